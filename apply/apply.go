@@ -0,0 +1,196 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+// Package apply steps through the differences between two sources one schema type at a time, letting the user review,
+// edit and run the SQL generated for each type against the second source.
+package apply
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/facefunk/pgdiff"
+)
+
+// Session is a single interactive run.
+type Session struct {
+	fac1   pgdiff.SchemaFactory
+	fac2   pgdiff.SchemaFactory
+	exec   pgdiff.Executor
+	in     *bufio.Reader
+	out    io.Writer
+	dir    string
+	editor string
+}
+
+// NewSession creates a Session that reads answers from in, writes to out and keeps the SQL for each schema type in a
+// file in dir. fac2 must be a pgdiff.Executor. If editor, a command such as $EDITOR, is not empty, the user is offered
+// the chance to edit the SQL with it before it is run.
+func NewSession(fac1, fac2 pgdiff.SchemaFactory, in io.Reader, out io.Writer, dir, editor string) (*Session, error) {
+	e, ok := fac2.(pgdiff.Executor)
+	if !ok {
+		return nil, pgdiff.NewError("the second datasource cannot run SQL")
+	}
+	return &Session{
+		fac1:   fac1,
+		fac2:   fac2,
+		exec:   e,
+		in:     bufio.NewReader(in),
+		out:    out,
+		dir:    dir,
+		editor: editor,
+	}, nil
+}
+
+// Run steps through each of schemaTypes in order.
+func (s *Session) Run(schemaTypes []string) error {
+	fmt.Fprintln(s.out, "This is the reference source:")
+	fmt.Fprintln(s.out, s.fac1.Identify(1))
+	fmt.Fprintln(s.out, "This source may be changed (if you choose):")
+	fmt.Fprintln(s.out, s.fac2.Identify(2))
+	fmt.Fprintln(s.out)
+	for i, schemaType := range schemaTypes {
+		err := s.step(i+1, schemaType)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(s.out)
+	}
+	return nil
+}
+
+// step generates, shows and optionally runs the SQL for schemaType until the user no longer wants to rerun it.
+func (s *Session) step(num int, schemaType string) error {
+	file := filepath.Join(s.dir, fmt.Sprintf("%d-%s.sql", num, schemaType))
+	for {
+		fmt.Fprintf(s.out, "Generating diff for %s...\n", schemaType)
+		comp := pgdiff.CompareByFactories(s.fac1, s.fac2, schemaType)
+		err := s.plan(comp)
+		if err != nil {
+			return err
+		}
+		if !hasStatements(comp) {
+			pgdiff.PrintStringers(comp.Stringers(), pgdiff.OutputNotice, s.out, s.out)
+			fmt.Fprintf(s.out, "No changes found for %s\n", schemaType)
+			return nil
+		}
+		err = writeFile(file, comp, s.out)
+		if err != nil {
+			return err
+		}
+		err = s.show(file)
+		if err != nil {
+			return err
+		}
+		if s.editor != "" && s.confirm(fmt.Sprintf("Edit %s?", file)) {
+			err = s.edit(file)
+			if err != nil {
+				fmt.Fprintf(s.out, "Error: editing %s: %s\n", file, err)
+			}
+		}
+		if !s.confirm("Do you wish to run this against db2?") {
+			return nil
+		}
+		err = s.run(file)
+		if err != nil {
+			fmt.Fprintf(s.out, "Error: %s\n", err)
+		}
+		if !s.confirm(fmt.Sprintf("Rerun diff for %s?", schemaType)) {
+			return nil
+		}
+	}
+}
+
+// plan orders the changes in comp by the dependencies between objects, which are read afresh as running the SQL for
+// each type changes the second source.
+func (s *Session) plan(comp *pgdiff.Comparison) error {
+	deps1, err := pgdiff.DependenciesOf(s.fac1)
+	if err != nil {
+		return err
+	}
+	deps2, err := pgdiff.DependenciesOf(s.fac2)
+	if err != nil {
+		return err
+	}
+	comp.Changes = pgdiff.Plan(comp.Changes, deps1, deps2)
+	return nil
+}
+
+// hasStatements reports whether any SQL was generated.
+func hasStatements(comp *pgdiff.Comparison) bool {
+	for _, change := range comp.Changes {
+		if len(change.Statements()) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// writeFile writes the statements and notices of comp to file. Errors are written to errOut instead, as they are not
+// SQL comments.
+func writeFile(file string, comp *pgdiff.Comparison, errOut io.Writer) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return pgdiff.NewError("creating SQL file: " + err.Error())
+	}
+	pgdiff.PrintStringers(comp.Stringers(), pgdiff.OutputLine|pgdiff.OutputNotice, f, errOut)
+	err = f.Close()
+	if err != nil {
+		return pgdiff.NewError("closing SQL file: " + err.Error())
+	}
+	return nil
+}
+
+func (s *Session) show(file string) error {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return pgdiff.NewError("reading SQL file: " + err.Error())
+	}
+	_, err = s.out.Write(b)
+	return err
+}
+
+// edit opens file in the editor, which is attached to the terminal rather than to in and out.
+func (s *Session) edit(file string) error {
+	args := strings.Fields(s.editor)
+	cmd := exec.Command(args[0], append(args[1:], file)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// run executes each statement in file against db2, stopping at the first that fails.
+func (s *Session) run(file string) error {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return pgdiff.NewError("reading SQL file: " + err.Error())
+	}
+	stmts, err := pgdiff.SplitSQL(string(b))
+	if err != nil {
+		return err
+	}
+	for i, stmt := range stmts {
+		err = s.exec.Exec(stmt)
+		if err != nil {
+			return pgdiff.NewError(fmt.Sprintf("running statement %d of %d: %s\n%s", i+1, len(stmts), err,
+				strings.TrimSpace(stmt)))
+		}
+	}
+	fmt.Fprintf(s.out, "Ran %d statements\n", len(stmts))
+	return nil
+}
+
+// confirm asks a yes or no question, defaulting to no.
+func (s *Session) confirm(question string) bool {
+	fmt.Fprintf(s.out, "%s [yN]: ", question)
+	answer, _ := s.in.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return strings.HasPrefix(answer, "y")
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package apply
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/facefunk/pgdiff"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	tableSource struct {
+		tables []string
+	}
+
+	execFactory struct {
+		*pgdiff.RowSchemaFactory
+		source  *tableSource
+		queries []string
+	}
+)
+
+func (s *tableSource) Rows(schemaType string) ([]map[string]string, error) {
+	var rows []map[string]string
+	if schemaType == pgdiff.TableSchemaType {
+		for _, t := range s.tables {
+			rows = append(rows, map[string]string{"compare_name": t, "table_schema": "public", "table_name": t,
+				"table_type": "TABLE"})
+		}
+	}
+	return rows, nil
+}
+
+func (s *tableSource) Identify(num int) *pgdiff.Notice {
+	return pgdiff.NewNotice(fmt.Sprintf("-- tables%d", num))
+}
+
+// Exec creates the table named by a CREATE TABLE query, so that a rerun finds no changes.
+func (f *execFactory) Exec(query string) error {
+	f.queries = append(f.queries, query)
+	name := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(query), "CREATE TABLE public."), "();")
+	f.source.tables = append(f.source.tables, name)
+	return nil
+}
+
+func newExecFactory(tables ...string) *execFactory {
+	s := &tableSource{tables}
+	return &execFactory{RowSchemaFactory: pgdiff.NewRowSchemaFactory(s, "public"), source: s}
+}
+
+func TestSession(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pgdiff-apply-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fac1 := pgdiff.NewRowSchemaFactory(&tableSource{[]string{"a", "b"}}, "public")
+	fac2 := newExecFactory("b")
+	out := &bytes.Buffer{}
+	s, err := NewSession(fac1, fac2, strings.NewReader("y\ny\n"), out, dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Run([]string{pgdiff.TableSchemaType, pgdiff.SequenceSchemaType})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"CREATE TABLE public.a();"}, fac2.queries)
+	assert.Contains(t, out.String(), "CREATE TABLE public.a();\nDo you wish to run this against db2? [yN]: Ran 1 statements\n")
+	assert.Contains(t, out.String(), "Rerun diff for TABLE? [yN]: Generating diff for TABLE...\nNo changes found for TABLE\n")
+	assert.Contains(t, out.String(), "No changes found for SEQUENCE\n")
+	b, err := ioutil.ReadFile(filepath.Join(dir, "1-TABLE.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "CREATE TABLE public.a();\n", string(b))
+
+	_, err = NewSession(fac1, fac1, strings.NewReader(""), out, dir, "")
+	assert.EqualError(t, err, "the second datasource cannot run SQL")
+}
@@ -4,13 +4,11 @@
 #
 # For OSX and Linux:
 #  * builds pgdiff 
-#  * downloads pgrun 
-#  * combines them, a README, and pgdiff.sh into a tgz file
+#  * combines it and a README into a tgz file
 #
 # For Windows:
 #  * builds pgdiff.exe
-#  * downloads pgrun.exe
-#  * combines them, a README, and pgdiff.sh into a zip file
+#  * combines it and a README into a zip file
 #
 
 SCRIPT_DIR="$(dirname `ls -l $0 | awk '{ print $NF }'`)"
@@ -35,10 +33,6 @@ if [[ -f $LINUX_README ]]; then
     mkdir -p $workdir
     # Build the executable
     GOOS=linux GOARCH=386 go build -o "$workdir/$APPNAME" main/pgdiff.go
-    # Download pgrun to the temp directory 
-    wget -O "$workdir/pgrun" "https://github.com/joncrlsn/pgrun/raw/master/bin-linux/pgrun"
-    # Copy the bash runtime script to the temp directory
-    cp pgdiff.sh "$workdir/"
     cp "${SCRIPT_DIR}/${LINUX_README}" "$workdir/README.md"
     cd "$tempdir"
     # Make everything executable
@@ -60,10 +54,6 @@ if [[ -f $OSX_README ]]; then
     mkdir -p $workdir
     # Build the executable
     GOOS=darwin GOARCH=386 go build -o "$workdir/$APPNAME" main/pgdiff.go
-    # Download pgrun to the work directory 
-    wget -O "$workdir/pgrun" "https://github.com/joncrlsn/pgrun/raw/master/bin-osx/pgrun"
-    # Copy the bash runtime script to the temp directory
-    cp pgdiff.sh "$workdir/"
     cp "${SCRIPT_DIR}/${OSX_README}" "$workdir/README.md"
     cd "$tempdir"
     # Make everything executable
@@ -84,13 +74,10 @@ if [[ -f $WIN_README ]]; then
     echo $workdir
     mkdir -p $workdir
     GOOS=windows GOARCH=386 go build -o "${workdir}/${APPNAME}.exe" main/pgdiff.go
-    # Download pgrun to the work directory 
-    # Copy the bash runtime script to the temp directory
     cp "${SCRIPT_DIR}/${WIN_README}" "$workdir/README.md"
     cd "$tempdir"
     # Make everything executable
     chmod -v ugo+x $APPNAME/*
-    wget -O "${workdir}/pgrun.exe" "https://github.com/joncrlsn/pgrun/raw/master/bin-win/pgrun.exe"
     zipName="${tempdir}/${WIN_FILE}"
     zip -r "$zipName" $APPNAME
     cd -
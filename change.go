@@ -0,0 +1,99 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+const (
+	ActionAdd   Action = "add"
+	ActionDrop  Action = "drop"
+	ActionAlter Action = "alter"
+)
+
+type (
+	// Action is what must be done to an object in db2 to make it match db1.
+	Action string
+
+	// Change is a single object that differs between db1 and db2, along with the output generated to resolve it.
+	Change struct {
+		SchemaType string
+		// Identity is the qualified name of the object, eg. public.users.email for a column.
+		Identity string
+		Action   Action
+		// Before is the row describing the object in db2, nil when the object is added.
+		Before map[string]string
+		// After is the row describing the object in db1, nil when the object is dropped.
+		After map[string]string
+		// Output is every Line, Notice, Warning and Error generated for the change, in order.
+		Output []Stringer
+	}
+
+	// Comparison is the result of comparing a single schema type between db1 and db2.
+	Comparison struct {
+		SchemaType string
+		Changes    []*Change
+		// Output is any Notice or Error not belonging to a single Change, eg. when db2 does not support SchemaType.
+		Output []Stringer
+	}
+)
+
+// Statements returns the SQL generated for the change.
+func (c *Change) Statements() []string {
+	var strs []string
+	for _, s := range c.Output {
+		if l, ok := s.(*Line); ok {
+			strs = append(strs, l.String())
+		}
+	}
+	return strs
+}
+
+// Warnings returns the warnings generated for the change.
+func (c *Change) Warnings() []string {
+	var strs []string
+	for _, s := range c.Output {
+		if w, ok := s.(*Warning); ok {
+			strs = append(strs, w.String())
+		}
+	}
+	return strs
+}
+
+// Errors returns the errors encountered while generating the change.
+func (c *Change) Errors() []string {
+	var strs []string
+	for _, s := range c.Output {
+		if e, ok := s.(*Error); ok {
+			strs = append(strs, e.String())
+		}
+	}
+	return strs
+}
+
+// Stringers returns the output of the comparison followed by the output of each Change.
+func (c *Comparison) Stringers() []Stringer {
+	strs := append([]Stringer(nil), c.Output...)
+	for _, change := range c.Changes {
+		strs = append(strs, change.Output...)
+	}
+	return strs
+}
+
+// Count returns the number of changes with action.
+func (c *Comparison) Count(action Action) int {
+	n := 0
+	for _, change := range c.Changes {
+		if change.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// copyRow copies row so that a Change cannot be altered through the rows of a Schema.
+func copyRow(row map[string]string) map[string]string {
+	c := make(map[string]string, len(row))
+	for k, v := range row {
+		c[k] = v
+	}
+	return c
+}
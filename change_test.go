@@ -0,0 +1,67 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func tableRow(name string) map[string]string {
+	return map[string]string{"compare_name": name, "table_schema": "public", "table_name": name, "table_type": "TABLE"}
+}
+
+func columnRow(name, dataType string) map[string]string {
+	return map[string]string{"compare_name": "t." + name, "table_schema": "public", "table_name": "t",
+		"column_name": name, "data_type": dataType, "is_nullable": "YES", "column_default": "null"}
+}
+
+// diffOutput returns the output of every change between s1 and s2.
+func diffOutput(s1, s2 Schema) []Stringer {
+	var strs []Stringer
+	for _, change := range Diff(s1, s2) {
+		strs = append(strs, change.Output...)
+	}
+	return strs
+}
+
+func TestDiff(t *testing.T) {
+	changes := Diff(NewTableSchema(TableRows{tableRow("a"), tableRow("b")}, "public"),
+		NewTableSchema(TableRows{tableRow("b"), tableRow("c")}, "public"))
+	if assert.Len(t, changes, 2) {
+		assert.Equal(t, "public.a", changes[0].Identity)
+		assert.Equal(t, ActionAdd, changes[0].Action)
+		assert.Nil(t, changes[0].Before)
+		assert.Equal(t, tableRow("a"), changes[0].After)
+		assert.Equal(t, []string{"CREATE TABLE public.a();"}, changes[0].Statements())
+
+		assert.Equal(t, "public.c", changes[1].Identity)
+		assert.Equal(t, ActionDrop, changes[1].Action)
+		assert.Equal(t, tableRow("c"), changes[1].Before)
+		assert.Nil(t, changes[1].After)
+		assert.Equal(t, []string{"DROP TABLE public.c;"}, changes[1].Statements())
+	}
+
+	changes = Diff(NewColumnSchema(ColumnRows{columnRow("c", "bigint")}, "public"),
+		NewColumnSchema(ColumnRows{columnRow("c", "integer")}, "public"))
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, "public.t.c", changes[0].Identity)
+		assert.Equal(t, ActionAlter, changes[0].Action)
+		assert.Equal(t, "integer", changes[0].Before["data_type"])
+		assert.Equal(t, "bigint", changes[0].After["data_type"])
+		assert.Equal(t, []string{"ALTER TABLE public.t ALTER COLUMN c TYPE bigint;"}, changes[0].Statements())
+		assert.Equal(t, []string{"-- WARNING: This type change may not work well: (integer to bigint)."},
+			changes[0].Warnings())
+		assert.Empty(t, changes[0].Errors())
+	}
+}
+
+func TestComparisonStringers(t *testing.T) {
+	comp := &Comparison{
+		Output:  []Stringer{NewNotice("-- notice")},
+		Changes: []*Change{{Output: []Stringer{NewLine("line 1")}}, {Output: []Stringer{NewLine("line 2")}}},
+	}
+	assert.Equal(t, []Stringer{NewNotice("-- notice"), NewLine("line 1"), NewLine("line 2")}, comp.Stringers())
+}
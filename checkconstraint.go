@@ -0,0 +1,148 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/joncrlsn/misc"
+)
+
+const notValidSuffix = " NOT VALID"
+
+// ==================================
+// CheckConstraintRows definition
+// ==================================
+
+// CheckConstraintRows is a sortable string map
+type CheckConstraintRows []map[string]string
+
+func (slice CheckConstraintRows) Len() int {
+	return len(slice)
+}
+
+func (slice CheckConstraintRows) Less(i, j int) bool {
+	return slice[i]["compare_name"] < slice[j]["compare_name"]
+}
+
+func (slice CheckConstraintRows) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+// ==================================
+// CheckConstraintSchema definition
+// (implements Schema -- defined in pgdiff.go)
+// ==================================
+
+// CheckConstraintSchema holds a slice of rows from one of the databases as well as
+// a reference to the current row of data we're viewing.
+//
+// If notValid is set, constraints are added NOT VALID and then validated in a separate statement, so writes are only
+// briefly blocked while the existing rows are checked.
+type CheckConstraintSchema struct {
+	rows     CheckConstraintRows
+	rowNum   int
+	done     bool
+	dbSchema string
+	notValid bool
+	other    *CheckConstraintSchema
+}
+
+func NewCheckConstraintSchema(rows CheckConstraintRows, dbSchema string, notValid bool) *CheckConstraintSchema {
+	return &CheckConstraintSchema{rows: rows, rowNum: -1, dbSchema: dbSchema, notValid: notValid}
+}
+
+// get returns the value from the current row for the given key
+func (c *CheckConstraintSchema) get(key string) string {
+	if c.rowNum >= len(c.rows) {
+		return ""
+	}
+	return c.rows[c.rowNum][key]
+}
+
+// NextRow increments the rowNum and tells you whether or not there are more
+func (c *CheckConstraintSchema) NextRow() bool {
+	if c.rowNum >= len(c.rows)-1 {
+		c.done = true
+	}
+	c.rowNum = c.rowNum + 1
+	return !c.done
+}
+
+// Identity returns the qualified name of the current row's object
+func (c *CheckConstraintSchema) Identity() string {
+	return c.get("schema_name") + "." + c.get("table_name") + "." + c.get("constraint_name")
+}
+
+// Row returns a copy of the current row
+func (c *CheckConstraintSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
+// Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
+func (c *CheckConstraintSchema) Compare(obj Schema) (int, *Error) {
+	c2, ok := obj.(*CheckConstraintSchema)
+	if !ok {
+		return +999, NewError(fmt.Sprint("compare(obj) needs a CheckConstraintSchema instance", c2))
+	}
+	c.other = c2
+
+	val := misc.CompareStrings(c.get("compare_name"), c.other.get("compare_name"))
+	return val, nil
+}
+
+// Add returns SQL to add the check constraint, validating it separately if notValid is set
+func (c *CheckConstraintSchema) Add() []Stringer {
+	return c.add(c.get("constraint_def"))
+}
+
+func (c *CheckConstraintSchema) add(def string) []Stringer {
+	table := c.table()
+	if !c.notValid || strings.HasSuffix(def, notValidSuffix) {
+		return []Stringer{NewLine(fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s;", table, c.get("constraint_name"), def))}
+	}
+	return []Stringer{
+		NewLine(fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s NOT VALID;", table, c.get("constraint_name"), def)),
+		NewLine(fmt.Sprintf("ALTER TABLE %s VALIDATE CONSTRAINT %s;", table, c.get("constraint_name"))),
+	}
+}
+
+// table returns the qualified name of the constraint's table in db2
+func (c *CheckConstraintSchema) table() string {
+	schema := c.other.dbSchema
+	if schema == "*" {
+		schema = c.get("schema_name")
+	}
+	return schema + "." + c.get("table_name")
+}
+
+// Drop returns SQL to drop the check constraint
+func (c CheckConstraintSchema) Drop() []Stringer {
+	return []Stringer{NewLine(fmt.Sprintf("ALTER TABLE %s.%s DROP CONSTRAINT %s; -- %s", c.get("schema_name"), c.get("table_name"), c.get("constraint_name"), c.get("constraint_def")))}
+}
+
+// Change handles the case where the table and constraint name match, but the check does not. A check cannot be
+// altered, so it is dropped and added again. A constraint that is only NOT VALID in db2 is validated.
+func (c *CheckConstraintSchema) Change() []Stringer {
+	def1 := c.get("constraint_def")
+	def2 := c.other.get("constraint_def")
+	if def1 == def2 {
+		return nil
+	}
+	check1 := strings.TrimSuffix(def1, notValidSuffix)
+	check2 := strings.TrimSuffix(def2, notValidSuffix)
+	if check1 == check2 {
+		if check1 == def1 {
+			return []Stringer{NewLine(fmt.Sprintf("ALTER TABLE %s VALIDATE CONSTRAINT %s;", c.table(), c.get("constraint_name")))}
+		}
+		// Only db1's constraint is NOT VALID, which leaves nothing to do
+		return nil
+	}
+	strs := []Stringer{NewLine(fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s; -- %s", c.table(), c.get("constraint_name"), def2))}
+	return append(strs, c.add(def1)...)
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func checkRow(name, def string) map[string]string {
+	return map[string]string{"compare_name": "s.t." + name, "schema_name": "s", "table_name": "t",
+		"constraint_name": name, "constraint_def": def}
+}
+
+func TestCheckConstraint(t *testing.T) {
+	db1 := CheckConstraintRows{
+		checkRow("a", "CHECK ((amount > 0))"),
+		checkRow("b", "CHECK ((qty > 0))"),
+		checkRow("c", "CHECK ((price > 0))"),
+		checkRow("d", "CHECK ((tax >= 0))"),
+	}
+	db2 := CheckConstraintRows{
+		checkRow("b", "CHECK ((qty >= 0))"),
+		checkRow("c", "CHECK ((price > 0)) NOT VALID"),
+		checkRow("d", "CHECK ((tax >= 0))"),
+		checkRow("e", "CHECK ((e > 0))"),
+	}
+	assert.Equal(t, []Stringer{
+		NewLine("ALTER TABLE s.t ADD CONSTRAINT a CHECK ((amount > 0));"),
+		NewLine("ALTER TABLE s.t DROP CONSTRAINT b; -- CHECK ((qty >= 0))"),
+		NewLine("ALTER TABLE s.t ADD CONSTRAINT b CHECK ((qty > 0));"),
+		NewLine("ALTER TABLE s.t VALIDATE CONSTRAINT c;"),
+		NewLine("ALTER TABLE s.t DROP CONSTRAINT e; -- CHECK ((e > 0))"),
+	}, diffOutput(NewCheckConstraintSchema(db1, "*", false), NewCheckConstraintSchema(db2, "*", false)))
+
+	assert.Equal(t, []Stringer{
+		NewLine("ALTER TABLE s.t ADD CONSTRAINT a CHECK ((amount > 0)) NOT VALID;"),
+		NewLine("ALTER TABLE s.t VALIDATE CONSTRAINT a;"),
+	}, diffOutput(NewCheckConstraintSchema(db1[:1], "*", true), NewCheckConstraintSchema(nil, "*", false)))
+	notValid := CheckConstraintRows{checkRow("c", "CHECK ((price > 0)) NOT VALID")}
+	assert.Equal(t, []Stringer{NewLine("ALTER TABLE s.t ADD CONSTRAINT c CHECK ((price > 0)) NOT VALID;")},
+		diffOutput(NewCheckConstraintSchema(notValid, "*", true), NewCheckConstraintSchema(nil, "*", false)))
+}
@@ -71,6 +71,19 @@ func (c *ColumnSchema) NextRow() bool {
 	return !c.done
 }
 
+// Identity returns the qualified name of the current row's object
+func (c *ColumnSchema) Identity() string {
+	return c.get("table_schema") + "." + c.get("table_name") + "." + c.get("column_name")
+}
+
+// Row returns a copy of the current row
+func (c *ColumnSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
 // Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
 func (c *ColumnSchema) Compare(obj Schema) (int, *Error) {
 	var err *Error
@@ -85,6 +98,12 @@ func (c *ColumnSchema) Compare(obj Schema) (int, *Error) {
 
 // Add prints SQL to add the column
 func (c *ColumnSchema) Add() []Stringer {
+	// The partition key columns of a partitioned table are created along with the table, see TableSchema.Add. An
+	// inherited column comes from the parent table.
+	if c.get("is_partition_key") == "YES" || c.get("is_inherited") == "YES" {
+		return nil
+	}
+
 	var strs []Stringer
 	schema := c.other.dbSchema
 	if schema == "*" {
@@ -93,8 +112,8 @@ func (c *ColumnSchema) Add() []Stringer {
 
 	// Knowing the version of db2 would eliminate the need for this warning
 	if c.get("is_identity") == "YES" {
-		strs = append(strs, NewNotice("-- WARNING: identity columns are not supported in PostgreSQL versions < 10."),
-			NewNotice("-- Attempting to create identity columns in earlier versions will probably result in errors."))
+		strs = append(strs, NewWarning("-- WARNING: identity columns are not supported in PostgreSQL versions < 10."),
+			NewWarning("-- Attempting to create identity columns in earlier versions will probably result in errors."))
 	}
 
 	var alter string
@@ -134,12 +153,20 @@ func (c *ColumnSchema) Add() []Stringer {
 
 // Drop prints SQL to drop the column
 func (c *ColumnSchema) Drop() []Stringer {
+	// An inherited column is dropped through the parent table
+	if c.get("is_inherited") == "YES" {
+		return nil
+	}
 	// if dropping column
 	return []Stringer{NewLine(fmt.Sprintf("ALTER TABLE %s.%s DROP COLUMN IF EXISTS %s;", c.get("table_schema"), c.get("table_name"), c.get("column_name")))}
 }
 
 // Change handles the case where the table and column match, but the details do not
 func (c *ColumnSchema) Change() []Stringer {
+	// Changes to an inherited column are made to the parent table, which passes them on
+	if c.get("is_inherited") == "YES" {
+		return nil
+	}
 	var strs []Stringer
 	// Adjust data type for array columns
 	dataType1 := c.get("data_type")
@@ -172,7 +199,7 @@ func (c *ColumnSchema) Change() []Stringer {
 					return []Stringer{NewError("converting string to int"), NewError(err.Error())}
 				}
 				if max1Int < max2Int {
-					strs = append(strs, NewNotice("-- WARNING: The next statement will shorten a character varying column, which may result in data loss."))
+					strs = append(strs, NewWarning("-- WARNING: The next statement will shorten a character varying column, which may result in data loss."))
 				}
 				strs = append(strs, NewNotice(fmt.Sprintf("-- max1Valid: %v  max2Valid: %v", max1Valid, max2Valid)),
 					NewLine(fmt.Sprintf("ALTER TABLE %s.%s ALTER COLUMN %s TYPE character varying(%s);", c.other.get("table_schema"), c.get("table_name"), c.get("column_name"), max1)))
@@ -182,11 +209,11 @@ func (c *ColumnSchema) Change() []Stringer {
 
 	// Code and test a column change from integer to bigint
 	if dataType1 != dataType2 {
-		strs = append(strs, NewNotice(fmt.Sprintf("-- WARNING: This type change may not work well: (%s to %s).", dataType2, dataType1)))
+		strs = append(strs, NewWarning(fmt.Sprintf("-- WARNING: This type change may not work well: (%s to %s).", dataType2, dataType1)))
 		if strings.HasPrefix(dataType1, "character") {
 			max1, max1Valid := getMaxLength(c.get("character_maximum_length"))
 			if !max1Valid {
-				strs = append(strs, NewNotice("-- WARNING: varchar column has no maximum length.  Setting to 1024"))
+				strs = append(strs, NewWarning("-- WARNING: varchar column has no maximum length.  Setting to 1024"))
 			}
 			strs = append(strs, NewLine(fmt.Sprintf("ALTER TABLE %s.%s ALTER COLUMN %s TYPE %s(%s);", c.other.get("table_schema"), c.get("table_name"), c.get("column_name"), dataType1, max1)))
 		} else {
@@ -209,8 +236,8 @@ func (c *ColumnSchema) Change() []Stringer {
 	var identitySql string
 	if c.get("is_identity") != c.other.get("is_identity") {
 		// Knowing the version of db2 would eliminate the need for this warning
-		strs = append(strs, NewNotice("-- WARNING: identity columns are not supported in PostgreSQL versions < 10."),
-			NewNotice("-- Attempting to create identity columns in earlier versions will probably result in errors."))
+		strs = append(strs, NewWarning("-- WARNING: identity columns are not supported in PostgreSQL versions < 10."),
+			NewWarning("-- Attempting to create identity columns in earlier versions will probably result in errors."))
 		if c.get("is_identity") == "YES" {
 			identitySql = fmt.Sprintf("ALTER TABLE \"%s\".\"%s\" ALTER COLUMN \"%s\" ADD GENERATED %s AS IDENTITY;", c.other.get("table_schema"), c.get("table_name"), c.get("column_name"), c.get("identity_generation"))
 		} else {
@@ -0,0 +1,129 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"fmt"
+
+	"github.com/joncrlsn/misc"
+)
+
+// ==================================
+// CommentRows definition
+// ==================================
+
+// CommentRows is a sortable string map
+type CommentRows []map[string]string
+
+func (slice CommentRows) Len() int {
+	return len(slice)
+}
+
+func (slice CommentRows) Less(i, j int) bool {
+	return slice[i]["compare_name"] < slice[j]["compare_name"]
+}
+
+func (slice CommentRows) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+// ==================================
+// CommentSchema definition
+// (implements Schema -- defined in pgdiff.go)
+// ==================================
+
+// CommentSchema holds a slice of rows from one of the databases as well as
+// a reference to the current row of data we're viewing.
+//
+// Each row holds the comment on one object. object_type is the object's keyword in COMMENT ON, table_name is set for
+// columns and constraints and object_name includes the identity arguments of functions.
+type CommentSchema struct {
+	rows     CommentRows
+	rowNum   int
+	done     bool
+	dbSchema string
+	other    *CommentSchema
+}
+
+func NewCommentSchema(rows CommentRows, dbSchema string) *CommentSchema {
+	return &CommentSchema{rows: rows, rowNum: -1, dbSchema: dbSchema}
+}
+
+// get returns the value from the current row for the given key
+func (c *CommentSchema) get(key string) string {
+	if c.rowNum >= len(c.rows) {
+		return ""
+	}
+	return c.rows[c.rowNum][key]
+}
+
+// NextRow increments the rowNum and tells you whether or not there are more
+func (c *CommentSchema) NextRow() bool {
+	if c.rowNum >= len(c.rows)-1 {
+		c.done = true
+	}
+	c.rowNum = c.rowNum + 1
+	return !c.done
+}
+
+// Identity returns the kind and qualified name of the current row's object
+func (c *CommentSchema) Identity() string {
+	return c.get("object_type") + " " + c.object(c.get("schema_name"))
+}
+
+// Row returns a copy of the current row
+func (c *CommentSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
+// Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
+func (c *CommentSchema) Compare(obj Schema) (int, *Error) {
+	c2, ok := obj.(*CommentSchema)
+	if !ok {
+		return +999, NewError(fmt.Sprint("compare(obj) needs a CommentSchema instance", c2))
+	}
+	c.other = c2
+
+	val := misc.CompareStrings(c.get("compare_name"), c.other.get("compare_name"))
+	return val, nil
+}
+
+// object returns the name of the current row's object as COMMENT ON expects it, in the given schema
+func (c *CommentSchema) object(schema string) string {
+	switch c.get("object_type") {
+	case "SCHEMA":
+		return schema
+	case "COLUMN":
+		return schema + "." + c.get("table_name") + "." + c.get("object_name")
+	case "CONSTRAINT":
+		return c.get("object_name") + " ON " + schema + "." + c.get("table_name")
+	}
+	return schema + "." + c.get("object_name")
+}
+
+// Add returns SQL to comment on the object in db2
+func (c *CommentSchema) Add() []Stringer {
+	schema := c.other.dbSchema
+	if schema == "*" {
+		schema = c.get("schema_name")
+	}
+	return []Stringer{NewLine(fmt.Sprintf("COMMENT ON %s %s IS %s;", c.get("object_type"), c.object(schema),
+		quoteLiteral(c.get("comment"))))}
+}
+
+// Drop returns SQL to remove the comment
+func (c CommentSchema) Drop() []Stringer {
+	return []Stringer{NewLine(fmt.Sprintf("COMMENT ON %s %s IS NULL;", c.get("object_type"), c.object(c.get("schema_name"))))}
+}
+
+// Change handles the case where the objects match, but the comments do not
+func (c *CommentSchema) Change() []Stringer {
+	if c.get("comment") == c.other.get("comment") {
+		return nil
+	}
+	return c.Add()
+}
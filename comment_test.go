@@ -0,0 +1,43 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func commentRow(objectType, table, name, comment string) map[string]string {
+	compare := objectType + " s."
+	if table != "null" {
+		compare += table + "."
+	}
+	return map[string]string{"compare_name": compare + name, "schema_name": "s", "object_type": objectType,
+		"table_name": table, "object_name": name, "comment": comment}
+}
+
+func TestComment(t *testing.T) {
+	assert.Equal(t, []Stringer{
+		NewLine("COMMENT ON COLUMN s.t.c IS 'It''s a column';"),
+		NewLine("COMMENT ON CONSTRAINT t_check ON s.t IS E'C:\\\\temp';"),
+		NewLine("COMMENT ON FUNCTION s.f(a integer) IS 'Eff';"),
+	}, diffOutput(NewCommentSchema(CommentRows{
+		commentRow("COLUMN", "t", "c", "It's a column"),
+		commentRow("CONSTRAINT", "t", "t_check", `C:\temp`),
+		commentRow("FUNCTION", "null", "f(a integer)", "Eff"),
+	}, "*"), NewCommentSchema(nil, "*")))
+
+	assert.Equal(t, []Stringer{NewLine("COMMENT ON SCHEMA s IS NULL;"), NewLine("COMMENT ON TABLE s.t IS NULL;")},
+		diffOutput(NewCommentSchema(nil, "*"), NewCommentSchema(CommentRows{
+			commentRow("SCHEMA", "null", "s", "Ess"),
+			commentRow("TABLE", "null", "t", "Tee"),
+		}, "*")))
+
+	assert.Equal(t, []Stringer{NewLine("COMMENT ON MATERIALIZED VIEW s2.v IS 'New';")},
+		diffOutput(NewCommentSchema(CommentRows{commentRow("MATERIALIZED VIEW", "null", "v", "New")}, "*"),
+			NewCommentSchema(CommentRows{commentRow("MATERIALIZED VIEW", "null", "v", "Old")}, "s2")))
+	assert.Empty(t, diffOutput(NewCommentSchema(CommentRows{commentRow("TABLE", "null", "t", "Tee")}, "*"),
+		NewCommentSchema(CommentRows{commentRow("TABLE", "null", "t", "Tee")}, "*")))
+}
@@ -21,6 +21,7 @@ import (
 const (
 	defaultSchema = "*"
 	defaultOutput = OutputLine | OutputNotice
+	defaultFormat = FormatText
 )
 
 type (
@@ -67,6 +68,9 @@ type (
 	// GlobalConfig is the Config that does not apply to any Module.
 	GlobalConfig struct {
 		Output OutputSet
+		Format Format
+		// NotValid adds CHECK constraints NOT VALID and then validates them, see RowSchemaFactory.SetCheckNotValid.
+		NotValid bool `yaml:"notValid"`
 	}
 
 	// SourceModule is a ConfigModule that decodes SourceConfig.
@@ -101,6 +105,9 @@ func (m *GlobalModule) Name() string {
 func (m *GlobalModule) RegisterFlags(flagSet *flag.FlagSet) {
 	m.vals.Output = defaultOutput
 	flagSet.VarP(&m.vals.Output, "output", "t", "combination of output types to output")
+	m.vals.Format = defaultFormat
+	flagSet.Var(&m.vals.Format, "format", "output format: text or json")
+	flagSet.BoolVar(&m.vals.NotValid, "not-valid", false, "add check constraints NOT VALID, then validate them")
 }
 
 func (m *GlobalModule) ConfigureFromFlags() {
@@ -124,6 +131,7 @@ func (m *GlobalModule) UnmarshalYAML(unmarshal func(interface{}) error) error {
 func defaultGlobalConfig() *GlobalConfig {
 	return &GlobalConfig{
 		Output: defaultOutput,
+		Format: defaultFormat,
 	}
 }
 
@@ -194,6 +202,25 @@ func (o *OutputSet) Set(str string) error {
 	return err
 }
 
+func (f *Format) String() string {
+	return string(*f)
+}
+
+func (f *Format) Set(str string) error {
+	var err error
+	*f, err = FormatFromString(str)
+	return err
+}
+
+func (f *Format) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	err := unmarshal(&str)
+	if err != nil {
+		return err
+	}
+	return f.Set(str)
+}
+
 func (c *SourceConfig) SetSourceConfig(conf *SourceConfig) {
 	panic("SourceConfig already is a SourceConfig")
 }
@@ -236,27 +263,56 @@ func ConfigureModulesFromReadSeeker(configModules []ConfigModule, reader io.Read
 func FactoriesFromModules(modules []Module, sourceModule *SourceModule) (map[int]SchemaFactory, error) {
 	facs := make(map[int]SchemaFactory, 2)
 	var schemas string
-confNum:
 	for i := 1; i <= 2; i++ {
-		sourceConf := sourceModule.Config(i).(*SourceConfig)
-		schemas += sourceConf.Schema
-		for _, mod := range modules {
-			conf := mod.Config(i)
-			conf.SetSourceConfig(sourceConf)
-			if conf.Valid() {
-				fac, err := mod.Factory(conf)
-				if err != nil {
-					return nil, NewError(fmt.Sprintf("initialising SchemaFactory: %s", err))
-				}
-				facs[i] = fac
-				continue confNum
-			}
+		schemas += sourceModule.Config(i).(*SourceConfig).Schema
+		fac, err := FactoryFromModules(modules, sourceModule, i)
+		if err != nil {
+			return nil, closeFactories(facs, err)
 		}
-		return nil, NewError("two properly configured datasources required")
+		if fac == nil {
+			return nil, closeFactories(facs, NewError("two properly configured datasources required"))
+		}
+		facs[i] = fac
 	}
 	// Verify schemas
 	if schemas != "**" && strings.Contains(schemas, "*") {
-		return nil, NewError("If one schema is an asterisk, both must be")
+		return nil, closeFactories(facs, NewError("If one schema is an asterisk, both must be"))
 	}
 	return facs, nil
 }
+
+// closeFactories closes those of facs that need closing, eg. to drop their scratch databases, after err prevented
+// them being used. err is returned along with any errors closing them.
+func closeFactories(facs map[int]SchemaFactory, err error) error {
+	msg := err.Error()
+	for i := 1; i <= len(facs); i++ {
+		if closer, ok := facs[i].(io.Closer); ok {
+			cerr := closer.Close()
+			if cerr != nil {
+				msg += fmt.Sprintf("; closing SchemaFactory %d: %s", i, cerr)
+			}
+		}
+	}
+	if msg == err.Error() {
+		return err
+	}
+	return NewError(msg)
+}
+
+// FactoryFromModules returns a SchemaFactory for source i from the first Module with a valid config for it, or nil if
+// there is none.
+func FactoryFromModules(modules []Module, sourceModule *SourceModule, i int) (SchemaFactory, error) {
+	sourceConf := sourceModule.Config(i).(*SourceConfig)
+	for _, mod := range modules {
+		conf := mod.Config(i)
+		conf.SetSourceConfig(sourceConf)
+		if conf.Valid() {
+			fac, err := mod.Factory(conf)
+			if err != nil {
+				return nil, NewError(fmt.Sprintf("initialising SchemaFactory: %s", err))
+			}
+			return fac, nil
+		}
+	}
+	return nil, nil
+}
@@ -6,6 +6,7 @@ package pgdiff
 import (
 	"testing"
 
+	flag "github.com/ogier/pflag"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v3"
 )
@@ -35,3 +36,67 @@ source2:
 	assert.Equal(t, "jon", out.conf2.User)
 	assert.Equal(t, "*", out.conf2.Schema)
 }
+
+func TestGlobalYAML(t *testing.T) {
+	out := GlobalModule{}
+	err := yaml.Unmarshal([]byte("global:\n  format: json\n  notValid: true\n"), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, FormatJSON, out.conf.Format)
+	assert.Equal(t, OutputSet(defaultOutput), out.conf.Output)
+	assert.True(t, out.conf.NotValid)
+
+	err = yaml.Unmarshal([]byte("global:\n  format: xml\n"), &out)
+	assert.EqualError(t, err, "invalid format: xml")
+}
+
+// closerModule is a Module whose factories record being closed.
+type closerModule struct {
+	closed []int
+}
+
+type closerConfig struct {
+	*SourceConfig
+	num int
+}
+
+type closerFactory struct {
+	*RowSchemaFactory
+	mod *closerModule
+	num int
+}
+
+func (m *closerModule) Name() string                                          { return "closer" }
+func (m *closerModule) RegisterFlags(flagSet *flag.FlagSet)                   {}
+func (m *closerModule) ConfigureFromFlags()                                   {}
+func (m *closerModule) UnmarshalYAML(unmarshal func(interface{}) error) error { return nil }
+func (m *closerModule) Config(i int) Config                                   { return &closerConfig{num: i} }
+
+func (m *closerModule) Factory(conf Config) (SchemaFactory, error) {
+	return &closerFactory{mod: m, num: conf.(*closerConfig).num}, nil
+}
+
+func (c *closerConfig) SetSourceConfig(conf *SourceConfig) { c.SourceConfig = conf }
+
+func (f *closerFactory) Close() error {
+	f.mod.closed = append(f.mod.closed, f.num)
+	return nil
+}
+
+func TestFactoriesFromModulesCloses(t *testing.T) {
+	mod := &closerModule{}
+	sourceModule := &SourceModule{conf1: SourceConfig{User: "u", Schema: "public"},
+		conf2: SourceConfig{User: "u", Schema: "*"}}
+	facs, err := FactoriesFromModules([]Module{mod}, sourceModule)
+	assert.EqualError(t, err, "If one schema is an asterisk, both must be")
+	assert.Nil(t, facs)
+	assert.Equal(t, []int{1, 2}, mod.closed)
+
+	mod.closed = nil
+	sourceModule.conf2 = SourceConfig{Schema: "public"}
+	facs, err = FactoriesFromModules([]Module{mod}, sourceModule)
+	assert.EqualError(t, err, "two properly configured datasources required")
+	assert.Nil(t, facs)
+	assert.Equal(t, []int{1}, mod.closed)
+}
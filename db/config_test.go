@@ -12,8 +12,10 @@ import (
 )
 
 var (
-	_ pgdiff.Module = (*Module)(nil)
-	_ pgdiff.Config = (*Config)(nil)
+	_ pgdiff.Module           = (*Module)(nil)
+	_ pgdiff.Config           = (*Config)(nil)
+	_ pgdiff.Executor         = (*SchemaFactory)(nil)
+	_ pgdiff.DependencySource = (*SchemaFactory)(nil)
 )
 
 func TestYAML(t *testing.T) {
@@ -12,280 +12,258 @@ import (
 	"bytes"
 	"database/sql"
 	"fmt"
-	"sort"
+	"strconv"
 	"text/template"
+	"time"
 
 	"github.com/facefunk/pgdiff"
 	"github.com/joncrlsn/pgutil"
 	_ "github.com/lib/pq"
 )
 
+// isoFormat is the format pgutil.QueryStrings gives timestamps in.
+const isoFormat = "2006-01-02T15:04:05.000-0700"
+
+// SchemaFactory is a pgdiff.RowSource that runs the catalog queries against a live database. The embedded
+// RowSchemaFactory turns its rows into each type of Schema.
 type SchemaFactory struct {
-	conn   *sql.DB
-	dbInfo *pgutil.DbInfo
+	*pgdiff.RowSchemaFactory
+	conn    *sql.DB
+	dbInfo  *pgutil.DbInfo
+	version int
+	// conninfo is whether the user may read the connection strings of subscriptions, once conninfoChecked.
+	conninfo, conninfoChecked bool
 }
 
 func NewSchemaFactory(conn *sql.DB, dbInfo *pgutil.DbInfo) pgdiff.SchemaFactory {
-	return &SchemaFactory{conn, dbInfo}
-}
-
-// columnSchema returns a Schema that outputs SQL to make the columns match between two databases or schemas
-func columnSchema(conn *sql.DB, dbInfo *pgutil.DbInfo, tpl *template.Template) (*pgdiff.ColumnSchema, error) {
-	buf := new(bytes.Buffer)
-	err := tpl.Execute(buf, dbInfo)
-	if err != nil {
-		return nil, err
-	}
-
-	rowChan, _ := pgutil.QueryStrings(conn, buf.String())
-
-	rows := make(pgdiff.ColumnRows, 0)
-	for row := range rowChan {
-		rows = append(rows, row)
-	}
-	sort.Sort(rows)
-
-	return pgdiff.NewColumnSchema(rows, dbInfo.DbSchema), nil
+	f := &SchemaFactory{conn: conn, dbInfo: dbInfo}
+	f.RowSchemaFactory = pgdiff.NewRowSchemaFactory(f, dbInfo.DbSchema)
+	return f
 }
 
-// Column returns a ColumnSchema that outputs SQL to make the columns match between two databases or
-// schemas
-func (f *SchemaFactory) Column() (*pgdiff.ColumnSchema, error) {
-	return columnSchema(f.conn, f.dbInfo, columnSqlTemplate)
+// Supports reports that a database can produce every schema type. Without it the embedded RowSchemaFactory would ask
+// the SchemaFactory, its own RowSource, and so itself, forever.
+func (f *SchemaFactory) Supports(schemaType string) bool {
+	return true
 }
 
-// TableColumn returns a ColumnSchema that outputs SQL to make the tables columns (without views columns)
-// match between two databases or schemas
-func (f *SchemaFactory) TableColumn() (*pgdiff.ColumnSchema, error) {
-	return columnSchema(f.conn, f.dbInfo, tableColumnSqlTemplate)
-}
-
-// ForeignKey returns a ForeignKeySchema that compares the foreign keys in the two databases.
-func (f *SchemaFactory) ForeignKey() (*pgdiff.ForeignKeySchema, error) {
-	buf := new(bytes.Buffer)
-	err := foreignKeySqlTemplate.Execute(buf, f.dbInfo)
+// Rows runs the catalog query for schemaType.
+func (f *SchemaFactory) Rows(schemaType string) ([]map[string]string, error) {
+	query, err := f.query(schemaType)
 	if err != nil {
 		return nil, err
 	}
 
-	rowChan, _ := pgutil.QueryStrings(f.conn, buf.String())
-
-	rows := make(pgdiff.ForeignKeyRows, 0)
-	for row := range rowChan {
-		rows = append(rows, row)
-	}
-	sort.Sort(rows)
-
-	return pgdiff.NewForeignKeySchema(rows, f.dbInfo.DbSchema), nil
-}
-
-// Function returns a FunctionSchema that outputs SQL to make the functions match between DBs
-func (f *SchemaFactory) Function() (*pgdiff.FunctionSchema, error) {
-	buf := new(bytes.Buffer)
-	err := functionSqlTemplate.Execute(buf, f.dbInfo)
+	rows, err := f.queryStrings(query)
 	if err != nil {
 		return nil, err
 	}
-
-	rowChan, _ := pgutil.QueryStrings(f.conn, buf.String())
-
-	rows := make(pgdiff.FunctionRows, 0)
-	for row := range rowChan {
-		rows = append(rows, row)
-	}
-	sort.Sort(rows)
-
-	return pgdiff.NewFunctionSchema(rows, f.dbInfo.DbSchema), nil
+	return rows, pgdiff.MaskSecrets(schemaType, rows)
 }
 
-// GrantAttribute returns a GrantAttributeSchema that outputs SQL to make the granted permissions match
-// between DBs or schemas
-func (f *SchemaFactory) GrantAttribute() (*pgdiff.GrantAttributeSchema, error) {
-	buf := new(bytes.Buffer)
-	err := grantAttributeSqlTemplate.Execute(buf, f.dbInfo)
-	if err != nil {
-		return nil, err
-	}
-
-	rowChan, _ := pgutil.QueryStrings(f.conn, buf.String())
-
-	rows := make(pgdiff.GrantAttributeRows, 0)
-	for row := range rowChan {
-		rows = append(rows, row)
+// query returns the catalog query for schemaType, filtered by the configured dbSchema where the query supports it.
+func (f *SchemaFactory) query(schemaType string) (string, error) {
+	var tpl *template.Template
+	switch schemaType {
+	case pgdiff.SchemataSchemaType:
+		return schemataSql, nil
+	case pgdiff.RoleSchemaType:
+		return roleSql, nil
+	case pgdiff.ForeignDataWrapperSchemaType:
+		return foreignDataWrapperSql, nil
+	case pgdiff.ServerSchemaType:
+		return foreignServerSql, nil
+	case pgdiff.UserMappingSchemaType:
+		return userMappingSql, nil
+	case pgdiff.PublicationSchemaType:
+		version, err := f.serverVersion()
+		return publicationSql(version), err
+	case pgdiff.SubscriptionSchemaType:
+		version, err := f.serverVersion()
+		if err != nil {
+			return "", err
+		}
+		conninfo, err := f.canReadConninfo()
+		return subscriptionSql(version, conninfo), err
+	case pgdiff.EventTriggerSchemaType:
+		return eventTriggerSql, nil
+	case pgdiff.ViewSchemaType:
+		return viewSql, nil
+	case pgdiff.MatViewSchemaType:
+		return matViewSql, nil
+	case pgdiff.ExtensionSchemaType:
+		tpl = extensionSqlTemplate
+	case pgdiff.SequenceSchemaType:
+		tpl = sequenceSqlTemplate
+	case pgdiff.EnumSchemaType:
+		tpl = enumSqlTemplate
+	case pgdiff.DomainSchemaType:
+		tpl = domainSqlTemplate
+	case pgdiff.TypeSchemaType:
+		tpl = typeSqlTemplate
+	case pgdiff.TableSchemaType:
+		tpl = tableSqlTemplate
+	case pgdiff.ForeignTableSchemaType:
+		tpl = foreignTableSqlTemplate
+	case pgdiff.ColumnSchemaType:
+		tpl = columnSqlTemplate
+	case pgdiff.TableColumnSchemaType:
+		tpl = tableColumnSqlTemplate
+	case pgdiff.OperatorSchemaType:
+		tpl = operatorSqlTemplate
+	case pgdiff.OperatorClassSchemaType:
+		tpl = operatorClassSqlTemplate
+	case pgdiff.IndexSchemaType:
+		tpl = indexSqlTemplate
+	case pgdiff.ForeignKeySchemaType:
+		tpl = foreignKeySqlTemplate
+	case pgdiff.CheckConstraintSchemaType:
+		tpl = checkConstraintSqlTemplate
+	case pgdiff.FunctionSchemaType:
+		tpl = functionSqlTemplate
+	case pgdiff.TriggerSchemaType:
+		tpl = triggerSqlTemplate
+	case pgdiff.RuleSchemaType:
+		tpl = ruleSqlTemplate
+	case pgdiff.PolicySchemaType:
+		tpl = policySqlTemplate
+	case pgdiff.OwnerSchemaType:
+		tpl = ownerSqlTemplate
+	case pgdiff.GrantRelationshipSchemaType:
+		tpl = grantRelationshipSqlTemplate
+	case pgdiff.GrantAttributeSchemaType:
+		tpl = grantAttributeSqlTemplate
+	case pgdiff.CommentSchemaType:
+		tpl = commentSqlTemplate
+	default:
+		return "", pgdiff.NewError(fmt.Sprintf("unsupported schema type: %s", schemaType))
 	}
-	sort.Sort(rows)
-
-	return pgdiff.NewGrantAttributeSchema(rows, f.dbInfo.DbSchema), nil
-}
-
-// GrantRelationship returns a GrantRelationshipSchema that outputs SQL to make the granted permissions
-// match between DBs or schemas
-func (f *SchemaFactory) GrantRelationship() (*pgdiff.GrantRelationshipSchema, error) {
 	buf := new(bytes.Buffer)
-	err := grantRelationshipSqlTemplate.Execute(buf, f.dbInfo)
+	err := tpl.Execute(buf, f.dbInfo)
 	if err != nil {
-		return nil, err
-	}
-
-	rowChan, _ := pgutil.QueryStrings(f.conn, buf.String())
-
-	rows := make(pgdiff.GrantRelationshipRows, 0)
-	for row := range rowChan {
-		rows = append(rows, row)
+		return "", err
 	}
-	sort.Sort(rows)
-
-	return pgdiff.NewGrantRelationshipSchema(rows, f.dbInfo.DbSchema), nil
+	return buf.String(), nil
 }
 
-// Index returns an IndexSchema that outputs Sql to make the indexes match between to DBs or schemas
-func (f *SchemaFactory) Index() (*pgdiff.IndexSchema, error) {
-	buf := new(bytes.Buffer)
-	err := indexSqlTemplate.Execute(buf, f.dbInfo)
+// Dependencies reads the dependencies between objects from pg_depend.
+func (f *SchemaFactory) Dependencies() ([]pgdiff.Dependency, error) {
+	rows, err := f.queryStrings(dependencySql)
 	if err != nil {
 		return nil, err
 	}
-
-	rowChan, _ := pgutil.QueryStrings(f.conn, buf.String())
-
-	rows := make(pgdiff.IndexRows, 0)
-	for row := range rowChan {
-		rows = append(rows, row)
+	var deps []pgdiff.Dependency
+	for _, row := range rows {
+		deps = append(deps, pgdiff.Dependency{
+			Object:     pgdiff.ObjectRef{SchemaType: row["schema_type"], Identity: row["identity"]},
+			Referenced: pgdiff.ObjectRef{SchemaType: row["ref_schema_type"], Identity: row["ref_identity"]},
+		})
 	}
-	sort.Sort(rows)
-
-	return pgdiff.NewIndexSchema(rows, f.dbInfo.DbSchema), nil
+	return deps, nil
 }
 
-// MatView returns a MatViewSchema that outputs SQL to make the matviews match between DBs
-func (f *SchemaFactory) MatView() (*pgdiff.MatViewSchema, error) {
-
-	rowChan, _ := pgutil.QueryStrings(f.conn, matViewSql)
-
-	rows := make(pgdiff.MatViewRows, 0)
-	for row := range rowChan {
-		rows = append(rows, row)
+// serverVersion returns the server_version_num of the database server, reading it once.
+func (f *SchemaFactory) serverVersion() (int, error) {
+	if f.version != 0 {
+		return f.version, nil
 	}
-	sort.Sort(rows)
-
-	return pgdiff.NewMatViewSchema(rows), nil
-}
-
-// Owner returns an OwnerSchema that compares the ownership of tables, sequences, and views between two
-// databases or schemas
-func (f *SchemaFactory) Owner() (*pgdiff.OwnerSchema, error) {
-	buf := new(bytes.Buffer)
-	err := ownerSqlTemplate.Execute(buf, f.dbInfo)
+	rows, err := f.queryStrings(serverVersionNumSql)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-
-	rowChan, _ := pgutil.QueryStrings(f.conn, buf.String())
-
-	rows := make(pgdiff.OwnerRows, 0)
-	for row := range rowChan {
-		rows = append(rows, row)
+	if len(rows) != 1 {
+		return 0, pgdiff.NewError("reading server_version_num: no rows")
 	}
-	sort.Sort(rows)
-
-	return pgdiff.NewOwnerSchema(rows), nil
+	f.version, err = strconv.Atoi(rows[0]["version_num"])
+	return f.version, err
 }
 
-// Role returns a RoleSchema that compares the roles between two databases or schemas.
-func (f *SchemaFactory) Role() (*pgdiff.RoleSchema, error) {
-
-	rowChan, _ := pgutil.QueryStrings(f.conn, roleSql)
-
-	rows := make(pgdiff.RoleRows, 0)
-	for row := range rowChan {
-		rows = append(rows, row)
+// canReadConninfo reports whether the user may read the connection strings of subscriptions, which only superusers
+// may by default, checking once.
+func (f *SchemaFactory) canReadConninfo() (bool, error) {
+	if f.conninfoChecked {
+		return f.conninfo, nil
 	}
-	sort.Sort(rows)
-
-	return pgdiff.NewRoleSchema(rows), nil
-}
-
-// Schemata returns a SchemataSchema that outputs SQL to make the dbSchema names match between DBs
-func (f *SchemaFactory) Schemata() (*pgdiff.SchemataSchema, error) {
-	rowChan, _ := pgutil.QueryStrings(f.conn, schemataSql)
-
-	rows := make(pgdiff.SchemataRows, 0)
-	for row := range rowChan {
-		rows = append(rows, row)
+	rows, err := f.queryStrings(conninfoPrivilegeSql)
+	if err != nil {
+		return false, err
 	}
-	sort.Sort(rows)
-
-	return pgdiff.NewSchemataSchema(rows), nil
+	f.conninfo, f.conninfoChecked = len(rows) == 1 && rows[0]["granted"] == "true", true
+	return f.conninfo, nil
 }
 
-// Sequence returns a SequenceSchema that outputs SQL to make the sequences match between DBs or schemas
-func (f *SchemaFactory) Sequence() (*pgdiff.SequenceSchema, error) {
-	buf := new(bytes.Buffer)
-	err := sequenceSqlTemplate.Execute(buf, f.dbInfo)
+// queryStrings runs query and returns its rows keyed by column name, with each value converted to a string as
+// pgutil.QueryStrings does, including "null" for NULL. Unlike pgutil.QueryStrings, errors are returned rather than
+// exiting.
+func (f *SchemaFactory) queryStrings(query string) ([]map[string]string, error) {
+	rows, err := f.conn.Query(query)
 	if err != nil {
 		return nil, err
 	}
-
-	rowChan, _ := pgutil.QueryStrings(f.conn, buf.String())
-
-	rows := make(pgdiff.SequenceRows, 0)
-	for row := range rowChan {
-		rows = append(rows, row)
-	}
-	sort.Sort(rows)
-
-	return pgdiff.NewSequenceSchema(rows, f.dbInfo.DbSchema), nil
-}
-
-// Table returns a TableSchema that outputs SQL to make the table names match between DBs
-func (f *SchemaFactory) Table() (*pgdiff.TableSchema, error) {
-	buf := new(bytes.Buffer)
-	err := tableSqlTemplate.Execute(buf, f.dbInfo)
+	defer rows.Close()
+	cols, err := rows.Columns()
 	if err != nil {
 		return nil, err
 	}
+	vals := make([]interface{}, len(cols))
+	ptrs := make([]interface{}, len(cols))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	out := make([]map[string]string, 0)
+	for rows.Next() {
+		err = rows.Scan(ptrs...)
+		if err != nil {
+			return nil, err
+		}
+		row := make(map[string]string, len(cols))
+		for i, col := range cols {
+			row[col] = valueString(vals[i])
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
 
-	rowChan, _ := pgutil.QueryStrings(f.conn, buf.String())
-
-	rows := make(pgdiff.TableRows, 0)
-	for row := range rowChan {
-		rows = append(rows, row)
+// valueString converts a scanned value to a string as pgutil.QueryStrings does.
+func valueString(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return "null"
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case int64:
+		return fmt.Sprintf("%d", v)
+	case float64:
+		return fmt.Sprintf("%f", v)
+	case bool:
+		return fmt.Sprintf("%t", v)
+	case time.Time:
+		return v.Format(isoFormat)
 	}
-	sort.Sort(rows)
+	return fmt.Sprintf("%v", val)
+}
 
-	return pgdiff.NewTableSchema(rows, f.dbInfo.DbSchema), nil
+// Exec runs query, which may hold several statements, in the database.
+func (f *SchemaFactory) Exec(query string) error {
+	_, err := f.conn.Exec(query)
+	return err
 }
 
-// Trigger returns a TriggerSchema that outputs SQL to make the triggers match between DBs
-func (f *SchemaFactory) Trigger() (*pgdiff.TriggerSchema, error) {
-	buf := new(bytes.Buffer)
-	err := triggerSqlTemplate.Execute(buf, f.dbInfo)
+// ServerInfo describes the database server and database the rows are read from.
+func (f *SchemaFactory) ServerInfo() (map[string]string, error) {
+	rows, err := f.queryStrings(serverSql)
 	if err != nil {
 		return nil, err
 	}
-
-	rowChan, _ := pgutil.QueryStrings(f.conn, buf.String())
-
-	rows := make(pgdiff.TriggerRows, 0)
-	for row := range rowChan {
-		rows = append(rows, row)
-	}
-	sort.Sort(rows)
-
-	return pgdiff.NewTriggerSchema(rows, f.dbInfo.DbSchema), nil
-}
-
-// View returns a ViewSchema that outputs SQL to make the views match between DBs
-func (f *SchemaFactory) View() (*pgdiff.ViewSchema, error) {
-	rowChan, _ := pgutil.QueryStrings(f.conn, viewSql)
-
-	rows := make(pgdiff.ViewRows, 0)
-	for row := range rowChan {
-		rows = append(rows, row)
+	info := map[string]string{}
+	for _, row := range rows {
+		info = row
 	}
-	sort.Sort(rows)
-
-	return pgdiff.NewViewSchema(rows), nil
+	info["host"] = f.dbInfo.DbHost
+	return info, nil
 }
 
 func (f *SchemaFactory) Identify(num int) *pgdiff.Notice {
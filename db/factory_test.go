@@ -0,0 +1,69 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/facefunk/pgdiff"
+	"github.com/joncrlsn/pgutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDependenciesError(t *testing.T) {
+	// Nothing listens on port 1, so the query fails to connect.
+	conn, err := sql.Open("postgres", "host=127.0.0.1 port=1 user=pgdiff dbname=pgdiff sslmode=disable connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	f := NewSchemaFactory(conn, &pgutil.DbInfo{DbSchema: "*"}).(*SchemaFactory)
+	deps, err := f.Dependencies()
+	assert.Error(t, err)
+	assert.Nil(t, deps)
+}
+
+func TestServerVersionError(t *testing.T) {
+	conn, err := sql.Open("postgres", "host=127.0.0.1 port=1 user=pgdiff dbname=pgdiff sslmode=disable connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	f := NewSchemaFactory(conn, &pgutil.DbInfo{DbSchema: "*"}).(*SchemaFactory)
+	_, err = f.query(pgdiff.PublicationSchemaType)
+	assert.Error(t, err)
+}
+
+func TestSupports(t *testing.T) {
+	f := NewSchemaFactory(nil, &pgutil.DbInfo{DbSchema: "*"})
+	p, ok := f.(pgdiff.PartialSchemaFactory)
+	assert.True(t, ok)
+	assert.True(t, p.Supports(pgdiff.RoleSchemaType))
+}
+
+func TestRowsError(t *testing.T) {
+	conn, err := sql.Open("postgres", "host=127.0.0.1 port=1 user=pgdiff dbname=pgdiff sslmode=disable connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	f := NewSchemaFactory(conn, &pgutil.DbInfo{DbSchema: "*"}).(*SchemaFactory)
+	rows, err := f.Rows(pgdiff.TableSchemaType)
+	assert.Error(t, err)
+	assert.Nil(t, rows)
+	info, err := f.ServerInfo()
+	assert.Error(t, err)
+	assert.Nil(t, info)
+}
+
+func TestValueString(t *testing.T) {
+	assert.Equal(t, "null", valueString(nil))
+	assert.Equal(t, "a", valueString([]byte("a")))
+	assert.Equal(t, "7", valueString(int64(7)))
+	assert.Equal(t, "1.500000", valueString(1.5))
+	assert.Equal(t, "true", valueString(true))
+	assert.Equal(t, "2022-06-01T09:30:00.000+0000", valueString(time.Date(2022, 6, 1, 9, 30, 0, 0, time.UTC)))
+}
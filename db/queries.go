@@ -8,26 +8,41 @@
 
 package db
 
-import "text/template"
+import (
+	"fmt"
+	"text/template"
+)
 
 var (
 	columnSqlTemplate            = initColumnSqlTemplate()
 	tableColumnSqlTemplate       = initTableColumnSqlTemplate()
 	foreignKeySqlTemplate        = initForeignKeySqlTemplate()
+	checkConstraintSqlTemplate   = initCheckConstraintSqlTemplate()
 	functionSqlTemplate          = initFunctionSqlTemplate()
 	grantAttributeSqlTemplate    = initGrantAttributeSqlTemplate()
 	grantRelationshipSqlTemplate = initGrantRelationshipSqlTemplate()
 	indexSqlTemplate             = initIndexSqlTemplate()
 	ownerSqlTemplate             = initOwnerSqlTemplate()
 	sequenceSqlTemplate          = initSequenceSqlTemplate()
+	extensionSqlTemplate         = initExtensionSqlTemplate()
+	enumSqlTemplate              = initEnumSqlTemplate()
+	domainSqlTemplate            = initDomainSqlTemplate()
+	typeSqlTemplate              = initTypeSqlTemplate()
 	tableSqlTemplate             = initTableSqlTemplate()
+	operatorSqlTemplate          = initOperatorSqlTemplate()
+	operatorClassSqlTemplate     = initOperatorClassSqlTemplate()
+	foreignTableSqlTemplate      = initForeignTableSqlTemplate()
 	triggerSqlTemplate           = initTriggerSqlTemplate()
+	ruleSqlTemplate              = initRuleSqlTemplate()
+	policySqlTemplate            = initPolicySqlTemplate()
+	commentSqlTemplate           = initCommentSqlTemplate()
 
 	matViewSql = `
 WITH matviews as ( SELECT schemaname || '.' || matviewname AS matviewname,
 definition
 FROM pg_catalog.pg_matviews 
 WHERE schemaname NOT LIKE 'pg_%' 
+` + notExtensionMember("pg_class", "(quote_ident(schemaname) || '.' || quote_ident(matviewname))::regclass") + `
 )
 SELECT
 matviewname,
@@ -56,6 +71,57 @@ SELECT r.rolname
 	        WHERE m.member = r.oid) as memberof
 FROM pg_catalog.pg_roles AS r
 ORDER BY r.rolname;
+`
+
+	foreignDataWrapperSql = `
+SELECT w.fdwname AS compare_name
+    , w.fdwname AS fdw_name
+    , NULLIF(w.fdwhandler, 0)::regproc::text AS handler
+    , NULLIF(w.fdwvalidator, 0)::regproc::text AS validator
+    , COALESCE(array_to_json(w.fdwoptions)::text, '[]') AS options
+FROM pg_catalog.pg_foreign_data_wrapper w
+WHERE true
+` + notExtensionMember("pg_foreign_data_wrapper", "w.oid") + `
+ORDER BY compare_name;
+`
+
+	foreignServerSql = `
+SELECT s.srvname AS compare_name
+    , s.srvname AS server_name
+    , w.fdwname AS fdw_name
+    , s.srvtype AS server_type
+    , s.srvversion AS server_version
+    , COALESCE(array_to_json(s.srvoptions)::text, '[]') AS options
+FROM pg_catalog.pg_foreign_server s
+INNER JOIN pg_catalog.pg_foreign_data_wrapper w ON (w.oid = s.srvfdw)
+WHERE true
+` + notExtensionMember("pg_foreign_server", "s.oid") + `
+ORDER BY compare_name;
+`
+
+	// userMappingSql reads option values in plain text, so SchemaFactory.Rows masks the secret ones.
+	userMappingSql = `
+SELECT u.srvname || '.' || CASE WHEN u.umuser = 0 THEN 'PUBLIC' ELSE u.usename END AS compare_name
+    , u.srvname AS server_name
+    , CASE WHEN u.umuser = 0 THEN 'PUBLIC' ELSE u.usename END AS user_name
+    , COALESCE(array_to_json(u.umoptions)::text, '[]') AS options
+FROM pg_catalog.pg_user_mappings u
+WHERE true
+` + notExtensionMember("pg_user_mapping", "u.umid") + `
+ORDER BY compare_name;
+`
+
+	eventTriggerSql = `
+SELECT e.evtname AS compare_name
+    , e.evtname AS trigger_name
+    , e.evtevent AS event
+    , (SELECT string_agg(quote_literal(tag), ', ' ORDER BY tag COLLATE "C") FROM unnest(e.evttags) AS tag) AS tags
+    , e.evtfoid::regproc::text AS function
+    , e.evtenabled AS enabled
+FROM pg_catalog.pg_event_trigger e
+WHERE true
+` + notExtensionMember("pg_event_trigger", "e.oid") + `
+ORDER BY compare_name;
 `
 
 	schemataSql = `
@@ -65,17 +131,258 @@ SELECT schema_name
 FROM information_schema.schemata
 WHERE schema_name NOT LIKE 'pg_%' 
   AND schema_name <> 'information_schema' 
+  ` + notExtensionMember("pg_namespace", "(SELECT oid FROM pg_catalog.pg_namespace WHERE nspname = schema_name)") + `
 ORDER BY schema_name;`
 
+	// dependencySql lists the normal dependencies between objects in non-system schemas, identified by schema type and
+	// the identity pgdiff gives them. A view depends through the _RETURN rule that defines it, a column default through
+	// pg_attrdef. An object created by an extension also stands for the extension. A partition depends on its parent
+	// through pg_inherits. Foreign data wrappers, servers, user mappings and event triggers belong to no schema. The
+	// pg_amop and pg_amproc entries of an operator class or family stand for it.
+	dependencySql = `
+WITH objects AS (
+    SELECT 'pg_class'::regclass::oid AS classid, c.oid AS objid, 0 AS objsubid, n.nspname AS schema_name
+        , CASE c.relkind WHEN 'v' THEN 'VIEW' WHEN 'm' THEN 'MATVIEW' WHEN 'S' THEN 'SEQUENCE' WHEN 'f' THEN 'FOREIGN_TABLE'
+            ELSE 'TABLE' END AS schema_type
+        , n.nspname || '.' || c.relname AS identity
+    FROM pg_catalog.pg_class c
+    INNER JOIN pg_catalog.pg_namespace n ON (n.oid = c.relnamespace)
+    WHERE c.relkind IN ('r', 'p', 'v', 'm', 'S', 'f')
+    UNION ALL
+    SELECT 'pg_class'::regclass::oid, c2.oid, 0, n.nspname, 'INDEX', n.nspname || '.' || c.relname || '.' || c2.relname
+    FROM pg_catalog.pg_index i
+    INNER JOIN pg_catalog.pg_class c ON (c.oid = i.indrelid)
+    INNER JOIN pg_catalog.pg_class c2 ON (c2.oid = i.indexrelid)
+    INNER JOIN pg_catalog.pg_namespace n ON (n.oid = c2.relnamespace)
+    UNION ALL
+    SELECT 'pg_class'::regclass::oid, c.oid, a.attnum::int, n.nspname, 'COLUMN', n.nspname || '.' || c.relname || '.' || a.attname
+    FROM pg_catalog.pg_attribute a
+    INNER JOIN pg_catalog.pg_class c ON (c.oid = a.attrelid)
+    INNER JOIN pg_catalog.pg_namespace n ON (n.oid = c.relnamespace)
+    WHERE a.attnum > 0 AND NOT a.attisdropped AND c.relkind IN ('r', 'p', 'v', 'm')
+    UNION ALL
+    SELECT 'pg_attrdef'::regclass::oid, d.oid, 0, n.nspname, 'COLUMN', n.nspname || '.' || c.relname || '.' || a.attname
+    FROM pg_catalog.pg_attrdef d
+    INNER JOIN pg_catalog.pg_attribute a ON (a.attrelid = d.adrelid AND a.attnum = d.adnum)
+    INNER JOIN pg_catalog.pg_class c ON (c.oid = d.adrelid)
+    INNER JOIN pg_catalog.pg_namespace n ON (n.oid = c.relnamespace)
+    UNION ALL
+    SELECT 'pg_rewrite'::regclass::oid, r.oid, 0, n.nspname
+        , CASE c.relkind WHEN 'm' THEN 'MATVIEW' ELSE 'VIEW' END, n.nspname || '.' || c.relname
+    FROM pg_catalog.pg_rewrite r
+    INNER JOIN pg_catalog.pg_class c ON (c.oid = r.ev_class)
+    INNER JOIN pg_catalog.pg_namespace n ON (n.oid = c.relnamespace)
+    WHERE c.relkind IN ('v', 'm') AND r.rulename = '_RETURN'
+    UNION ALL
+    SELECT 'pg_rewrite'::regclass::oid, r.oid, 0, n.nspname, 'RULE', n.nspname || '.' || c.relname || '.' || r.rulename
+    FROM pg_catalog.pg_rewrite r
+    INNER JOIN pg_catalog.pg_class c ON (c.oid = r.ev_class)
+    INNER JOIN pg_catalog.pg_namespace n ON (n.oid = c.relnamespace)
+    WHERE r.rulename <> '_RETURN'
+    UNION ALL
+    SELECT 'pg_proc'::regclass::oid, p.oid, 0, n.nspname, 'FUNCTION', n.nspname || '.' || p.proname || '(' || oidvectortypes(p.proargtypes) || ')'
+    FROM pg_catalog.pg_proc p
+    INNER JOIN pg_catalog.pg_namespace n ON (n.oid = p.pronamespace)
+    UNION ALL
+    SELECT 'pg_constraint'::regclass::oid, con.oid, 0, n.nspname
+        , CASE con.contype WHEN 'f' THEN 'FOREIGN_KEY' ELSE 'CHECK_CONSTRAINT' END, n.nspname || '.' || c.relname || '.' || con.conname
+    FROM pg_catalog.pg_constraint con
+    INNER JOIN pg_catalog.pg_class c ON (c.oid = con.conrelid)
+    INNER JOIN pg_catalog.pg_namespace n ON (n.oid = con.connamespace)
+    WHERE con.contype IN ('f', 'c')
+    UNION ALL
+    SELECT 'pg_type'::regclass::oid, t.oid, 0, n.nspname
+        , CASE t.typtype WHEN 'e' THEN 'ENUM' WHEN 'd' THEN 'DOMAIN' ELSE 'TYPE' END, n.nspname || '.' || t.typname
+    FROM pg_catalog.pg_type t
+    INNER JOIN pg_catalog.pg_namespace n ON (n.oid = t.typnamespace)
+    LEFT JOIN pg_catalog.pg_class c ON (c.oid = t.typrelid)
+    WHERE t.typtype IN ('e', 'd') OR c.relkind = 'c'
+    UNION ALL
+    SELECT 'pg_class'::regclass::oid, c.oid, a.attnum::int, n.nspname, 'TYPE', n.nspname || '.' || t.typname
+    FROM pg_catalog.pg_attribute a
+    INNER JOIN pg_catalog.pg_class c ON (c.oid = a.attrelid)
+    INNER JOIN pg_catalog.pg_type t ON (t.oid = c.reltype)
+    INNER JOIN pg_catalog.pg_namespace n ON (n.oid = t.typnamespace)
+    WHERE a.attnum > 0 AND NOT a.attisdropped AND c.relkind = 'c'
+    UNION ALL
+    SELECT 'pg_constraint'::regclass::oid, con.oid, 0, n.nspname, 'DOMAIN', n.nspname || '.' || t.typname
+    FROM pg_catalog.pg_constraint con
+    INNER JOIN pg_catalog.pg_type t ON (t.oid = con.contypid)
+    INNER JOIN pg_catalog.pg_namespace n ON (n.oid = t.typnamespace)
+    UNION ALL
+    SELECT d.classid, d.objid, 0, n.nspname, 'EXTENSION', x.extname
+    FROM pg_catalog.pg_depend d
+    INNER JOIN pg_catalog.pg_extension x ON (x.oid = d.refobjid)
+    INNER JOIN pg_catalog.pg_namespace n ON (n.oid = x.extnamespace)
+    WHERE d.refclassid = 'pg_extension'::regclass AND d.deptype = 'e'
+    UNION ALL
+    SELECT 'pg_trigger'::regclass::oid, t.oid, 0, n.nspname, 'TRIGGER', n.nspname || '.' || c.relname || '.' || t.tgname
+    FROM pg_catalog.pg_trigger t
+    INNER JOIN pg_catalog.pg_class c ON (c.oid = t.tgrelid)
+    INNER JOIN pg_catalog.pg_namespace n ON (n.oid = c.relnamespace)
+    WHERE NOT t.tgisinternal
+    UNION ALL
+    SELECT 'pg_policy'::regclass::oid, p.oid, 0, n.nspname, 'POLICY', n.nspname || '.' || c.relname || '.' || p.polname
+    FROM pg_catalog.pg_policy p
+    INNER JOIN pg_catalog.pg_class c ON (c.oid = p.polrelid)
+    INNER JOIN pg_catalog.pg_namespace n ON (n.oid = c.relnamespace)
+    UNION ALL
+    SELECT 'pg_foreign_data_wrapper'::regclass::oid, w.oid, 0, '', 'FOREIGN_DATA_WRAPPER', w.fdwname
+    FROM pg_catalog.pg_foreign_data_wrapper w
+    UNION ALL
+    SELECT 'pg_foreign_server'::regclass::oid, s.oid, 0, '', 'SERVER', s.srvname
+    FROM pg_catalog.pg_foreign_server s
+    UNION ALL
+    SELECT 'pg_user_mapping'::regclass::oid, u.umid, 0, '', 'USER_MAPPING'
+        , u.srvname || '.' || CASE WHEN u.umuser = 0 THEN 'PUBLIC' ELSE u.usename END
+    FROM pg_catalog.pg_user_mappings u
+    UNION ALL
+    SELECT 'pg_event_trigger'::regclass::oid, e.oid, 0, '', 'EVENT_TRIGGER', e.evtname
+    FROM pg_catalog.pg_event_trigger e
+    UNION ALL
+    SELECT 'pg_operator'::regclass::oid, o.oid, 0, n.nspname, 'OPERATOR', n.nspname || '.' || o.oprname || '('
+        || CASE WHEN o.oprleft = 0 THEN 'NONE' ELSE format_type(o.oprleft, NULL) END || ', ' || format_type(o.oprright, NULL) || ')'
+    FROM pg_catalog.pg_operator o
+    INNER JOIN pg_catalog.pg_namespace n ON (n.oid = o.oprnamespace)
+    UNION ALL
+    SELECT 'pg_opfamily'::regclass::oid, f.oid, 0, n.nspname, 'OPERATOR_CLASS', n.nspname || '.' || f.opfname || ' USING ' || am.amname
+    FROM pg_catalog.pg_opfamily f
+    INNER JOIN pg_catalog.pg_namespace n ON (n.oid = f.opfnamespace)
+    INNER JOIN pg_catalog.pg_am am ON (am.oid = f.opfmethod)
+    UNION ALL
+    SELECT 'pg_opclass'::regclass::oid, c.oid, 0, n.nspname, 'OPERATOR_CLASS', n.nspname || '.' || c.opcname || ' USING ' || am.amname
+    FROM pg_catalog.pg_opclass c
+    INNER JOIN pg_catalog.pg_namespace n ON (n.oid = c.opcnamespace)
+    INNER JOIN pg_catalog.pg_am am ON (am.oid = c.opcmethod)
+    UNION ALL
+    SELECT d.classid, d.objid, 0, n.nspname, 'OPERATOR_CLASS'
+        , n.nspname || '.' || COALESCE(c.opcname, f.opfname) || ' USING ' || am.amname
+    FROM pg_catalog.pg_depend d
+    LEFT JOIN pg_catalog.pg_opclass c ON (d.refclassid = 'pg_opclass'::regclass AND c.oid = d.refobjid)
+    LEFT JOIN pg_catalog.pg_opfamily f ON (d.refclassid = 'pg_opfamily'::regclass AND f.oid = d.refobjid)
+    INNER JOIN pg_catalog.pg_namespace n ON (n.oid = COALESCE(c.opcnamespace, f.opfnamespace))
+    INNER JOIN pg_catalog.pg_am am ON (am.oid = COALESCE(c.opcmethod, f.opfmethod))
+    WHERE d.classid IN ('pg_amop'::regclass, 'pg_amproc'::regclass) AND d.deptype IN ('i', 'a')
+)
+SELECT DISTINCT o.schema_type, o.identity, r.schema_type AS ref_schema_type, r.identity AS ref_identity
+FROM pg_catalog.pg_depend d
+INNER JOIN objects o ON (o.classid = d.classid AND o.objid = d.objid AND o.objsubid = d.objsubid)
+INNER JOIN objects r ON (r.classid = d.refclassid AND r.objid = d.refobjid AND r.objsubid = d.refobjsubid)
+WHERE d.deptype = 'n'
+AND (o.schema_type, o.identity) <> (r.schema_type, r.identity)
+AND o.schema_name NOT LIKE 'pg_%' AND o.schema_name <> 'information_schema'
+AND r.schema_name NOT LIKE 'pg_%' AND r.schema_name <> 'information_schema'
+UNION
+SELECT 'TABLE', n.nspname || '.' || c.relname, 'TABLE', pn.nspname || '.' || pc.relname
+FROM pg_catalog.pg_inherits i
+INNER JOIN pg_catalog.pg_class c ON (c.oid = i.inhrelid)
+INNER JOIN pg_catalog.pg_namespace n ON (n.oid = c.relnamespace)
+INNER JOIN pg_catalog.pg_class pc ON (pc.oid = i.inhparent)
+INNER JOIN pg_catalog.pg_namespace pn ON (pn.oid = pc.relnamespace)
+WHERE c.relkind IN ('r', 'p')
+AND n.nspname NOT LIKE 'pg_%' AND n.nspname <> 'information_schema'
+AND pn.nspname NOT LIKE 'pg_%' AND pn.nspname <> 'information_schema';
+`
+
+	serverSql = `
+SELECT current_database() AS database
+    , current_setting('server_version') AS version;
+`
+
+	serverVersionNumSql = `
+SELECT current_setting('server_version_num') AS version_num;
+`
+
+	conninfoPrivilegeSql = `
+SELECT has_column_privilege('pg_catalog.pg_subscription', 'subconninfo', 'SELECT')::text AS granted;
+`
+
 	viewSql = `
 SELECT schemaname || '.' || viewname AS viewname
 	, definition 
 FROM pg_views 
 WHERE schemaname NOT LIKE 'pg_%' 
+` + notExtensionMember("pg_class", "(quote_ident(schemaname) || '.' || quote_ident(viewname))::regclass") + `
 ORDER BY viewname;
 `
 )
 
+// publicationSql returns the query for publications on a server of the given server_version_num. Servers before
+// PostgreSQL 15 have no row filters or schema publications, so those read as null.
+func publicationSql(version int) string {
+	where := "pg_catalog.pg_get_expr(pr.prqual, pr.prrelid)"
+	schemas := `(SELECT string_agg(quote_ident(n.nspname), ', ' ORDER BY quote_ident(n.nspname) COLLATE "C")
+        FROM pg_catalog.pg_publication_namespace pn
+        INNER JOIN pg_catalog.pg_namespace n ON (n.oid = pn.pnnspid)
+        WHERE pn.pnpubid = p.oid)`
+	if version < 150000 {
+		where, schemas = "NULL", "NULL::text"
+	}
+	viaRoot := "CASE WHEN p.pubviaroot THEN 'YES' ELSE 'NO' END"
+	if version < 130000 {
+		viaRoot = "'NO'"
+	}
+	return `
+SELECT p.pubname AS compare_name
+    , p.pubname AS pub_name
+    , CASE WHEN p.puballtables THEN 'YES' ELSE 'NO' END AS all_tables
+    , array_to_string(ARRAY[CASE WHEN p.pubinsert THEN 'insert' END, CASE WHEN p.pubupdate THEN 'update' END
+        , CASE WHEN p.pubdelete THEN 'delete' END, CASE WHEN p.pubtruncate THEN 'truncate' END], ', ') AS publish
+    , ` + viaRoot + ` AS via_root
+    , COALESCE((SELECT json_agg(json_build_object('name', quote_ident(n.nspname) || '.' || quote_ident(c.relname)
+            , 'where', ` + where + `)
+            ORDER BY quote_ident(n.nspname) || '.' || quote_ident(c.relname) COLLATE "C")::text
+        FROM pg_catalog.pg_publication_rel pr
+        INNER JOIN pg_catalog.pg_class c ON (c.oid = pr.prrelid)
+        INNER JOIN pg_catalog.pg_namespace n ON (n.oid = c.relnamespace)
+        WHERE pr.prpubid = p.oid), '[]') AS tables
+    , ` + schemas + ` AS schemas
+FROM pg_catalog.pg_publication p
+WHERE true
+` + notExtensionMember("pg_publication", "p.oid") + `
+ORDER BY compare_name;
+`
+}
+
+// subscriptionSql returns the query for subscriptions on a server of the given server_version_num. Servers before
+// PostgreSQL 14 have no binary or streaming parameters, so those read as their defaults. Connection strings are read in
+// plain text, so SchemaFactory.Rows masks their passwords, and only if conninfo is set, as a query that so much as
+// names subconninfo fails for users without the privilege to read it. Otherwise they read as null.
+func subscriptionSql(version int, conninfo bool) string {
+	conninfoColumn := "s.subconninfo"
+	if !conninfo {
+		conninfoColumn = "NULL::text"
+	}
+	binary := "s.subbinary::text"
+	streaming := "CASE s.substream::text WHEN 'true' THEN 'on' WHEN 't' THEN 'on' WHEN 'p' THEN 'parallel' ELSE 'off' END"
+	if version < 140000 {
+		binary, streaming = "'false'", "'off'"
+	}
+	return `
+SELECT s.subname AS compare_name
+    , s.subname AS sub_name
+    , CASE WHEN s.subenabled THEN 'YES' ELSE 'NO' END AS enabled
+    , ` + conninfoColumn + ` AS conninfo
+    , (SELECT string_agg(quote_ident(pub), ', ' ORDER BY quote_ident(pub) COLLATE "C")
+        FROM unnest(s.subpublications) AS pub) AS publications
+    , s.subslotname AS slot_name
+    , ` + binary + ` AS binary
+    , ` + streaming + ` AS streaming
+    , s.subsynccommit AS synchronous_commit
+FROM pg_catalog.pg_subscription s
+WHERE s.subdbid = (SELECT oid FROM pg_catalog.pg_database WHERE datname = current_database())
+` + notExtensionMember("pg_subscription", "s.oid") + `
+ORDER BY compare_name;
+`
+}
+
+// notExtensionMember returns a condition that excludes objects created by an extension, given the system catalog
+// holding the object and an expression for its oid. The extension is compared instead, see initExtensionSqlTemplate.
+func notExtensionMember(catalog, oid string) string {
+	return fmt.Sprintf("AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend ext WHERE ext.classid = '%s'::regclass "+
+		"AND ext.objid = %s AND ext.deptype = 'e')", catalog, oid)
+}
+
 func initColumnSqlTemplate() *template.Template {
 	query := `
 SELECT table_schema
@@ -89,8 +396,17 @@ SELECT table_schema
     , is_identity
     , identity_generation
     , substring(udt_name from 2) AS array_type
+    , CASE WHEN EXISTS (SELECT 1 FROM pg_catalog.pg_partitioned_table pt
+        WHERE pt.partrelid = (quote_ident(table_schema) || '.' || quote_ident(table_name))::regclass
+        AND ordinal_position::int2 = ANY (pt.partattrs::int2[])) THEN 'YES' ELSE 'NO' END AS is_partition_key
+    , CASE WHEN (SELECT attinhcount > 0 AND NOT attislocal FROM pg_catalog.pg_attribute
+        WHERE attrelid = (quote_ident(table_schema) || '.' || quote_ident(table_name))::regclass
+        AND attname = column_name) THEN 'YES' ELSE 'NO' END AS is_inherited
 FROM information_schema.columns
 WHERE is_updatable = 'YES'
+AND NOT (SELECT relispartition OR relkind = 'f' FROM pg_catalog.pg_class
+    WHERE oid = (quote_ident(table_schema) || '.' || quote_ident(table_name))::regclass)
+` + notExtensionMember("pg_class", "(quote_ident(table_schema) || '.' || quote_ident(table_name))::regclass") + `
 {{if eq $.DbSchema "*" }}
 AND table_schema NOT LIKE 'pg_%' 
 AND table_schema <> 'information_schema' 
@@ -114,12 +430,21 @@ SELECT a.table_schema
     , is_nullable
     , column_default
     , character_maximum_length
+    , CASE WHEN EXISTS (SELECT 1 FROM pg_catalog.pg_partitioned_table pt
+        WHERE pt.partrelid = (quote_ident(a.table_schema) || '.' || quote_ident(a.table_name))::regclass
+        AND ordinal_position::int2 = ANY (pt.partattrs::int2[])) THEN 'YES' ELSE 'NO' END AS is_partition_key
+    , CASE WHEN (SELECT attinhcount > 0 AND NOT attislocal FROM pg_catalog.pg_attribute
+        WHERE attrelid = (quote_ident(a.table_schema) || '.' || quote_ident(a.table_name))::regclass
+        AND attname = column_name) THEN 'YES' ELSE 'NO' END AS is_inherited
 FROM information_schema.columns a
 INNER JOIN information_schema.tables b
     ON a.table_schema = b.table_schema AND
        a.table_name = b.table_name AND
        b.table_type = 'BASE TABLE'
 WHERE is_updatable = 'YES'
+AND NOT (SELECT relispartition OR relkind = 'f' FROM pg_catalog.pg_class
+    WHERE oid = (quote_ident(a.table_schema) || '.' || quote_ident(a.table_name))::regclass)
+` + notExtensionMember("pg_class", "(quote_ident(a.table_schema) || '.' || quote_ident(a.table_name))::regclass") + `
 {{if eq $.DbSchema "*" }}
 AND a.table_schema NOT LIKE 'pg_%' 
 AND a.table_schema <> 'information_schema' 
@@ -144,6 +469,8 @@ FROM pg_catalog.pg_constraint c
 INNER JOIN pg_class AS cl ON (c.conrelid = cl.oid)
 INNER JOIN pg_namespace AS ns ON (ns.oid = c.connamespace)
 WHERE c.contype = 'f'
+AND c.conparentid = 0
+` + notExtensionMember("pg_class", "cl.oid") + `
 {{if eq $.DbSchema "*"}}
 AND ns.nspname NOT LIKE 'pg_%' 
 AND ns.nspname <> 'information_schema' 
@@ -156,19 +483,80 @@ AND ns.nspname = '{{$.DbSchema}}'
 	return t
 }
 
+func initCheckConstraintSqlTemplate() *template.Template {
+	query := `
+SELECT {{if eq $.DbSchema "*" }}ns.nspname || '.' || {{end}}cl.relname || '.' || c.conname AS compare_name
+    , ns.nspname AS schema_name
+	, cl.relname AS table_name
+    , c.conname AS constraint_name
+	, pg_catalog.pg_get_constraintdef(c.oid) as constraint_def
+FROM pg_catalog.pg_constraint c
+INNER JOIN pg_class AS cl ON (c.conrelid = cl.oid)
+INNER JOIN pg_namespace AS ns ON (ns.oid = c.connamespace)
+WHERE c.contype = 'c'
+AND c.conislocal
+` + notExtensionMember("pg_class", "cl.oid") + `
+{{if eq $.DbSchema "*"}}
+AND ns.nspname NOT LIKE 'pg_%' 
+AND ns.nspname <> 'information_schema' 
+{{else}}
+AND ns.nspname = '{{$.DbSchema}}'
+{{end}}
+`
+	t := template.New("CheckConstraintSqlTmpl")
+	template.Must(t.Parse(query))
+	return t
+}
+
 func initFunctionSqlTemplate() *template.Template {
 	query := `
 SELECT n.nspname                 AS schema_name
-	, {{if eq $.DbSchema "*" }}n.nspname || '.' || {{end}}p.proname AS compare_name
+	, {{if eq $.DbSchema "*" }}n.nspname || '.' || {{end}}p.proname || '(' || oidvectortypes(p.proargtypes) || ')' AS compare_name
 	, p.proname                  AS function_name
 	, p.oid::regprocedure        AS fancy
+	, oidvectortypes(p.proargtypes) AS arg_types
 	, t.typname                  AS return_type
-	, pg_get_functiondef(p.oid)  AS definition
+	, CASE WHEN p.prokind IN ('f', 'w') THEN pg_get_function_result(p.oid) END AS result
+	, CASE p.prokind WHEN 'p' THEN 'PROCEDURE' WHEN 'a' THEN 'AGGREGATE' WHEN 'w' THEN 'WINDOW' ELSE 'FUNCTION' END AS kind
+	, CASE WHEN p.prokind = 'a' AND p.pronargs = 0 THEN '*'
+		ELSE pg_get_function_identity_arguments(p.oid) END AS identity_args
+	-- pg_get_functiondef cannot rebuild an aggregate, so its definition is put together from pg_aggregate
+	, CASE WHEN p.prokind <> 'a' THEN pg_get_functiondef(p.oid)
+		ELSE 'CREATE OR REPLACE AGGREGATE ' || quote_ident(n.nspname) || '.' || quote_ident(p.proname) || '('
+		|| CASE WHEN p.pronargs = 0 THEN '*' ELSE pg_get_function_arguments(p.oid) END || E') (\n    '
+		|| concat_ws(E',\n    '
+			, 'SFUNC = ' || a.aggtransfn::regproc
+			, 'STYPE = ' || format_type(a.aggtranstype, NULL)
+			, CASE WHEN a.aggtransspace <> 0 THEN 'SSPACE = ' || a.aggtransspace END
+			, CASE WHEN a.aggfinalfn <> 0 THEN 'FINALFUNC = ' || a.aggfinalfn::regproc END
+			, CASE WHEN a.aggfinalextra THEN 'FINALFUNC_EXTRA' END
+			, CASE WHEN a.aggfinalmodify <> CASE WHEN a.aggkind = 'n' THEN 'r' ELSE 'w' END
+				THEN 'FINALFUNC_MODIFY = ' || CASE a.aggfinalmodify WHEN 'r' THEN 'READ_ONLY' WHEN 's' THEN 'SHAREABLE' ELSE 'READ_WRITE' END END
+			, CASE WHEN a.aggcombinefn <> 0 THEN 'COMBINEFUNC = ' || a.aggcombinefn::regproc END
+			, CASE WHEN a.aggserialfn <> 0 THEN 'SERIALFUNC = ' || a.aggserialfn::regproc END
+			, CASE WHEN a.aggdeserialfn <> 0 THEN 'DESERIALFUNC = ' || a.aggdeserialfn::regproc END
+			, 'INITCOND = ' || quote_literal(a.agginitval)
+			, CASE WHEN a.aggmtransfn <> 0 THEN 'MSFUNC = ' || a.aggmtransfn::regproc END
+			, CASE WHEN a.aggminvtransfn <> 0 THEN 'MINVFUNC = ' || a.aggminvtransfn::regproc END
+			, CASE WHEN a.aggmtranstype <> 0 THEN 'MSTYPE = ' || format_type(a.aggmtranstype, NULL) END
+			, CASE WHEN a.aggmtransspace <> 0 THEN 'MSSPACE = ' || a.aggmtransspace END
+			, CASE WHEN a.aggmfinalfn <> 0 THEN 'MFINALFUNC = ' || a.aggmfinalfn::regproc END
+			, CASE WHEN a.aggmfinalextra THEN 'MFINALFUNC_EXTRA' END
+			, CASE WHEN a.aggmfinalmodify <> CASE WHEN a.aggkind = 'n' THEN 'r' ELSE 'w' END
+				THEN 'MFINALFUNC_MODIFY = ' || CASE a.aggmfinalmodify WHEN 'r' THEN 'READ_ONLY' WHEN 's' THEN 'SHAREABLE' ELSE 'READ_WRITE' END END
+			, 'MINITCOND = ' || quote_literal(a.aggminitval)
+			, CASE WHEN a.aggsortop <> 0 THEN 'SORTOP = OPERATOR(' || quote_ident(opn.nspname) || '.' || op.oprname || ')' END
+			, CASE p.proparallel WHEN 's' THEN 'PARALLEL = SAFE' WHEN 'r' THEN 'PARALLEL = RESTRICTED' END
+			, CASE WHEN a.aggkind = 'h' THEN 'HYPOTHETICAL' END
+		) || E'\n)' END AS definition
 FROM pg_proc AS p
 JOIN pg_type t ON (p.prorettype = t.oid)
 JOIN pg_namespace n ON (n.oid = p.pronamespace)
-JOIN pg_language l ON (p.prolang = l.oid AND l.lanname IN ('c','plpgsql', 'sql'))
+LEFT JOIN pg_aggregate a ON (a.aggfnoid = p.oid)
+LEFT JOIN pg_operator op ON (op.oid = a.aggsortop)
+LEFT JOIN pg_namespace opn ON (opn.oid = op.oprnamespace)
 WHERE true
+` + notExtensionMember("pg_proc", "p.oid") + `
 {{if eq $.DbSchema "*" }}
 AND n.nspname NOT LIKE 'pg_%' 
 AND n.nspname <> 'information_schema' 
@@ -202,6 +590,7 @@ INNER JOIN (SELECT attname, unnest(attacl) AS attacl, attrelid
            WHERE NOT attisdropped AND attacl IS NOT NULL)
       AS a ON (a.attrelid = c.oid)
 WHERE c.relkind IN ('r', 'v', 'f')
+` + notExtensionMember("pg_class", "c.oid") + `
 --AND pg_catalog.pg_table_is_visible(c.oid)
 {{ if eq $.DbSchema "*" }}
 AND n.nspname NOT LIKE 'pg_%'
@@ -231,6 +620,7 @@ SELECT n.nspname AS schema_name
 FROM pg_catalog.pg_class c
 LEFT JOIN pg_catalog.pg_namespace n ON (n.oid = c.relnamespace)
 WHERE c.relkind IN ('r', 'v', 'S', 'f')
+` + notExtensionMember("pg_class", "c.oid") + `
 --AND pg_catalog.pg_table_is_visible(c.oid)
 {{ if eq $.DbSchema "*" }}
 AND n.nspname NOT LIKE 'pg_%'
@@ -262,7 +652,8 @@ INNER JOIN pg_catalog.pg_class AS c2 ON (c2.oid = i.indexrelid)
 LEFT OUTER JOIN pg_catalog.pg_constraint con
     ON (con.conrelid = i.indrelid AND con.conindid = i.indexrelid AND con.contype IN ('p','u','x'))
 INNER JOIN pg_catalog.pg_namespace AS n ON (c2.relnamespace = n.oid)
-WHERE true
+WHERE NOT c2.relispartition
+` + notExtensionMember("pg_class", "c.oid") + `
 {{if eq $.DbSchema "*"}}
 AND n.nspname NOT LIKE 'pg_%' 
 AND n.nspname <> 'information_schema' 
@@ -289,6 +680,7 @@ FROM pg_class AS c
 INNER JOIN pg_roles AS a ON (a.oid = c.relowner)
 INNER JOIN pg_namespace AS n ON (n.oid = c.relnamespace)
 WHERE c.relkind IN ('r', 'S', 'v')
+` + notExtensionMember("pg_class", "c.oid") + `
 {{if eq $.DbSchema "*" }}
 AND n.nspname NOT LIKE 'pg_%' 
 AND n.nspname <> 'information_schema'
@@ -315,6 +707,7 @@ SELECT sequence_schema AS schema_name
 	, cycle_option 
 FROM information_schema.sequences
 WHERE true
+` + notExtensionMember("pg_class", "(quote_ident(sequence_schema) || '.' || quote_ident(sequence_name))::regclass") + `
 {{if eq $.DbSchema "*" }}
 AND sequence_schema NOT LIKE 'pg_%' 
 AND sequence_schema <> 'information_schema' 
@@ -328,23 +721,140 @@ AND sequence_schema = '{{$.DbSchema}}'
 	return t
 }
 
+func initExtensionSqlTemplate() *template.Template {
+	query := `
+SELECT x.extname AS compare_name
+    , x.extname AS extension_name
+    , n.nspname AS schema_name
+    , x.extversion AS version
+FROM pg_catalog.pg_extension x
+INNER JOIN pg_catalog.pg_namespace n ON (n.oid = x.extnamespace)
+WHERE true
+{{if eq $.DbSchema "*" }}
+AND n.nspname NOT LIKE 'pg_%' 
+AND n.nspname <> 'information_schema' 
+{{else}}
+AND n.nspname = '{{$.DbSchema}}'
+{{end}}
+`
+
+	t := template.New("ExtensionSqlTmpl")
+	template.Must(t.Parse(query))
+	return t
+}
+
+func initEnumSqlTemplate() *template.Template {
+	query := `
+SELECT n.nspname AS schema_name
+    , {{if eq $.DbSchema "*" }}n.nspname || '.' || {{end}}t.typname AS compare_name
+    , t.typname AS type_name
+    , COALESCE((SELECT json_agg(e.enumlabel ORDER BY e.enumsortorder) FROM pg_catalog.pg_enum e WHERE e.enumtypid = t.oid)::text, '[]') AS labels
+FROM pg_catalog.pg_type t
+INNER JOIN pg_catalog.pg_namespace n ON (n.oid = t.typnamespace)
+WHERE t.typtype = 'e'
+` + notExtensionMember("pg_type", "t.oid") + `
+{{if eq $.DbSchema "*" }}
+AND n.nspname NOT LIKE 'pg_%' 
+AND n.nspname <> 'information_schema' 
+{{else}}
+AND n.nspname = '{{$.DbSchema}}'
+{{end}}
+`
+
+	t := template.New("EnumSqlTmpl")
+	template.Must(t.Parse(query))
+	return t
+}
+
+func initDomainSqlTemplate() *template.Template {
+	query := `
+SELECT n.nspname AS schema_name
+    , {{if eq $.DbSchema "*" }}n.nspname || '.' || {{end}}t.typname AS compare_name
+    , t.typname AS domain_name
+    , pg_catalog.format_type(t.typbasetype, t.typtypmod) AS data_type
+    , COALESCE(t.typdefault, 'null') AS domain_default
+    , CASE WHEN t.typnotnull THEN 'NO' ELSE 'YES' END AS is_nullable
+    , COALESCE((SELECT json_agg(json_build_object('name', c.conname, 'def', pg_catalog.pg_get_constraintdef(c.oid)) ORDER BY c.conname)
+        FROM pg_catalog.pg_constraint c WHERE c.contypid = t.oid AND c.contype = 'c')::text, '[]') AS constraints
+FROM pg_catalog.pg_type t
+INNER JOIN pg_catalog.pg_namespace n ON (n.oid = t.typnamespace)
+WHERE t.typtype = 'd'
+` + notExtensionMember("pg_type", "t.oid") + `
+{{if eq $.DbSchema "*" }}
+AND n.nspname NOT LIKE 'pg_%' 
+AND n.nspname <> 'information_schema' 
+{{else}}
+AND n.nspname = '{{$.DbSchema}}'
+{{end}}
+`
+
+	t := template.New("DomainSqlTmpl")
+	template.Must(t.Parse(query))
+	return t
+}
+
+func initTypeSqlTemplate() *template.Template {
+	query := `
+SELECT n.nspname AS schema_name
+    , {{if eq $.DbSchema "*" }}n.nspname || '.' || {{end}}t.typname AS compare_name
+    , t.typname AS type_name
+    , COALESCE((SELECT json_agg(json_build_object('name', a.attname, 'type', pg_catalog.format_type(a.atttypid, a.atttypmod)) ORDER BY a.attnum)
+        FROM pg_catalog.pg_attribute a WHERE a.attrelid = t.typrelid AND a.attnum > 0 AND NOT a.attisdropped)::text, '[]') AS attributes
+FROM pg_catalog.pg_type t
+INNER JOIN pg_catalog.pg_namespace n ON (n.oid = t.typnamespace)
+INNER JOIN pg_catalog.pg_class c ON (c.oid = t.typrelid)
+WHERE t.typtype = 'c'
+AND c.relkind = 'c'
+` + notExtensionMember("pg_type", "t.oid") + `
+{{if eq $.DbSchema "*" }}
+AND n.nspname NOT LIKE 'pg_%' 
+AND n.nspname <> 'information_schema' 
+{{else}}
+AND n.nspname = '{{$.DbSchema}}'
+{{end}}
+`
+
+	t := template.New("TypeSqlTmpl")
+	template.Must(t.Parse(query))
+	return t
+}
+
 func initTableSqlTemplate() *template.Template {
 
 	query := `
-SELECT table_schema
-    , {{if eq $.DbSchema "*" }}table_schema || '.' || {{end}}table_name AS compare_name
-	, table_name
-    , CASE table_type 
-	  WHEN 'BASE TABLE' THEN 'TABLE' 
-	  ELSE table_type END AS table_type
-    , is_insertable_into
-FROM information_schema.tables 
-WHERE table_type = 'BASE TABLE'
+SELECT n.nspname AS table_schema
+    , {{if eq $.DbSchema "*" }}n.nspname || '.' || {{end}}c.relname AS compare_name
+	, c.relname AS table_name
+    , 'TABLE' AS table_type
+    , 'YES' AS is_insertable_into
+    , COALESCE(pg_catalog.pg_get_partkeydef(c.oid), 'null') AS partition_key
+    , COALESCE((SELECT string_agg(quote_ident(a.attname) || ' ' || pg_catalog.format_type(a.atttypid, a.atttypmod)
+            || CASE WHEN a.attnotnull THEN ' NOT NULL' ELSE '' END, ', ' ORDER BY a.attnum)
+        FROM pg_catalog.pg_partitioned_table pt
+        INNER JOIN pg_catalog.pg_attribute a ON (a.attrelid = pt.partrelid AND a.attnum = ANY (pt.partattrs::int2[]))
+        WHERE pt.partrelid = c.oid), 'null') AS partition_columns
+    , COALESCE(pn.nspname, 'null') AS parent_schema
+    , COALESCE(pc.relname, 'null') AS parent_table
+    , COALESCE(pg_catalog.pg_get_expr(c.relpartbound, c.oid), 'null') AS partition_bound
+    , COALESCE((SELECT string_agg(CASE WHEN ipc.relnamespace = c.relnamespace THEN ipc.relname
+            ELSE ipn.nspname || '.' || ipc.relname END, ', ' ORDER BY ii.inhseqno)
+        FROM pg_catalog.pg_inherits ii
+        INNER JOIN pg_catalog.pg_class ipc ON (ipc.oid = ii.inhparent)
+        INNER JOIN pg_catalog.pg_namespace ipn ON (ipn.oid = ipc.relnamespace)
+        WHERE ii.inhrelid = c.oid AND NOT c.relispartition), 'null') AS inherits
+FROM pg_catalog.pg_class c
+INNER JOIN pg_catalog.pg_namespace n ON (n.oid = c.relnamespace)
+LEFT JOIN pg_catalog.pg_inherits i ON (i.inhrelid = c.oid AND c.relispartition)
+LEFT JOIN pg_catalog.pg_class pc ON (pc.oid = i.inhparent)
+LEFT JOIN pg_catalog.pg_namespace pn ON (pn.oid = pc.relnamespace)
+WHERE c.relkind IN ('r', 'p')
+AND c.relpersistence <> 't'
+` + notExtensionMember("pg_class", "c.oid") + `
 {{if eq $.DbSchema "*" }}
-AND table_schema NOT LIKE 'pg_%' 
-AND table_schema <> 'information_schema' 
+AND n.nspname NOT LIKE 'pg_%' 
+AND n.nspname <> 'information_schema' 
 {{else}}
-AND table_schema = '{{$.DbSchema}}'
+AND n.nspname = '{{$.DbSchema}}'
 {{end}}
 ORDER BY compare_name;
 `
@@ -353,6 +863,38 @@ ORDER BY compare_name;
 	return t
 }
 
+func initForeignTableSqlTemplate() *template.Template {
+	query := `
+SELECT n.nspname AS schema_name
+    , {{if eq $.DbSchema "*" }}n.nspname || '.' || {{end}}c.relname AS compare_name
+    , c.relname AS table_name
+    , s.srvname AS server_name
+    , COALESCE(array_to_json(ft.ftoptions)::text, '[]') AS options
+    , COALESCE((SELECT json_agg(json_build_object('name', a.attname
+            , 'type', pg_catalog.format_type(a.atttypid, a.atttypmod)
+            , 'not_null', a.attnotnull
+            , 'options', COALESCE(a.attfdwoptions, '{}')) ORDER BY a.attnum)::text
+        FROM pg_catalog.pg_attribute a
+        WHERE a.attrelid = c.oid AND a.attnum > 0 AND NOT a.attisdropped), '[]') AS columns
+FROM pg_catalog.pg_foreign_table ft
+INNER JOIN pg_catalog.pg_class c ON (c.oid = ft.ftrelid)
+INNER JOIN pg_catalog.pg_namespace n ON (n.oid = c.relnamespace)
+INNER JOIN pg_catalog.pg_foreign_server s ON (s.oid = ft.ftserver)
+WHERE true
+` + notExtensionMember("pg_class", "c.oid") + `
+{{if eq $.DbSchema "*" }}
+AND n.nspname NOT LIKE 'pg_%' 
+AND n.nspname <> 'information_schema' 
+{{else}}
+AND n.nspname = '{{$.DbSchema}}'
+{{end}}
+ORDER BY compare_name;
+`
+	t := template.New("ForeignTableSqlTmpl")
+	template.Must(t.Parse(query))
+	return t
+}
+
 func initTriggerSqlTemplate() *template.Template {
 	query := `
 SELECT n.nspname AS schema_name
@@ -365,6 +907,7 @@ FROM pg_catalog.pg_trigger t
 INNER JOIN pg_catalog.pg_class c ON (c.oid = t.tgrelid)
 INNER JOIN pg_catalog.pg_namespace n ON (n.oid = c.relnamespace)
 WHERE not t.tgisinternal
+` + notExtensionMember("pg_class", "c.oid") + `
 {{if eq $.DbSchema "*" }}
 AND n.nspname NOT LIKE 'pg_%' 
 AND n.nspname <> 'information_schema' 
@@ -376,3 +919,272 @@ AND n.nspname = '{{$.DbSchema}}'
 	template.Must(t.Parse(query))
 	return t
 }
+
+func initOperatorSqlTemplate() *template.Template {
+	query := `
+SELECT n.nspname AS schema_name
+   , {{if eq $.DbSchema "*" }}n.nspname || '.' || {{end}}o.oprname || '(' || CASE WHEN o.oprleft = 0 THEN 'NONE' ELSE format_type(o.oprleft, NULL) END
+       || ', ' || format_type(o.oprright, NULL) || ')' AS compare_name
+   , o.oprname AS operator_name
+   , CASE WHEN o.oprleft = 0 THEN 'NONE' ELSE format_type(o.oprleft, NULL) END AS left_type
+   , format_type(o.oprright, NULL) AS right_type
+   , o.oprcode::regproc AS function
+   , CASE WHEN o.oprcom <> 0 THEN 'OPERATOR(' || quote_ident(cn.nspname) || '.' || co.oprname || ')' END AS commutator
+   , CASE WHEN o.oprnegate <> 0 THEN 'OPERATOR(' || quote_ident(nn.nspname) || '.' || no.oprname || ')' END AS negator
+   , CASE WHEN o.oprrest <> 0 THEN o.oprrest::regproc::text END AS restrict
+   , CASE WHEN o.oprjoin <> 0 THEN o.oprjoin::regproc::text END AS join
+   , CASE WHEN o.oprcanhash THEN 'YES' ELSE 'NO' END AS hashes
+   , CASE WHEN o.oprcanmerge THEN 'YES' ELSE 'NO' END AS merges
+FROM pg_catalog.pg_operator o
+INNER JOIN pg_catalog.pg_namespace n ON (n.oid = o.oprnamespace)
+LEFT JOIN pg_catalog.pg_operator co ON (co.oid = o.oprcom)
+LEFT JOIN pg_catalog.pg_namespace cn ON (cn.oid = co.oprnamespace)
+LEFT JOIN pg_catalog.pg_operator no ON (no.oid = o.oprnegate)
+LEFT JOIN pg_catalog.pg_namespace nn ON (nn.oid = no.oprnamespace)
+WHERE true
+` + notExtensionMember("pg_operator", "o.oid") + `
+{{if eq $.DbSchema "*" }}
+AND n.nspname NOT LIKE 'pg_%' 
+AND n.nspname <> 'information_schema' 
+{{else}}
+AND n.nspname = '{{$.DbSchema}}'
+{{end}}
+`
+	t := template.New("OperatorSqlTmpl")
+	template.Must(t.Parse(query))
+	return t
+}
+
+// opFamilyMembersSql selects, as a JSON array, the members of an operator class or family that depend on the object
+// refclass refobjid with the dependency type deptype: internally on a class for its members, automatically on a family
+// for those added to it outside of any class.
+func opFamilyMembersSql(refclass, refobjid, deptype string) string {
+	return fmt.Sprintf(`(SELECT COALESCE(json_agg(m.member ORDER BY m.kind, m.num, m.member COLLATE "C"), '[]')::text FROM (
+       SELECT 1 AS kind, ao.amopstrategy AS num, 'OPERATOR ' || ao.amopstrategy || ' ' || ao.amopopr::regoperator
+           || CASE WHEN ao.amoppurpose = 'o' THEN ' FOR ORDER BY ' || CASE WHEN pg_opfamily_is_visible(sf.oid)
+               THEN quote_ident(sf.opfname) ELSE quote_ident(sfn.nspname) || '.' || quote_ident(sf.opfname) END ELSE '' END AS member
+       FROM pg_catalog.pg_amop ao
+       INNER JOIN pg_catalog.pg_depend d ON (d.classid = 'pg_amop'::regclass AND d.objid = ao.oid)
+       LEFT JOIN pg_catalog.pg_opfamily sf ON (sf.oid = ao.amopsortfamily)
+       LEFT JOIN pg_catalog.pg_namespace sfn ON (sfn.oid = sf.opfnamespace)
+       WHERE d.refclassid = '%[1]s'::regclass AND d.refobjid = %[2]s AND d.deptype = '%[3]s'
+       UNION ALL
+       SELECT 2, ap.amprocnum, 'FUNCTION ' || ap.amprocnum || ' (' || format_type(ap.amproclefttype, NULL) || ', '
+           || format_type(ap.amprocrighttype, NULL) || ') ' || ap.amproc::regprocedure
+       FROM pg_catalog.pg_amproc ap
+       INNER JOIN pg_catalog.pg_depend d ON (d.classid = 'pg_amproc'::regclass AND d.objid = ap.oid)
+       WHERE d.refclassid = '%[1]s'::regclass AND d.refobjid = %[2]s AND d.deptype = '%[3]s'
+   ) m)`, refclass, refobjid, deptype)
+}
+
+func initOperatorClassSqlTemplate() *template.Template {
+	query := `
+SELECT n.nspname AS schema_name
+   , {{if eq $.DbSchema "*" }}n.nspname || '.' || {{end}}f.opfname || ' USING ' || am.amname AS compare_name
+   , n.nspname AS family_schema
+   , f.opfname AS family_name
+   , am.amname AS access_method
+   , 'null' AS class_name
+   , 'null' AS is_default
+   , 'null' AS type
+   , 'null' AS storage
+   , ` + opFamilyMembersSql("pg_opfamily", "f.oid", "a") + ` AS members
+FROM pg_catalog.pg_opfamily f
+INNER JOIN pg_catalog.pg_namespace n ON (n.oid = f.opfnamespace)
+INNER JOIN pg_catalog.pg_am am ON (am.oid = f.opfmethod)
+WHERE true
+` + notExtensionMember("pg_opfamily", "f.oid") + `
+{{if eq $.DbSchema "*" }}
+AND n.nspname NOT LIKE 'pg_%' 
+AND n.nspname <> 'information_schema' 
+{{else}}
+AND n.nspname = '{{$.DbSchema}}'
+{{end}}
+UNION ALL
+SELECT n.nspname AS schema_name
+   , {{if eq $.DbSchema "*" }}fn.nspname || '.' || {{end}}f.opfname || ' USING ' || am.amname || ' CLASS '
+       || {{if eq $.DbSchema "*" }}n.nspname || '.' || {{end}}c.opcname AS compare_name
+   , fn.nspname AS family_schema
+   , f.opfname AS family_name
+   , am.amname AS access_method
+   , c.opcname AS class_name
+   , CASE WHEN c.opcdefault THEN 'YES' ELSE 'NO' END AS is_default
+   , format_type(c.opcintype, NULL) AS type
+   , CASE WHEN c.opckeytype <> 0 THEN format_type(c.opckeytype, NULL) ELSE 'null' END AS storage
+   , ` + opFamilyMembersSql("pg_opclass", "c.oid", "i") + ` AS members
+FROM pg_catalog.pg_opclass c
+INNER JOIN pg_catalog.pg_namespace n ON (n.oid = c.opcnamespace)
+INNER JOIN pg_catalog.pg_opfamily f ON (f.oid = c.opcfamily)
+INNER JOIN pg_catalog.pg_namespace fn ON (fn.oid = f.opfnamespace)
+INNER JOIN pg_catalog.pg_am am ON (am.oid = c.opcmethod)
+WHERE true
+` + notExtensionMember("pg_opclass", "c.oid") + `
+{{if eq $.DbSchema "*" }}
+AND n.nspname NOT LIKE 'pg_%' 
+AND n.nspname <> 'information_schema' 
+{{else}}
+AND n.nspname = '{{$.DbSchema}}'
+{{end}}
+`
+	t := template.New("OperatorClassSqlTmpl")
+	template.Must(t.Parse(query))
+	return t
+}
+
+func initRuleSqlTemplate() *template.Template {
+	query := `
+SELECT n.nspname AS schema_name
+   , {{if eq $.DbSchema "*" }}n.nspname || '.' || {{end}}c.relname || '.' || r.rulename AS compare_name
+   , c.relname AS table_name
+   , r.rulename AS rule_name
+   , regexp_replace(pg_catalog.pg_get_ruledef(r.oid), ';$', '') AS rule_def
+FROM pg_catalog.pg_rewrite r
+INNER JOIN pg_catalog.pg_class c ON (c.oid = r.ev_class)
+INNER JOIN pg_catalog.pg_namespace n ON (n.oid = c.relnamespace)
+WHERE r.rulename <> '_RETURN'
+` + notExtensionMember("pg_class", "c.oid") + `
+{{if eq $.DbSchema "*" }}
+AND n.nspname NOT LIKE 'pg_%' 
+AND n.nspname <> 'information_schema' 
+{{else}}
+AND n.nspname = '{{$.DbSchema}}'
+{{end}}
+`
+	t := template.New("RuleSqlTmpl")
+	template.Must(t.Parse(query))
+	return t
+}
+
+func initPolicySqlTemplate() *template.Template {
+	query := `
+SELECT n.nspname AS schema_name
+   , {{if eq $.DbSchema "*" }}n.nspname || '.' || {{end}}c.relname AS compare_name
+   , c.relname AS table_name
+   , 'null' AS policy_name
+   , CASE WHEN c.relrowsecurity THEN 'YES' ELSE 'NO' END AS row_security
+   , CASE WHEN c.relforcerowsecurity THEN 'YES' ELSE 'NO' END AS force_row_security
+   , 'null' AS command
+   , 'null' AS permissive
+   , 'null' AS roles
+   , 'null' AS using_expr
+   , 'null' AS check_expr
+FROM pg_catalog.pg_class c
+INNER JOIN pg_catalog.pg_namespace n ON (n.oid = c.relnamespace)
+WHERE c.relkind IN ('r', 'p')
+AND (c.relrowsecurity OR c.relforcerowsecurity)
+` + notExtensionMember("pg_class", "c.oid") + `
+{{if eq $.DbSchema "*" }}
+AND n.nspname NOT LIKE 'pg_%' 
+AND n.nspname <> 'information_schema' 
+{{else}}
+AND n.nspname = '{{$.DbSchema}}'
+{{end}}
+UNION ALL
+SELECT n.nspname AS schema_name
+   , {{if eq $.DbSchema "*" }}n.nspname || '.' || {{end}}c.relname || '.' || p.polname AS compare_name
+   , c.relname AS table_name
+   , p.polname AS policy_name
+   , 'null' AS row_security
+   , 'null' AS force_row_security
+   , CASE p.polcmd WHEN 'r' THEN 'SELECT' WHEN 'a' THEN 'INSERT' WHEN 'w' THEN 'UPDATE' WHEN 'd' THEN 'DELETE' ELSE 'ALL' END AS command
+   , CASE WHEN p.polpermissive THEN 'PERMISSIVE' ELSE 'RESTRICTIVE' END AS permissive
+   , CASE WHEN p.polroles = '{0}' THEN 'PUBLIC'
+       ELSE (SELECT string_agg(quote_ident(r.rolname), ', ' ORDER BY quote_ident(r.rolname) COLLATE "C")
+             FROM pg_catalog.pg_roles r WHERE r.oid = ANY (p.polroles)) END AS roles
+   , COALESCE(pg_catalog.pg_get_expr(p.polqual, p.polrelid), 'null') AS using_expr
+   , COALESCE(pg_catalog.pg_get_expr(p.polwithcheck, p.polrelid), 'null') AS check_expr
+FROM pg_catalog.pg_policy p
+INNER JOIN pg_catalog.pg_class c ON (c.oid = p.polrelid)
+INNER JOIN pg_catalog.pg_namespace n ON (n.oid = c.relnamespace)
+WHERE true
+` + notExtensionMember("pg_class", "c.oid") + `
+{{if eq $.DbSchema "*" }}
+AND n.nspname NOT LIKE 'pg_%' 
+AND n.nspname <> 'information_schema' 
+{{else}}
+AND n.nspname = '{{$.DbSchema}}'
+{{end}}
+`
+	t := template.New("PolicySqlTmpl")
+	template.Must(t.Parse(query))
+	return t
+}
+
+func initCommentSqlTemplate() *template.Template {
+	query := `
+WITH comments AS (
+    SELECT n.nspname AS schema_name
+        , CASE c.relkind WHEN 'v' THEN 'VIEW' WHEN 'm' THEN 'MATERIALIZED VIEW' WHEN 'i' THEN 'INDEX' WHEN 'I' THEN 'INDEX'
+            WHEN 'S' THEN 'SEQUENCE' WHEN 'f' THEN 'FOREIGN TABLE' ELSE 'TABLE' END AS object_type
+        , 'null' AS table_name
+        , c.relname AS object_name
+        , d.description AS comment
+    FROM pg_catalog.pg_description d
+    INNER JOIN pg_catalog.pg_class c ON (c.oid = d.objoid)
+    INNER JOIN pg_catalog.pg_namespace n ON (n.oid = c.relnamespace)
+    WHERE d.classoid = 'pg_class'::regclass AND d.objsubid = 0
+    AND c.relkind IN ('r', 'p', 'v', 'm', 'i', 'I', 'S', 'f')
+    ` + notExtensionMember("pg_class", "c.oid") + `
+    UNION ALL
+    SELECT n.nspname, 'COLUMN', c.relname, a.attname, d.description
+    FROM pg_catalog.pg_description d
+    INNER JOIN pg_catalog.pg_class c ON (c.oid = d.objoid)
+    INNER JOIN pg_catalog.pg_attribute a ON (a.attrelid = c.oid AND a.attnum = d.objsubid)
+    INNER JOIN pg_catalog.pg_namespace n ON (n.oid = c.relnamespace)
+    WHERE d.classoid = 'pg_class'::regclass AND d.objsubid > 0
+    AND c.relkind IN ('r', 'p', 'v', 'm', 'f')
+    ` + notExtensionMember("pg_class", "c.oid") + `
+    UNION ALL
+    SELECT n.nspname, CASE p.prokind WHEN 'p' THEN 'PROCEDURE' WHEN 'a' THEN 'AGGREGATE' ELSE 'FUNCTION' END
+        , 'null', p.proname || '(' || CASE WHEN p.prokind = 'a' AND p.pronargs = 0 THEN '*'
+            ELSE pg_catalog.pg_get_function_identity_arguments(p.oid) END || ')', d.description
+    FROM pg_catalog.pg_description d
+    INNER JOIN pg_catalog.pg_proc p ON (p.oid = d.objoid)
+    INNER JOIN pg_catalog.pg_namespace n ON (n.oid = p.pronamespace)
+    WHERE d.classoid = 'pg_proc'::regclass
+    ` + notExtensionMember("pg_proc", "p.oid") + `
+    UNION ALL
+    SELECT n.nspname, 'CONSTRAINT', c.relname, con.conname, d.description
+    FROM pg_catalog.pg_description d
+    INNER JOIN pg_catalog.pg_constraint con ON (con.oid = d.objoid)
+    INNER JOIN pg_catalog.pg_class c ON (c.oid = con.conrelid)
+    INNER JOIN pg_catalog.pg_namespace n ON (n.oid = c.relnamespace)
+    WHERE d.classoid = 'pg_constraint'::regclass
+    ` + notExtensionMember("pg_class", "c.oid") + `
+    UNION ALL
+    SELECT n.nspname, CASE t.typtype WHEN 'd' THEN 'DOMAIN' ELSE 'TYPE' END, 'null', t.typname, d.description
+    FROM pg_catalog.pg_description d
+    INNER JOIN pg_catalog.pg_type t ON (t.oid = d.objoid)
+    INNER JOIN pg_catalog.pg_namespace n ON (n.oid = t.typnamespace)
+    LEFT JOIN pg_catalog.pg_class c ON (c.oid = t.typrelid)
+    WHERE d.classoid = 'pg_type'::regclass
+    AND (t.typtype IN ('e', 'd') OR c.relkind = 'c')
+    ` + notExtensionMember("pg_type", "t.oid") + `
+    UNION ALL
+    SELECT n.nspname, 'SCHEMA', 'null', n.nspname, d.description
+    FROM pg_catalog.pg_description d
+    INNER JOIN pg_catalog.pg_namespace n ON (n.oid = d.objoid)
+    WHERE d.classoid = 'pg_namespace'::regclass
+    ` + notExtensionMember("pg_namespace", "n.oid") + `
+)
+SELECT schema_name
+    , CASE WHEN object_type = 'SCHEMA' THEN 'SCHEMA'{{if eq $.DbSchema "*" }} || ' ' || schema_name{{end}}
+        ELSE object_type || ' ' || {{if eq $.DbSchema "*" }}schema_name || '.' || {{end}}
+            CASE WHEN table_name = 'null' THEN '' ELSE table_name || '.' END || object_name END AS compare_name
+    , object_type
+    , table_name
+    , object_name
+    , comment
+FROM comments
+WHERE true
+{{if eq $.DbSchema "*" }}
+AND schema_name NOT LIKE 'pg_%' 
+AND schema_name <> 'information_schema' 
+{{else}}
+AND schema_name = '{{$.DbSchema}}'
+{{end}}
+`
+	t := template.New("CommentSqlTmpl")
+	template.Must(t.Parse(query))
+	return t
+}
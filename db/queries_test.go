@@ -0,0 +1,53 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"testing"
+
+	"github.com/facefunk/pgdiff"
+	"github.com/joncrlsn/pgutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryExcludesExtensionMembers(t *testing.T) {
+	f := &SchemaFactory{dbInfo: &pgutil.DbInfo{DbSchema: "*"}, version: 150000, conninfo: true,
+		conninfoChecked: true}
+	for _, schemaType := range append(pgdiff.AllSchemaTypes, pgdiff.TableColumnSchemaType) {
+		query, err := f.query(schemaType)
+		if !assert.NoError(t, err, schemaType) {
+			continue
+		}
+		switch schemaType {
+		case pgdiff.RoleSchemaType, pgdiff.ExtensionSchemaType:
+			assert.NotContains(t, query, "deptype = 'e'", schemaType)
+		default:
+			assert.Contains(t, query, "deptype = 'e'", schemaType)
+		}
+	}
+}
+
+func TestVersionedQueries(t *testing.T) {
+	assert.Contains(t, publicationSql(150000), "pr.prqual")
+	assert.Contains(t, publicationSql(150000), "pg_publication_namespace")
+	assert.NotContains(t, publicationSql(140000), "pr.prqual")
+	assert.NotContains(t, publicationSql(140000), "pg_publication_namespace")
+	assert.Contains(t, publicationSql(140000), "p.pubviaroot")
+	assert.NotContains(t, publicationSql(120000), "p.pubviaroot")
+
+	assert.Contains(t, subscriptionSql(140000, true), "s.subbinary")
+	assert.Contains(t, subscriptionSql(140000, true), "s.substream")
+	assert.NotContains(t, subscriptionSql(130000, true), "s.subbinary")
+	assert.NotContains(t, subscriptionSql(130000, true), "s.substream")
+	assert.Contains(t, subscriptionSql(140000, true), "s.subconninfo")
+	assert.NotContains(t, subscriptionSql(140000, false), "subconninfo")
+}
+
+func TestCommentQueryStarAggregates(t *testing.T) {
+	f := &SchemaFactory{dbInfo: &pgutil.DbInfo{DbSchema: "*"}}
+	query, err := f.query(pgdiff.CommentSchemaType)
+	if assert.NoError(t, err) {
+		assert.Contains(t, query, "p.prokind = 'a' AND p.pronargs = 0 THEN '*'")
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/facefunk/pgdiff"
+	"github.com/joncrlsn/pgutil"
+)
+
+const (
+	scratchPrefix      = "pgdiff_scratch_"
+	maintenanceDbName  = "postgres"
+	scratchRandomBytes = 8
+)
+
+// ScratchDatabase is a SchemaFactory for a temporary database created to load a desired schema into. Closing it drops
+// the database.
+type ScratchDatabase struct {
+	*SchemaFactory
+	admin *sql.DB
+	name  string
+}
+
+// NewScratchDatabase creates an empty database on the server described by server, connecting to server.DbName, or
+// the postgres database if that is empty, to do so. server.DbSchema selects the schema to compare.
+func NewScratchDatabase(server pgutil.DbInfo) (*ScratchDatabase, error) {
+	if server.DbName == "" {
+		server.DbName = maintenanceDbName
+	}
+	admin, err := server.Open()
+	if err != nil {
+		return nil, pgdiff.NewError("opening maintenance database: " + err.Error())
+	}
+	b := make([]byte, scratchRandomBytes)
+	_, err = rand.Read(b)
+	if err != nil {
+		admin.Close()
+		return nil, pgdiff.NewError("naming scratch database: " + err.Error())
+	}
+	name := scratchPrefix + hex.EncodeToString(b)
+	_, err = admin.Exec("CREATE DATABASE " + name)
+	if err != nil {
+		admin.Close()
+		return nil, pgdiff.NewError("creating scratch database: " + err.Error())
+	}
+
+	info := server
+	info.DbName = name
+	conn, err := info.Open()
+	if err != nil {
+		s := &ScratchDatabase{admin: admin, name: name}
+		s.drop()
+		return nil, pgdiff.NewError("opening scratch database: " + err.Error())
+	}
+	return &ScratchDatabase{
+		SchemaFactory: NewSchemaFactory(conn, &info).(*SchemaFactory),
+		admin:         admin,
+		name:          name,
+	}, nil
+}
+
+// ExecTx runs query in a transaction in the scratch database, so a query that fails leaves nothing behind.
+func (s *ScratchDatabase) ExecTx(query string) error {
+	tx, err := s.conn.Begin()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(query)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Name returns the name of the scratch database.
+func (s *ScratchDatabase) Name() string {
+	return s.name
+}
+
+// Close drops the scratch database, even if closing the connection to it fails.
+func (s *ScratchDatabase) Close() error {
+	cerr := s.conn.Close()
+	err := s.drop()
+	switch {
+	case cerr == nil:
+		return err
+	case err == nil:
+		return pgdiff.NewError(fmt.Sprintf("closing scratch database %s: %s", s.name, cerr))
+	}
+	return pgdiff.NewError(fmt.Sprintf("closing scratch database %s: %s; %s", s.name, cerr, err))
+}
+
+func (s *ScratchDatabase) drop() error {
+	_, err := s.admin.Exec("DROP DATABASE IF EXISTS " + s.name)
+	if err != nil {
+		s.admin.Close()
+		return pgdiff.NewError(fmt.Sprintf("dropping scratch database %s: %s", s.name, err))
+	}
+	return s.admin.Close()
+}
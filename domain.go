@@ -0,0 +1,221 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/joncrlsn/misc"
+)
+
+// domainConstraint is a single CHECK constraint of a domain, as held in the JSON array of a DOMAIN row.
+type domainConstraint struct {
+	Name string `json:"name"`
+	Def  string `json:"def"`
+}
+
+// ==================================
+// DomainRows definition
+// ==================================
+
+// DomainRows is a sortable string map
+type DomainRows []map[string]string
+
+func (slice DomainRows) Len() int {
+	return len(slice)
+}
+
+func (slice DomainRows) Less(i, j int) bool {
+	return slice[i]["compare_name"] < slice[j]["compare_name"]
+}
+
+func (slice DomainRows) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+// ==================================
+// DomainSchema definition
+// (implements Schema -- defined in pgdiff.go)
+// ==================================
+
+// DomainSchema holds a slice of rows from one of the databases as well as
+// a reference to the current row of data we're viewing.
+//
+// If notValid is set, constraints are added to existing domains NOT VALID and then validated in a separate statement.
+type DomainSchema struct {
+	rows     DomainRows
+	rowNum   int
+	done     bool
+	dbSchema string
+	notValid bool
+	other    *DomainSchema
+}
+
+func NewDomainSchema(rows DomainRows, dbSchema string, notValid bool) *DomainSchema {
+	return &DomainSchema{rows: rows, rowNum: -1, dbSchema: dbSchema, notValid: notValid}
+}
+
+// get returns the value from the current row for the given key
+func (c *DomainSchema) get(key string) string {
+	if c.rowNum >= len(c.rows) {
+		return ""
+	}
+	return c.rows[c.rowNum][key]
+}
+
+// constraints returns the current row's constraints in name order. They are held as a JSON array.
+func (c *DomainSchema) constraints() ([]domainConstraint, error) {
+	var cons []domainConstraint
+	err := json.Unmarshal([]byte(c.get("constraints")), &cons)
+	if err != nil {
+		return nil, fmt.Errorf("reading constraints of domain %s: %s", c.Identity(), err)
+	}
+	return cons, nil
+}
+
+// NextRow increments the rowNum and tells you whether or not there are more
+func (c *DomainSchema) NextRow() bool {
+	if c.rowNum >= len(c.rows)-1 {
+		c.done = true
+	}
+	c.rowNum = c.rowNum + 1
+	return !c.done
+}
+
+// Identity returns the qualified name of the current row's object
+func (c *DomainSchema) Identity() string {
+	return c.get("schema_name") + "." + c.get("domain_name")
+}
+
+// Row returns a copy of the current row
+func (c *DomainSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
+// Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
+func (c *DomainSchema) Compare(obj Schema) (int, *Error) {
+	c2, ok := obj.(*DomainSchema)
+	if !ok {
+		return +999, NewError(fmt.Sprint("compare(obj) needs a DomainSchema instance", c2))
+	}
+	c.other = c2
+
+	val := misc.CompareStrings(c.get("compare_name"), c.other.get("compare_name"))
+	return val, nil
+}
+
+// domainName returns the qualified name of the domain in db2
+func (c *DomainSchema) domainName() string {
+	schema := c.other.dbSchema
+	if schema == "*" {
+		schema = c.get("schema_name")
+	}
+	return schema + "." + c.get("domain_name")
+}
+
+// Add returns SQL to create the domain along with its constraints. No column uses the new domain, so there is nothing
+// for notValid to defer.
+func (c *DomainSchema) Add() []Stringer {
+	cons, err := c.constraints()
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	def := fmt.Sprintf("CREATE DOMAIN %s AS %s", c.domainName(), c.get("data_type"))
+	if c.get("domain_default") != "null" {
+		def += " DEFAULT " + c.get("domain_default")
+	}
+	if c.get("is_nullable") == "NO" {
+		def += " NOT NULL"
+	}
+	for _, con := range cons {
+		def += fmt.Sprintf(" CONSTRAINT %s %s", con.Name, con.Def)
+	}
+	return []Stringer{NewLine(def + ";")}
+}
+
+// addConstraint returns SQL to add con to the domain, validating it separately if notValid is set
+func (c *DomainSchema) addConstraint(domainName string, con domainConstraint) []Stringer {
+	if !c.notValid || strings.HasSuffix(con.Def, notValidSuffix) {
+		return []Stringer{NewLine(fmt.Sprintf("ALTER DOMAIN %s ADD CONSTRAINT %s %s;", domainName, con.Name, con.Def))}
+	}
+	return []Stringer{
+		NewLine(fmt.Sprintf("ALTER DOMAIN %s ADD CONSTRAINT %s %s NOT VALID;", domainName, con.Name, con.Def)),
+		NewLine(fmt.Sprintf("ALTER DOMAIN %s VALIDATE CONSTRAINT %s;", domainName, con.Name)),
+	}
+}
+
+// Drop returns SQL to drop the domain
+func (c DomainSchema) Drop() []Stringer {
+	return []Stringer{NewLine(fmt.Sprintf("DROP DOMAIN %s.%s;", c.get("schema_name"), c.get("domain_name")))}
+}
+
+// Change handles the case where the domain names match, but the default, nullability or constraints do not. The base
+// type of a domain cannot be altered, so a warning is given instead.
+func (c *DomainSchema) Change() []Stringer {
+	domainName := c.domainName()
+	var strs []Stringer
+	if c.get("data_type") != c.other.get("data_type") {
+		strs = append(strs, NewWarning(fmt.Sprintf("-- WARNING: domain %s is based on %s in db1 but %s in db2. "+
+			"PostgreSQL cannot change the type of a domain, so it must be dropped and created again.", domainName,
+			c.get("data_type"), c.other.get("data_type"))))
+	}
+	if c.get("domain_default") != c.other.get("domain_default") {
+		if c.get("domain_default") == "null" {
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER DOMAIN %s DROP DEFAULT;", domainName)))
+		} else {
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER DOMAIN %s SET DEFAULT %s;", domainName, c.get("domain_default"))))
+		}
+	}
+	if c.get("is_nullable") != c.other.get("is_nullable") {
+		if c.get("is_nullable") == "NO" {
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER DOMAIN %s SET NOT NULL;", domainName)))
+		} else {
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER DOMAIN %s DROP NOT NULL;", domainName)))
+		}
+	}
+	if c.get("constraints") == c.other.get("constraints") {
+		return strs
+	}
+	cons1, err := c.constraints()
+	if err != nil {
+		return append(strs, NewError(err.Error()))
+	}
+	cons2, err := c.other.constraints()
+	if err != nil {
+		return append(strs, NewError(err.Error()))
+	}
+	defs1 := map[string]string{}
+	for _, con := range cons1 {
+		defs1[con.Name] = con.Def
+	}
+	defs2 := map[string]string{}
+	for _, con := range cons2 {
+		defs2[con.Name] = con.Def
+	}
+	for _, con := range cons2 {
+		def1, ok := defs1[con.Name]
+		if ok && strings.TrimSuffix(def1, notValidSuffix) == strings.TrimSuffix(con.Def, notValidSuffix) {
+			continue
+		}
+		strs = append(strs, NewLine(fmt.Sprintf("ALTER DOMAIN %s DROP CONSTRAINT %s; -- %s", domainName, con.Name, con.Def)))
+	}
+	for _, con := range cons1 {
+		def2, ok := defs2[con.Name]
+		switch {
+		case ok && def2 == con.Def:
+		case ok && strings.TrimSuffix(def2, notValidSuffix) == con.Def:
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER DOMAIN %s VALIDATE CONSTRAINT %s;", domainName, con.Name)))
+		case ok && strings.TrimSuffix(con.Def, notValidSuffix) == def2:
+			// Only db1's constraint is NOT VALID, which leaves nothing to do
+		default:
+			strs = append(strs, c.addConstraint(domainName, con)...)
+		}
+	}
+	return strs
+}
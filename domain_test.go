@@ -0,0 +1,47 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func domainRow(dataType, def, nullable, constraints string) map[string]string {
+	return map[string]string{"compare_name": "s.positive", "schema_name": "s", "domain_name": "positive",
+		"data_type": dataType, "domain_default": def, "is_nullable": nullable, "constraints": constraints}
+}
+
+func TestDomain(t *testing.T) {
+	positive := domainRow("integer", "1", "NO", `[{"name":"positive_check","def":"CHECK ((VALUE > 0))"}]`)
+	assert.Equal(t, []Stringer{
+		NewLine("CREATE DOMAIN s.positive AS integer DEFAULT 1 NOT NULL CONSTRAINT positive_check CHECK ((VALUE > 0));"),
+	}, diffOutput(NewDomainSchema(DomainRows{positive}, "*", false), NewDomainSchema(nil, "*", false)))
+	assert.Equal(t, []Stringer{NewLine("DROP DOMAIN s.positive;")},
+		diffOutput(NewDomainSchema(nil, "*", false),
+			NewDomainSchema(DomainRows{domainRow("integer", "null", "YES", `[]`)}, "*", false)))
+
+	db1 := DomainRows{domainRow("bigint", "null", "NO", `[{"name":"a","def":"CHECK ((VALUE > 0))"},`+
+		`{"name":"b","def":"CHECK ((VALUE < 10))"},{"name":"c","def":"CHECK ((VALUE <> 5))"}]`)}
+	db2 := DomainRows{domainRow("integer", "1", "YES", `[{"name":"a","def":"CHECK ((VALUE > 0)) NOT VALID"},`+
+		`{"name":"b","def":"CHECK ((VALUE < 20))"},{"name":"d","def":"CHECK ((VALUE <> 6))"}]`)}
+	assert.Equal(t, []Stringer{
+		NewWarning("-- WARNING: domain s.positive is based on bigint in db1 but integer in db2. PostgreSQL cannot change " +
+			"the type of a domain, so it must be dropped and created again."),
+		NewLine("ALTER DOMAIN s.positive DROP DEFAULT;"),
+		NewLine("ALTER DOMAIN s.positive SET NOT NULL;"),
+		NewLine("ALTER DOMAIN s.positive DROP CONSTRAINT b; -- CHECK ((VALUE < 20))"),
+		NewLine("ALTER DOMAIN s.positive DROP CONSTRAINT d; -- CHECK ((VALUE <> 6))"),
+		NewLine("ALTER DOMAIN s.positive VALIDATE CONSTRAINT a;"),
+		NewLine("ALTER DOMAIN s.positive ADD CONSTRAINT b CHECK ((VALUE < 10));"),
+		NewLine("ALTER DOMAIN s.positive ADD CONSTRAINT c CHECK ((VALUE <> 5));"),
+	}, diffOutput(NewDomainSchema(db1, "*", false), NewDomainSchema(db2, "*", false)))
+	assert.Equal(t, []Stringer{
+		NewLine("ALTER DOMAIN s.positive ADD CONSTRAINT a CHECK ((VALUE > 0)) NOT VALID;"),
+		NewLine("ALTER DOMAIN s.positive VALIDATE CONSTRAINT a;"),
+	}, diffOutput(NewDomainSchema(DomainRows{
+		domainRow("integer", "null", "YES", `[{"name":"a","def":"CHECK ((VALUE > 0))"}]`),
+	}, "*", true), NewDomainSchema(DomainRows{domainRow("integer", "null", "YES", `[]`)}, "*", false)))
+}
@@ -0,0 +1,738 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package dump
+
+import (
+	"strings"
+)
+
+const defaultNamespace = "public"
+
+// aclOrder is the order in which PostgreSQL prints privilege characters in an aclitem.
+const aclOrder = "arwdDxtXUCTc"
+
+var (
+	// privilegeChars maps privilege keywords to aclitem characters.
+	privilegeChars = map[string]string{
+		"insert":     "a",
+		"select":     "r",
+		"update":     "w",
+		"delete":     "d",
+		"truncate":   "D",
+		"references": "x",
+		"trigger":    "t",
+		"execute":    "X",
+		"usage":      "U",
+		"create":     "C",
+		"temporary":  "T",
+		"temp":       "T",
+		"connect":    "c",
+	}
+
+	// allPrivileges is what ALL PRIVILEGES means for each relkind.
+	allPrivileges = map[byte]string{
+		'r': "arwdDxt",
+		'v': "arwdDxt",
+		'f': "arwdDxt",
+		'S': "rwU",
+	}
+)
+
+type (
+	// catalog is the subset of the system catalogs that can be rebuilt from a schema dump.
+	catalog struct {
+		namespaces []*namespace
+		extensions []*extension
+		relations  []*relation
+		indexes    []*index
+		functions  []*function
+		operators  []*operator
+		opFamilies []*opFamily
+		opClasses  []*opClass
+		triggers   []*trigger
+		rules      []*rule
+		evtTrigs   []*eventTrigger
+		policies   []*policy
+		wrappers   []*foreignDataWrapper
+		servers    []*foreignServer
+		mappings   []*userMapping
+		pubs       []*publication
+		subs       []*subscription
+		comments   []*comment
+		enums      []*enum
+		composites []*composite
+		domains    []*domain
+		types      map[string]bool
+		owners     bool
+	}
+
+	namespace struct {
+		name  string
+		owner string
+	}
+
+	// relation is a pg_class entry: a table, view, materialized view, sequence or foreign table.
+	relation struct {
+		schema           string
+		name             string
+		kind             byte
+		owner            string
+		columns          []*column
+		constraints      []*constraint
+		definition       string
+		seq              *sequence
+		acl              *acl
+		rowSecurity      bool
+		forceRowSecurity bool
+		// partitionKey is set for a partitioned table, as pg_get_partkeydef renders it. partitionCols are the columns
+		// it names.
+		partitionKey  string
+		partitionCols []string
+		// parent is the partitioned table a partition belongs to and bound its bound, as pg_get_expr renders it.
+		parent *relation
+		bound  string
+		// inherits are the tables named by INHERITS.
+		inherits []*relation
+		// server and fdwOptions are set for a foreign table.
+		server     string
+		fdwOptions []string
+	}
+
+	column struct {
+		name      string
+		typ       *typeName
+		notNull   bool
+		def       string
+		identity  string
+		acl       *acl
+		inherited bool
+		// fdwOptions are the options of a foreign table's column.
+		fdwOptions []string
+	}
+
+	sequence struct {
+		typ       string
+		increment string
+		min       string
+		max       string
+		start     string
+		cycle     bool
+		identity  bool
+	}
+
+	// constraint is a pg_constraint entry. def is rendered as pg_get_constraintdef would render it.
+	constraint struct {
+		name  string
+		typ   byte
+		def   string
+		index *index
+	}
+
+	// index is a pg_index entry. The definition is split so the relation name can be rendered as required.
+	index struct {
+		rel     *relation
+		name    string
+		unique  bool
+		primary bool
+		method  string
+		cols    string
+		rest    string
+		con     *constraint
+		// attached is set for the index of a partition that is attached to an index of the partitioned table.
+		attached bool
+	}
+
+	// function is a pg_proc entry. kind is FUNCTION, PROCEDURE, AGGREGATE or WINDOW and aggregate holds the parameters
+	// of an aggregate.
+	function struct {
+		kind       string
+		schema     string
+		name       string
+		args       []*argument
+		returns    string
+		returnType string
+		language   string
+		options    []string
+		config     []string
+		body       string
+		probin     string
+		sqlBody    string
+		aggregate  []*aggParam
+	}
+
+	// aggParam is a parameter of CREATE AGGREGATE. The value of a state type is held in typ, the values of flags such
+	// as HYPOTHETICAL are empty and the rest are rendered as the catalog query renders them.
+	aggParam struct {
+		key   string
+		value string
+		typ   *typeName
+	}
+
+	// argument is a function argument. orderBy is set on the first aggregated argument of an ordered-set aggregate.
+	argument struct {
+		mode    string
+		name    string
+		typ     *typeName
+		def     string
+		orderBy bool
+	}
+
+	// operator is a pg_operator entry. left is NONE for a prefix operator, function, restrict and join are rendered as
+	// regproc would render them and commutator and negator as qualified OPERATOR() names.
+	operator struct {
+		schema     string
+		name       string
+		left       string
+		right      string
+		function   string
+		commutator string
+		negator    string
+		restrict   string
+		join       string
+		hashes     bool
+		merges     bool
+	}
+
+	// opFamily is a pg_opfamily entry. members are those added outside of any class, rendered as the catalog query
+	// renders them.
+	opFamily struct {
+		schema  string
+		name    string
+		method  string
+		members []string
+	}
+
+	// opClass is a pg_opclass entry, its members rendered as the catalog query renders them.
+	opClass struct {
+		schema    string
+		name      string
+		method    string
+		family    *opFamily
+		isDefault bool
+		typ       string
+		storage   string
+		members   []string
+	}
+
+	trigger struct {
+		rel     *relation
+		name    string
+		def     string
+		enabled string
+	}
+
+	// eventTrigger is a pg_event_trigger entry. tags are upper case, as PostgreSQL keeps them, and function is rendered
+	// as regproc would render it.
+	eventTrigger struct {
+		name     string
+		event    string
+		tags     []string
+		function string
+		enabled  string
+	}
+
+	// rule is a pg_rewrite entry other than the _RETURN rule of a view. def is rendered as pg_get_ruledef would render
+	// it, less the final semicolon.
+	rule struct {
+		rel  *relation
+		name string
+		def  string
+	}
+
+	// policy is a pg_policy entry. The expressions are rendered as pg_get_expr would render them.
+	policy struct {
+		rel         *relation
+		name        string
+		command     string
+		restrictive bool
+		roles       []string
+		using       string
+		check       string
+	}
+
+	// foreignDataWrapper is a pg_foreign_data_wrapper entry. handler and validator are rendered as regproc would
+	// render them. Options are held as "name=value" strings, as in the system catalogs.
+	foreignDataWrapper struct {
+		name      string
+		handler   string
+		validator string
+		options   []string
+	}
+
+	// foreignServer is a pg_foreign_server entry.
+	foreignServer struct {
+		name    string
+		fdw     string
+		typ     string
+		version string
+		options []string
+	}
+
+	// userMapping is a pg_user_mapping entry. user is PUBLIC for the mapping of every role.
+	userMapping struct {
+		server  string
+		user    string
+		options []string
+	}
+
+	// publication is a pg_publication entry along with its pg_publication_rel tables and pg_publication_namespace
+	// schemas. publish holds the published operations.
+	publication struct {
+		name      string
+		allTables bool
+		publish   map[string]bool
+		viaRoot   bool
+		tables    []*publishedTable
+		schemas   []string
+	}
+
+	// publishedTable is a pg_publication_rel entry. where is the row filter, rendered as pg_get_expr would render it.
+	publishedTable struct {
+		rel   *relation
+		where string
+	}
+
+	// subscription is a pg_subscription entry. slotName is empty for NONE.
+	subscription struct {
+		name         string
+		conninfo     string
+		publications []string
+		enabled      bool
+		slotName     string
+		binary       bool
+		streaming    string
+		syncCommit   string
+	}
+
+	// comment is a pg_description entry, identified as in the COMMENT ON statement that made it. table is only set
+	// for columns and constraints.
+	comment struct {
+		typ    string
+		schema string
+		table  string
+		name   string
+		text   string
+	}
+
+	// extension is a pg_extension entry. version is empty unless the dump names it.
+	extension struct {
+		name    string
+		schema  string
+		version string
+	}
+
+	// enum is a pg_type entry of an enum along with its pg_enum labels in sort order.
+	enum struct {
+		schema string
+		name   string
+		labels []string
+	}
+
+	// composite is a pg_type entry of a composite type created by CREATE TYPE, along with its attributes.
+	composite struct {
+		schema string
+		name   string
+		attrs  []*column
+	}
+
+	// domain is a pg_type entry of a domain along with its CHECK constraints.
+	domain struct {
+		schema      string
+		name        string
+		typ         *typeName
+		def         string
+		notNull     bool
+		constraints []*constraint
+	}
+
+	// acl is an aclitem[] in the order items were granted.
+	acl struct {
+		items []*aclItem
+	}
+
+	aclItem struct {
+		grantee string
+		privs   string
+		grantor string
+	}
+
+	// typeName is a parsed type reference.
+	typeName struct {
+		schema string
+		name   string
+		mods   []string
+		dims   int
+		serial bool
+	}
+)
+
+func newCatalog() *catalog {
+	return &catalog{
+		namespaces: []*namespace{{name: defaultNamespace, owner: "pg_database_owner"}},
+		types:      map[string]bool{},
+	}
+}
+
+func (c *catalog) namespace(name string) *namespace {
+	for _, n := range c.namespaces {
+		if n.name == name {
+			return n
+		}
+	}
+	return nil
+}
+
+func (c *catalog) relation(schema, name string) *relation {
+	for _, r := range c.relations {
+		if r.schema == schema && r.name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+// partitionedBy tells you whether the column named name is in the partition key of r.
+func (r *relation) partitionedBy(name string) bool {
+	for _, col := range r.partitionCols {
+		if col == name {
+			return true
+		}
+	}
+	return false
+}
+
+// publication returns the publication named name, or nil if there is none.
+func (c *catalog) publication(name string) *publication {
+	for _, pub := range c.pubs {
+		if pub.name == name {
+			return pub
+		}
+	}
+	return nil
+}
+
+func (c *catalog) opFamily(schema, name, method string) *opFamily {
+	for _, f := range c.opFamilies {
+		if f.schema == schema && f.name == name && f.method == method {
+			return f
+		}
+	}
+	return nil
+}
+
+func (c *catalog) enum(schema, name string) *enum {
+	for _, e := range c.enums {
+		if e.schema == schema && e.name == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// domain returns the domain with the schema qualified name, or nil if there is none.
+func (c *catalog) domain(name string) *domain {
+	for _, d := range c.domains {
+		if d.schema+"."+d.name == name {
+			return d
+		}
+	}
+	return nil
+}
+
+// index returns the position of label, or -1 if the enum does not have it.
+func (e *enum) index(label string) int {
+	for i, l := range e.labels {
+		if l == label {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c *catalog) addRelation(r *relation) *relation {
+	if old := c.relation(r.schema, r.name); old != nil {
+		*old = *r
+		return old
+	}
+	c.relations = append(c.relations, r)
+	return r
+}
+
+func (c *catalog) index(schema, name string) *index {
+	for _, i := range c.indexes {
+		if i.rel.schema == schema && i.name == name {
+			return i
+		}
+	}
+	return nil
+}
+
+func (c *catalog) addIndex(i *index) {
+	if old := c.index(i.rel.schema, i.name); old != nil {
+		*old = *i
+		return
+	}
+	c.indexes = append(c.indexes, i)
+}
+
+func (r *relation) column(name string) *column {
+	return columnNamed(r.columns, name)
+}
+
+func columnNamed(cols []*column, name string) *column {
+	for _, col := range cols {
+		if col.name == name {
+			return col
+		}
+	}
+	return nil
+}
+
+// qualifiedName renders the relation name as pg_dump would, with the schema always present.
+func (r *relation) qualifiedName() string {
+	return quoteIdent(r.schema) + "." + quoteIdent(r.name)
+}
+
+// prettyName renders the relation name as the pretty catalog functions would under the default search_path.
+func (r *relation) prettyName() string {
+	if r.schema == defaultNamespace {
+		return quoteIdent(r.name)
+	}
+	return r.qualifiedName()
+}
+
+// def renders the index as pg_get_indexdef would. The pretty rendering omits the public schema and the parentheses
+// around a partial index predicate.
+func (i *index) def(pretty bool) string {
+	unique := ""
+	if i.unique {
+		unique = "UNIQUE "
+	}
+	name, cols, rest := i.rel.qualifiedName(), i.cols, i.rest
+	if pretty {
+		name, cols, rest = i.rel.prettyName(), unqualify(cols), unqualify(rest)
+		if k := strings.Index(rest, " WHERE ("); k >= 0 && enclosed(rest[k+7:]) {
+			rest = rest[:k] + " WHERE " + rest[k+8:len(rest)-1]
+		}
+	}
+	return "CREATE " + unique + "INDEX " + quoteIdent(i.name) + " ON " + name + " USING " + i.method + " (" + cols + ")" + rest
+}
+
+// enclosed reports whether s is entirely wrapped in a single pair of parentheses.
+func enclosed(s string) bool {
+	if !strings.HasPrefix(s, "(") || !strings.HasSuffix(s, ")") {
+		return false
+	}
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 && i < len(s)-1 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// grant adds privs for grantee.
+func (a *acl) grant(grantee, privs, grantor string) {
+	for _, item := range a.items {
+		if item.grantee == grantee {
+			item.privs = mergePrivileges(item.privs, privs)
+			return
+		}
+	}
+	a.items = append(a.items, &aclItem{grantee, mergePrivileges("", privs), grantor})
+}
+
+// revoke removes privs from grantee.
+func (a *acl) revoke(grantee, privs string) {
+	for i, item := range a.items {
+		if item.grantee != grantee {
+			continue
+		}
+		var kept string
+		for j := 0; j < len(item.privs); j++ {
+			p := item.privs[j]
+			if p == '*' {
+				continue
+			}
+			if !strings.ContainsRune(privs, rune(p)) {
+				kept += string(p)
+				if j+1 < len(item.privs) && item.privs[j+1] == '*' {
+					kept += "*"
+				}
+			}
+		}
+		if kept == "" {
+			a.items = append(a.items[:i], a.items[i+1:]...)
+		} else {
+			item.privs = kept
+		}
+		return
+	}
+}
+
+// strings renders each aclitem as PostgreSQL prints them.
+func (a *acl) strings() []string {
+	strs := make([]string, len(a.items))
+	for i, item := range a.items {
+		strs[i] = quoteRole(item.grantee) + "=" + item.privs + "/" + quoteRole(item.grantor)
+	}
+	return strs
+}
+
+// mergePrivileges combines two privilege strings, each character optionally followed by '*' for grant option, in
+// aclitem order.
+func mergePrivileges(a, b string) string {
+	has := map[byte]bool{}
+	opt := map[byte]bool{}
+	for _, s := range []string{a, b} {
+		for i := 0; i < len(s); i++ {
+			if s[i] == '*' {
+				continue
+			}
+			has[s[i]] = true
+			if i+1 < len(s) && s[i+1] == '*' {
+				opt[s[i]] = true
+			}
+		}
+	}
+	var out string
+	for i := 0; i < len(aclOrder); i++ {
+		c := aclOrder[i]
+		if has[c] {
+			out += string(c)
+			if opt[c] {
+				out += "*"
+			}
+		}
+	}
+	return out
+}
+
+// quoteRole renders a role name in an aclitem, where PUBLIC is an empty string.
+func quoteRole(role string) string {
+	if role == "public" {
+		return ""
+	}
+	return role
+}
+
+// quoteIdent quotes an identifier only if PostgreSQL would.
+func quoteIdent(name string) string {
+	if name == "" {
+		return `""`
+	}
+	safe := name[0] == '_' || (name[0] >= 'a' && name[0] <= 'z')
+	for i := 0; safe && i < len(name); i++ {
+		c := name[i]
+		safe = c == '_' || c == '$' || isDigit(c) || (c >= 'a' && c <= 'z')
+	}
+	if safe && !reservedWords[name] {
+		return name
+	}
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+// reservedWords are the keywords that quote_identifier always quotes.
+var reservedWords = map[string]bool{
+	"all": true, "analyse": true, "analyze": true, "and": true, "any": true, "array": true, "as": true, "asc": true,
+	"asymmetric": true, "both": true, "case": true, "cast": true, "check": true, "collate": true, "column": true,
+	"constraint": true, "create": true, "current_catalog": true, "current_date": true, "current_role": true,
+	"current_time": true, "current_timestamp": true, "current_user": true, "default": true, "deferrable": true,
+	"desc": true, "distinct": true, "do": true, "else": true, "end": true, "except": true, "false": true, "fetch": true,
+	"for": true, "foreign": true, "from": true, "grant": true, "group": true, "having": true, "in": true,
+	"initially": true, "intersect": true, "into": true, "lateral": true, "leading": true, "limit": true,
+	"localtime": true, "localtimestamp": true, "not": true, "null": true, "offset": true, "on": true, "only": true,
+	"or": true, "order": true, "placing": true, "primary": true, "references": true, "returning": true,
+	"select": true, "session_user": true, "some": true, "symmetric": true, "table": true, "then": true, "to": true,
+	"trailing": true, "true": true, "union": true, "unique": true, "user": true, "using": true, "variadic": true,
+	"when": true, "where": true, "window": true, "with": true,
+}
+
+// unqualify removes the public schema from names in text rendered with an empty search_path, as pg_dump renders
+// everything, so that it matches text rendered over a connection using the default search_path. Names inside
+// literals are only unqualified when the literal is cast to a reg* type, eg. nextval('public.seq'::regclass).
+func unqualify(s string) string {
+	const prefix = defaultNamespace + "."
+	var b strings.Builder
+	n := len(s)
+	for i := 0; i < n; {
+		c := s[i]
+		switch {
+		case c == '\'':
+			j := i + 1
+			for j < n {
+				if s[j] == '\'' {
+					if j+1 < n && s[j+1] == '\'' {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			if j < n {
+				j++
+			}
+			lit := s[i:j]
+			if strings.HasPrefix(lit, "'"+prefix) && strings.HasPrefix(s[j:], "::reg") {
+				lit = "'" + lit[len(prefix)+1:]
+			}
+			b.WriteString(lit)
+			i = j
+		case c == '"':
+			j := i + 1
+			for j < n && s[j] != '"' {
+				j++
+			}
+			if j < n {
+				j++
+			}
+			if s[i:j] == `"`+defaultNamespace+`"` && j < n && s[j] == '.' {
+				i = j + 1
+				continue
+			}
+			b.WriteString(s[i:j])
+			i = j
+		case strings.HasPrefix(s[i:], prefix) && (i == 0 || !(isIdentChar(s[i-1]) || s[i-1] == '.')):
+			i += len(prefix)
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// squash replaces each run of whitespace outside literals and quoted identifiers with a single space.
+func squash(s string) string {
+	var b strings.Builder
+	var quote byte
+	space := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote == 0 && (c == ' ' || c == '\t' || c == '\n' || c == '\r') {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		switch {
+		case quote == 0 && (c == '\'' || c == '"'):
+			quote = c
+		case quote == c:
+			quote = 0
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package dump
+
+import (
+	"github.com/facefunk/pgdiff"
+	flag "github.com/ogier/pflag"
+)
+
+type (
+	// Module is a pgdiff.Module that produces SchemaFactory instances from plain SQL schema dump files.
+	Module struct {
+		vals1 configVals
+		vals2 configVals
+		conf1 Config
+		conf2 Config
+	}
+	Config struct {
+		File     string
+		DbSchema string
+	}
+	configVals struct {
+		File string
+	}
+)
+
+func (m *Module) Name() string {
+	return "Dump file source"
+}
+
+func (m *Module) RegisterFlags(flagSet *flag.FlagSet) {
+	flagSet.StringVar(&m.vals1.File, "dump1", "", "first schema dump file (eg. from pg_dump --schema-only)")
+	flagSet.StringVar(&m.vals2.File, "dump2", "", "second schema dump file (eg. from pg_dump --schema-only)")
+}
+
+func (m *Module) ConfigureFromFlags() {
+	setConf(&m.conf1, &m.vals1)
+	setConf(&m.conf2, &m.vals2)
+}
+
+func setConf(conf *Config, vals *configVals) {
+	conf.File = vals.File
+}
+
+func (m *Module) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	conf := struct {
+		Dump1 *configVals
+		Dump2 *configVals
+	}{
+		&configVals{},
+		&configVals{},
+	}
+	err := unmarshal(&conf)
+	if err != nil {
+		return err
+	}
+	setConf(&m.conf1, conf.Dump1)
+	setConf(&m.conf2, conf.Dump2)
+	return nil
+}
+
+func (m *Module) Config(i int) pgdiff.Config {
+	switch i {
+	case 1:
+		return &m.conf1
+	case 2:
+		return &m.conf2
+	default:
+		panic("there are only 2 possible configs.")
+	}
+}
+
+func (m *Module) Factory(conf pgdiff.Config) (pgdiff.SchemaFactory, error) {
+	c, ok := conf.(*Config)
+	if !ok {
+		return nil, pgdiff.NewError("Factory requires dump.Config instance")
+	}
+	source, err := OpenSource(c.File, c.DbSchema)
+	if err != nil {
+		return nil, err
+	}
+	return pgdiff.NewRowSchemaFactory(source, c.DbSchema), nil
+}
+
+func (c *Config) SetSourceConfig(conf *pgdiff.SourceConfig) {
+	c.DbSchema = conf.Schema
+}
+
+func (c *Config) Valid() bool {
+	return c.File != "" && c.DbSchema != ""
+}
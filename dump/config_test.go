@@ -0,0 +1,35 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package dump
+
+import (
+	"testing"
+
+	"github.com/facefunk/pgdiff"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	_ pgdiff.Module               = (*Module)(nil)
+	_ pgdiff.Config               = (*Config)(nil)
+	_ pgdiff.RowSource            = (*Source)(nil)
+	_ pgdiff.PartialSchemaFactory = (*Source)(nil)
+)
+
+func TestYAML(t *testing.T) {
+	in := `
+test:
+  file: ignored.sql
+dump1:
+  file: schema.sql
+`
+	out := Module{}
+	err := yaml.Unmarshal([]byte(in), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "schema.sql", out.conf1.File)
+	assert.Equal(t, "", out.conf2.File)
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+//go:build go1.16
+// +build go1.16
+
+package dump
+
+import (
+	"io/fs"
+	"strings"
+
+	"github.com/facefunk/pgdiff"
+)
+
+// ReadFS parses every .sql file in fsys as a single dump, eg. schema files embedded with embed.FS. Files are read in
+// lexical order, though statements that alter a relation are read once the relation has been created, whatever the
+// order. Down migrations, named *.down.sql, are ignored.
+func ReadFS(fsys fs.FS, dbSchema string) (*Source, error) {
+	var sql []byte
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".sql") || strings.HasSuffix(path, ".down.sql") {
+			return nil
+		}
+		b, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		// Terminate any trailing comment and statement so files cannot run into each other.
+		sql = append(append(sql, b...), "\n;\n"...)
+		return nil
+	})
+	if err != nil {
+		return nil, pgdiff.NewError("reading schema files: " + err.Error())
+	}
+	return NewSource("embedded schema", sql, dbSchema)
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+//go:build go1.16
+// +build go1.16
+
+package dump
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/facefunk/pgdiff"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"indexes/users_email.sql": {Data: []byte("CREATE INDEX users_email ON users (email)")},
+		"tables/users.sql":        {Data: []byte("CREATE TABLE users (id serial PRIMARY KEY, email text) -- no semicolon")},
+		"1_users.down.sql":        {Data: []byte("CREATE TABLE dropped (id integer);")},
+	}
+	s, err := ReadFS(fsys, "public")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := s.Rows(pgdiff.TableSchemaType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, r, 1)
+	r, err = s.Rows(pgdiff.IndexSchemaType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, r, 2)
+	assert.Equal(t, "CREATE INDEX users_email ON users USING btree (email)", r[1]["index_def"])
+}
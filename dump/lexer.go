@@ -0,0 +1,261 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package dump
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	wordToken = iota
+	identToken
+	stringToken
+	numberToken
+	punctToken
+)
+
+type (
+	// token is a single lexical element of a SQL script. pos and end index the script it was read from.
+	token struct {
+		kind int
+		val  string
+		pos  int
+		end  int
+	}
+
+	// statement is a single SQL statement as a slice of tokens along with the script they index.
+	statement struct {
+		src  string
+		toks []token
+	}
+)
+
+// text returns the original text spanning toks[i] to toks[j-1].
+func (s *statement) text(i, j int) string {
+	if i >= j || i >= len(s.toks) {
+		return ""
+	}
+	return s.src[s.toks[i].pos:s.toks[j-1].end]
+}
+
+// splitStatements tokenizes a SQL script and splits it into statements. Comments and psql meta-commands are discarded.
+// Semicolons inside parentheses and BEGIN ATOMIC function bodies do not end a statement.
+func splitStatements(src string) []*statement {
+	toks := tokenize(src)
+	var stmts []*statement
+	start, depth, atomic := 0, 0, 0
+	for i, t := range toks {
+		switch {
+		case t.kind == punctToken && t.val == "(":
+			depth++
+		case t.kind == punctToken && t.val == ")":
+			depth--
+		case t.kind == wordToken && t.val == "atomic" && i > 0 && toks[i-1].kind == wordToken && toks[i-1].val == "begin":
+			atomic++
+		case atomic > 0 && t.kind == wordToken && t.val == "case":
+			atomic++
+		case atomic > 0 && t.kind == wordToken && t.val == "end":
+			atomic--
+		case t.kind == punctToken && t.val == ";" && depth <= 0 && atomic == 0:
+			if i > start {
+				stmts = append(stmts, &statement{src: src, toks: toks[start:i]})
+			}
+			start, depth = i+1, 0
+		}
+	}
+	if start < len(toks) {
+		stmts = append(stmts, &statement{src: src, toks: toks[start:]})
+	}
+	return stmts
+}
+
+// tokenize reads every token from src.
+func tokenize(src string) []token {
+	var toks []token
+	n := len(src)
+	i := 0
+	lineStart := true
+	for i < n {
+		c := src[i]
+		switch {
+		case c == '\n':
+			lineStart = true
+			i++
+			continue
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			i++
+			continue
+		case c == '\\' && lineStart:
+			// psql meta-command, eg. \connect or \restrict
+			for i < n && src[i] != '\n' {
+				i++
+			}
+			continue
+		}
+		lineStart = false
+		start := i
+		switch {
+		case c == '-' && i+1 < n && src[i+1] == '-':
+			for i < n && src[i] != '\n' {
+				i++
+			}
+			continue
+		case c == '/' && i+1 < n && src[i+1] == '*':
+			depth := 0
+			for i < n {
+				if src[i] == '/' && i+1 < n && src[i+1] == '*' {
+					depth++
+					i += 2
+				} else if src[i] == '*' && i+1 < n && src[i+1] == '/' {
+					depth--
+					i += 2
+					if depth == 0 {
+						break
+					}
+				} else {
+					i++
+				}
+			}
+			continue
+		case c == '\'':
+			val, end := readString(src, i, false)
+			toks = append(toks, token{stringToken, val, start, end})
+			i = end
+		case (c == 'e' || c == 'E') && i+1 < n && src[i+1] == '\'':
+			val, end := readString(src, i+1, true)
+			toks = append(toks, token{stringToken, val, start, end})
+			i = end
+		case c == '"':
+			var b strings.Builder
+			i++
+			for i < n {
+				if src[i] == '"' {
+					if i+1 < n && src[i+1] == '"' {
+						b.WriteByte('"')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				b.WriteByte(src[i])
+				i++
+			}
+			toks = append(toks, token{identToken, b.String(), start, i})
+		case c == '$' && i+1 < n && !isDigit(src[i+1]):
+			j := i + 1
+			for j < n && src[j] != '$' && isIdentChar(src[j]) {
+				j++
+			}
+			if j < n && src[j] == '$' {
+				tag := src[i : j+1]
+				k := strings.Index(src[j+1:], tag)
+				end := n
+				val := src[j+1:]
+				if k >= 0 {
+					val = src[j+1 : j+1+k]
+					end = j + 1 + k + len(tag)
+				}
+				toks = append(toks, token{stringToken, val, start, end})
+				i = end
+			} else {
+				toks = append(toks, token{punctToken, "$", start, i + 1})
+				i++
+			}
+		case isDigit(c) || (c == '.' && i+1 < n && isDigit(src[i+1])):
+			for i < n && (isDigit(src[i]) || src[i] == '.' || src[i] == 'e' || src[i] == 'E' ||
+				((src[i] == '-' || src[i] == '+') && (src[i-1] == 'e' || src[i-1] == 'E'))) {
+				if src[i] == '.' && i+1 < n && src[i+1] == '.' {
+					break
+				}
+				i++
+			}
+			toks = append(toks, token{numberToken, src[start:i], start, i})
+		case isIdentStart(src, i):
+			for i < n && (isIdentChar(src[i]) || src[i] >= 0x80) {
+				i++
+			}
+			toks = append(toks, token{wordToken, strings.ToLower(src[start:i]), start, i})
+		case c == ':' && i+1 < n && src[i+1] == ':':
+			toks = append(toks, token{punctToken, "::", start, i + 2})
+			i += 2
+		case strings.IndexByte("(),;.[]", c) >= 0:
+			toks = append(toks, token{punctToken, string(c), start, i + 1})
+			i++
+		default:
+			for i < n && strings.IndexByte("+-*/<>=~!@#%^&|`?:", src[i]) >= 0 {
+				if i > start && ((src[i] == '-' && i+1 < n && src[i+1] == '-') || (src[i] == '/' && i+1 < n && src[i+1] == '*')) {
+					break
+				}
+				i++
+			}
+			if i == start {
+				i++
+			}
+			toks = append(toks, token{punctToken, src[start:i], start, i})
+		}
+	}
+	return toks
+}
+
+// readString reads a quoted string literal starting at the quote at src[i], returning its value and end position.
+func readString(src string, i int, escapes bool) (string, int) {
+	var b strings.Builder
+	n := len(src)
+	i++
+	for i < n {
+		c := src[i]
+		if c == '\'' {
+			if i+1 < n && src[i+1] == '\'' {
+				b.WriteByte('\'')
+				i += 2
+				continue
+			}
+			return b.String(), i + 1
+		}
+		if escapes && c == '\\' && i+1 < n {
+			i++
+			switch src[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			default:
+				b.WriteByte(src[i])
+			}
+			i++
+			continue
+		}
+		b.WriteByte(c)
+		i++
+	}
+	return b.String(), n
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentStart(src string, i int) bool {
+	c := src[i]
+	if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+		return true
+	}
+	if c >= 0x80 {
+		end := i + 4
+		if end > len(src) {
+			end = len(src)
+		}
+		r := []rune(src[i:end])
+		return len(r) > 0 && unicode.IsLetter(r[0])
+	}
+	return false
+}
@@ -0,0 +1,2733 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package dump
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/facefunk/pgdiff"
+)
+
+// functionOptionWords start the options that may follow a function's argument list.
+var functionOptionWords = []string{
+	"language", "transform", "window", "immutable", "stable", "volatile", "called", "returns", "strict", "external",
+	"security", "leakproof", "not", "parallel", "cost", "rows", "support", "set", "as", "return", "begin",
+}
+
+// parser reads a single statement into a catalog.
+type parser struct {
+	*statement
+	i   int
+	cat *catalog
+}
+
+// errUnknownRelation defers a statement that refers to a relation that has not been created yet.
+var errUnknownRelation = errors.New("unknown relation")
+
+// parse reads every statement in src that describes a schema object into a new catalog. Statements that do not
+// describe schema objects are ignored, as are statements that alter relations that are never created. Statements that
+// alter a relation created later in src are read once it has been created, so hand-written files need not be ordered.
+func parse(src string) (*catalog, error) {
+	c := newCatalog()
+	pending := splitStatements(src)
+	for len(pending) > 0 {
+		var deferred []*statement
+		for _, stmt := range pending {
+			p := &parser{statement: stmt, cat: c}
+			err := p.parse()
+			if err == errUnknownRelation {
+				deferred = append(deferred, stmt)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(deferred) == len(pending) {
+			break
+		}
+		pending = deferred
+	}
+	return c, nil
+}
+
+func (p *parser) parse() error {
+	switch {
+	case p.word("create"):
+		p.word("or", "replace")
+		start := p.i
+		switch {
+		case p.word("schema"):
+			return p.createSchema()
+		case p.word("extension"):
+			return p.createExtension()
+		case p.word("table"):
+			return p.createTable()
+		case p.isAnyWord("global", "local", "temp", "temporary", "unlogged"):
+			for p.isAnyWord("global", "local", "temp", "temporary", "unlogged") {
+				p.next()
+			}
+			switch {
+			case p.word("table"):
+				return p.createTable()
+			case p.word("sequence"):
+				return p.createSequence()
+			case p.word("view"):
+				return p.createView('v')
+			}
+		case p.word("sequence"):
+			return p.createSequence()
+		case p.word("unique"), p.isWord("index"):
+			p.i = start
+			return p.createIndex()
+		case p.word("view"), p.word("recursive", "view"):
+			return p.createView('v')
+		case p.word("materialized", "view"):
+			return p.createView('m')
+		case p.word("operator", "family"):
+			return p.createOperatorFamily()
+		case p.word("operator", "class"):
+			return p.createOperatorClass()
+		case p.word("operator"):
+			return p.createOperator()
+		case p.word("function"):
+			return p.createFunction("FUNCTION")
+		case p.word("procedure"):
+			return p.createFunction("PROCEDURE")
+		case p.word("aggregate"):
+			return p.createAggregate()
+		case p.word("trigger"), p.word("constraint", "trigger"):
+			return p.createTrigger(start)
+		case p.word("event", "trigger"):
+			return p.createEventTrigger()
+		case p.word("rule"):
+			return p.createRule(start)
+		case p.word("policy"):
+			return p.createPolicy()
+		case p.word("foreign", "data", "wrapper"):
+			return p.createForeignDataWrapper()
+		case p.word("server"):
+			return p.createServer()
+		case p.word("user", "mapping"):
+			return p.createUserMapping()
+		case p.word("foreign", "table"):
+			return p.createForeignTable()
+		case p.word("publication"):
+			return p.createPublication()
+		case p.word("subscription"):
+			return p.createSubscription()
+		case p.word("type"):
+			return p.createType()
+		case p.word("domain"):
+			return p.createDomain()
+		}
+	case p.word("alter"):
+		switch {
+		case p.word("table"), p.word("sequence"), p.word("view"), p.word("materialized", "view"),
+			p.word("foreign", "table"):
+			return p.alterRelation()
+		case p.word("schema"):
+			return p.alterSchema()
+		case p.word("type"):
+			return p.alterType()
+		case p.word("domain"):
+			return p.alterDomain()
+		case p.word("index"):
+			return p.alterIndex()
+		case p.word("publication"):
+			return p.alterPublication()
+		case p.word("event", "trigger"):
+			return p.alterEventTrigger()
+		case p.word("operator", "family"):
+			return p.alterOperatorFamily()
+		}
+	case p.word("comment", "on"):
+		return p.comment()
+	case p.word("grant"):
+		return p.grant(false)
+	case p.word("revoke"):
+		return p.grant(true)
+	}
+	return nil
+}
+
+// ==================================
+// Token helpers
+// ==================================
+
+func (p *parser) more() bool {
+	return p.i < len(p.toks)
+}
+
+func (p *parser) peek() token {
+	if !p.more() {
+		return token{kind: -1}
+	}
+	return p.toks[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	p.i++
+	return t
+}
+
+func (p *parser) isWord(w string) bool {
+	t := p.peek()
+	return t.kind == wordToken && t.val == w
+}
+
+func (p *parser) isAnyWord(ws ...string) bool {
+	for _, w := range ws {
+		if p.isWord(w) {
+			return true
+		}
+	}
+	return false
+}
+
+// word consumes the sequence of words ws if the statement continues with all of them.
+func (p *parser) word(ws ...string) bool {
+	for k, w := range ws {
+		if p.i+k >= len(p.toks) {
+			return false
+		}
+		t := p.toks[p.i+k]
+		if t.kind != wordToken || t.val != w {
+			return false
+		}
+	}
+	p.i += len(ws)
+	return true
+}
+
+func (p *parser) isPunct(v string) bool {
+	t := p.peek()
+	return t.kind == punctToken && t.val == v
+}
+
+func (p *parser) punct(v string) bool {
+	if p.isPunct(v) {
+		p.i++
+		return true
+	}
+	return false
+}
+
+// nameToken reads an identifier, reporting whether it was quoted.
+func (p *parser) nameToken() (string, bool, error) {
+	t := p.peek()
+	switch t.kind {
+	case wordToken:
+		p.i++
+		return t.val, false, nil
+	case identToken:
+		p.i++
+		return t.val, true, nil
+	}
+	return "", false, p.errorf("expected a name")
+}
+
+func (p *parser) name() (string, error) {
+	n, _, err := p.nameToken()
+	return n, err
+}
+
+// qualifiedName reads a possibly schema qualified name, defaulting to the public schema.
+func (p *parser) qualifiedName() (string, string, error) {
+	n, err := p.name()
+	if err != nil {
+		return "", "", err
+	}
+	if !p.punct(".") {
+		return defaultNamespace, n, nil
+	}
+	n2, err := p.name()
+	return n, n2, err
+}
+
+// number reads a possibly signed number.
+func (p *parser) number() string {
+	sign := ""
+	if p.isPunct("-") || p.isPunct("+") {
+		sign = p.next().val
+		if sign == "+" {
+			sign = ""
+		}
+	}
+	return sign + p.next().val
+}
+
+// parens consumes a parenthesised list and returns the token range inside the parentheses.
+func (p *parser) parens() (int, int) {
+	if !p.punct("(") {
+		return p.i, p.i
+	}
+	i := p.i
+	depth := 1
+	for p.more() {
+		t := p.next()
+		if t.kind == punctToken {
+			switch t.val {
+			case "(":
+				depth++
+			case ")":
+				depth--
+				if depth == 0 {
+					return i, p.i - 1
+				}
+			}
+		}
+	}
+	return i, p.i
+}
+
+// until consumes at least one token, then every following token until stop returns true outside parentheses.
+func (p *parser) until(stop func() bool) (int, int) {
+	i := p.i
+	depth := 0
+	for p.more() {
+		if depth == 0 && p.i > i && stop() {
+			break
+		}
+		t := p.next()
+		if t.kind == punctToken {
+			switch t.val {
+			case "(", "[":
+				depth++
+			case ")", "]":
+				depth--
+			}
+		}
+	}
+	return i, p.i
+}
+
+// rest consumes and returns the range of every remaining token.
+func (p *parser) rest() (int, int) {
+	i := p.i
+	p.i = len(p.toks)
+	return i, p.i
+}
+
+// sub returns a parser for the token range i to j.
+func (p *parser) sub(i, j int) *parser {
+	return &parser{statement: &statement{src: p.src, toks: p.toks[i:j]}, cat: p.cat}
+}
+
+// names reads a parenthesised list of identifiers.
+func (p *parser) names() ([]string, error) {
+	i, j := p.parens()
+	var names []string
+	for _, r := range splitList(p.statement, i, j) {
+		n, err := p.sub(r[0], r[1]).name()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, n)
+	}
+	return names, nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	stmt := p.text(0, len(p.toks))
+	if len(stmt) > 60 {
+		stmt = stmt[:60] + "..."
+	}
+	return pgdiff.NewError(fmt.Sprintf("parsing %q: ", stmt) + fmt.Sprintf(format, args...))
+}
+
+// splitList splits the token range i to j at each comma outside parentheses.
+func splitList(s *statement, i, j int) [][2]int {
+	var out [][2]int
+	depth := 0
+	start := i
+	for k := i; k < j; k++ {
+		t := s.toks[k]
+		if t.kind != punctToken {
+			continue
+		}
+		switch t.val {
+		case "(", "[":
+			depth++
+		case ")", "]":
+			depth--
+		case ",":
+			if depth == 0 {
+				out = append(out, [2]int{start, k})
+				start = k + 1
+			}
+		}
+	}
+	if start < j {
+		out = append(out, [2]int{start, j})
+	}
+	return out
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func quoteIdents(names []string) string {
+	q := make([]string, len(names))
+	for i, n := range names {
+		q[i] = quoteIdent(n)
+	}
+	return strings.Join(q, ", ")
+}
+
+// ==================================
+// Schemas
+// ==================================
+
+func (p *parser) createSchema() error {
+	p.word("if", "not", "exists")
+	ns := &namespace{}
+	if !p.isWord("authorization") {
+		n, err := p.name()
+		if err != nil {
+			return err
+		}
+		ns.name = n
+	}
+	if p.word("authorization") {
+		owner, err := p.name()
+		if err != nil {
+			return err
+		}
+		ns.owner = owner
+		if ns.name == "" {
+			ns.name = owner
+		}
+	}
+	if old := p.cat.namespace(ns.name); old != nil {
+		old.owner = ns.owner
+		return nil
+	}
+	p.cat.namespaces = append(p.cat.namespaces, ns)
+	return nil
+}
+
+func (p *parser) alterSchema() error {
+	n, err := p.name()
+	if err != nil {
+		return err
+	}
+	ns := p.cat.namespace(n)
+	if ns == nil || !p.word("owner", "to") {
+		return nil
+	}
+	ns.owner, err = p.name()
+	return err
+}
+
+// ==================================
+// Tables
+// ==================================
+
+func (p *parser) createExtension() error {
+	p.word("if", "not", "exists")
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	x := &extension{name: name, schema: defaultNamespace}
+	p.word("with")
+	for p.more() {
+		switch {
+		case p.word("schema"):
+			x.schema, err = p.name()
+			if err != nil {
+				return err
+			}
+		case p.word("version"):
+			x.version = p.next().val
+		default:
+			p.next()
+		}
+	}
+	p.cat.extensions = append(p.cat.extensions, x)
+	return nil
+}
+
+func (p *parser) createTable() error {
+	p.word("if", "not", "exists")
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	rel := &relation{schema: schema, name: name, kind: 'r'}
+	if p.word("partition", "of") {
+		pSchema, pName, err := p.qualifiedName()
+		if err != nil {
+			return err
+		}
+		rel.parent = p.cat.relation(pSchema, pName)
+		if rel.parent == nil {
+			return errUnknownRelation
+		}
+		for _, col := range rel.parent.columns {
+			c := *col
+			c.acl = nil
+			rel.columns = append(rel.columns, &c)
+		}
+	} else if p.word("of") {
+		_, err = p.typeName()
+		if err != nil {
+			return err
+		}
+	}
+	p.cat.addRelation(rel)
+	if p.isPunct("(") {
+		i, j := p.parens()
+		for _, r := range splitList(p.statement, i, j) {
+			sub := p.sub(r[0], r[1])
+			err = sub.tableElement(rel)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if p.word("inherits") {
+		if err = p.inherits(rel); err != nil {
+			return err
+		}
+	}
+	if rel.parent != nil {
+		i, j := p.until(func() bool { return p.isWord("partition") })
+		rel.bound = unqualify(squash(p.text(i, j)))
+	}
+	if p.word("partition", "by") {
+		return p.partitionBy(rel)
+	}
+	return nil
+}
+
+// inherits reads the parents of rel. As in pg_attribute, their columns come first, merged with any of rel's columns
+// of the same name, which are then local, so not is_inherited.
+func (p *parser) inherits(rel *relation) error {
+	i, j := p.parens()
+	for _, r := range splitList(p.statement, i, j) {
+		schema, name, err := p.sub(r[0], r[1]).qualifiedName()
+		if err != nil {
+			return err
+		}
+		parent := p.cat.relation(schema, name)
+		if parent == nil {
+			return errUnknownRelation
+		}
+		rel.inherits = append(rel.inherits, parent)
+	}
+	var cols []*column
+	for _, parent := range rel.inherits {
+		for _, col := range parent.columns {
+			if columnNamed(cols, col.name) != nil {
+				continue
+			}
+			c := *col
+			c.acl, c.identity, c.inherited = nil, "", true
+			if local := rel.column(col.name); local != nil {
+				c.notNull = c.notNull || local.notNull
+				if local.def != "" {
+					c.def = local.def
+				}
+				c.inherited = false
+			}
+			cols = append(cols, &c)
+		}
+	}
+	for _, col := range rel.columns {
+		if columnNamed(cols, col.name) == nil {
+			cols = append(cols, col)
+		}
+	}
+	rel.columns = cols
+	return nil
+}
+
+// partitionBy reads the partition key of rel.
+func (p *parser) partitionBy(rel *relation) error {
+	method := strings.ToUpper(p.next().val)
+	i, j := p.parens()
+	rel.partitionKey = method + " (" + unqualify(squash(p.text(i, j))) + ")"
+	for _, r := range splitList(p.statement, i, j) {
+		sub := p.sub(r[0], r[1])
+		name, err := sub.name()
+		if err == nil && !sub.more() {
+			rel.partitionCols = append(rel.partitionCols, name)
+		}
+	}
+	return nil
+}
+
+func (p *parser) tableElement(rel *relation) error {
+	switch {
+	case p.isAnyWord("constraint", "primary", "unique", "foreign", "check", "exclude"):
+		return p.tableConstraint(rel)
+	case p.isWord("like"):
+		return nil
+	}
+	return p.columnDef(rel)
+}
+
+func (p *parser) columnDef(rel *relation) error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	col := rel.column(name)
+	if col == nil {
+		if p.word("with", "options") {
+			return nil
+		}
+		col = &column{name: name}
+		col.typ, err = p.typeName()
+		if err != nil {
+			return err
+		}
+		rel.columns = append(rel.columns, col)
+	} else {
+		p.word("with", "options")
+	}
+	if col.typ.serial {
+		seq := p.cat.addRelation(&relation{schema: rel.schema, name: rel.name + "_" + col.name + "_seq", kind: 'S'})
+		seq.seq = newSequence(col.typ.name)
+		col.notNull = true
+		col.def = fmt.Sprintf("nextval('%s'::regclass)", strings.Replace(seq.prettyName(), "'", "''", -1))
+		col.typ.serial = false
+	}
+	for p.more() {
+		name := ""
+		if p.word("constraint") {
+			name, err = p.name()
+			if err != nil {
+				return err
+			}
+		}
+		switch {
+		case p.word("not", "null"):
+			col.notNull = true
+		case p.word("null"):
+		case p.word("default"):
+			i, j := p.until(func() bool {
+				return p.isAnyWord("not", "null", "constraint", "primary", "unique", "references", "check",
+					"generated", "collate", "deferrable", "initially")
+			})
+			col.def = unqualify(p.text(i, j))
+		case p.word("collate"):
+			_, _, err = p.qualifiedName()
+		case p.word("options"):
+			col.fdwOptions, err = p.options(col.fdwOptions)
+		case p.word("generated"):
+			err = p.generated(rel, col)
+		case p.isAnyWord("primary", "unique", "references", "check"):
+			err = p.constraint(rel, name, col)
+		default:
+			p.next()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generated reads the rest of a GENERATED column option.
+func (p *parser) generated(rel *relation, col *column) error {
+	gen := ""
+	if p.word("always") {
+		gen = "ALWAYS"
+	} else if p.word("by", "default") {
+		gen = "BY DEFAULT"
+	}
+	if !p.word("as", "identity") {
+		p.word("as")
+		p.parens()
+		p.word("stored")
+		return nil
+	}
+	col.identity = gen
+	col.notNull = true
+	i, j := p.parens()
+	return p.sub(i, j).identitySequence(rel, col)
+}
+
+// identitySequence reads identity sequence options and adds the implicit sequence.
+func (p *parser) identitySequence(rel *relation, col *column) error {
+	schema, name := rel.schema, rel.name+"_"+col.name+"_seq"
+	typ := "bigint"
+	switch col.typ.name {
+	case "integer", "smallint":
+		typ = col.typ.name
+	}
+	var opts []token
+	for p.more() {
+		if p.word("sequence", "name") {
+			var err error
+			schema, name, err = p.qualifiedName()
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		opts = append(opts, p.next())
+	}
+	seq := p.cat.addRelation(&relation{schema: schema, name: name, kind: 'S'})
+	sub := &parser{statement: &statement{src: p.src, toks: opts}, cat: p.cat}
+	seq.seq = sub.sequenceOptions(typ)
+	seq.seq.identity = true
+	return nil
+}
+
+func (p *parser) tableConstraint(rel *relation) error {
+	name := ""
+	if p.word("constraint") {
+		var err error
+		name, err = p.name()
+		if err != nil {
+			return err
+		}
+	}
+	return p.constraint(rel, name, nil)
+}
+
+// constraint reads a table constraint, or a column constraint if col is not nil.
+func (p *parser) constraint(rel *relation, name string, col *column) error {
+	con := &constraint{name: name}
+	var cols []string
+	var err error
+	if col != nil {
+		cols = []string{col.name}
+	}
+	switch {
+	case p.word("primary", "key"), p.word("unique"):
+		con.typ = 'u'
+		keyword := "UNIQUE"
+		if p.toks[p.i-1].val == "key" {
+			con.typ = 'p'
+			keyword = "PRIMARY KEY"
+		}
+		if p.word("nulls", "not", "distinct") {
+			keyword += " NULLS NOT DISTINCT"
+		} else {
+			p.word("nulls", "distinct")
+		}
+		if col == nil {
+			cols, err = p.names()
+			if err != nil {
+				return err
+			}
+		}
+		include := ""
+		if p.word("include") {
+			inc, err := p.names()
+			if err != nil {
+				return err
+			}
+			include = " INCLUDE (" + quoteIdents(inc) + ")"
+		}
+		for p.more() && !p.isAnyWord("deferrable", "not", "initially") {
+			p.next()
+		}
+		con.def = keyword + " (" + quoteIdents(cols) + ")" + include + p.deferrable()
+		if con.name == "" {
+			if con.typ == 'p' {
+				con.name = rel.name + "_pkey"
+			} else {
+				con.name = rel.name + "_" + strings.Join(cols, "_") + "_key"
+			}
+		}
+		if con.typ == 'p' {
+			for _, c := range cols {
+				if c := rel.column(c); c != nil {
+					c.notNull = true
+				}
+			}
+		}
+		con.index = &index{rel: rel, name: con.name, unique: true, primary: con.typ == 'p', method: "btree",
+			cols: quoteIdents(cols), rest: include, con: con}
+		p.cat.addIndex(con.index)
+	case p.word("foreign", "key"), p.word("references"):
+		con.typ = 'f'
+		if p.toks[p.i-1].val == "key" {
+			cols, err = p.names()
+			if err != nil {
+				return err
+			}
+			p.word("references")
+		}
+		schema, table, err := p.qualifiedName()
+		if err != nil {
+			return err
+		}
+		ref := p.cat.relation(schema, table)
+		if ref == nil {
+			ref = &relation{schema: schema, name: table}
+		}
+		var refCols []string
+		if p.isPunct("(") {
+			refCols, err = p.names()
+			if err != nil {
+				return err
+			}
+		} else {
+			for _, c := range ref.constraints {
+				if c.typ == 'p' {
+					refCols = strings.Split(c.index.cols, ", ")
+				}
+			}
+		}
+		var action []string
+		notValid := ""
+		for p.more() && !p.isAnyWord("deferrable", "initially") {
+			if p.isWord("not") {
+				if !p.word("not", "valid") {
+					break
+				}
+				notValid = " NOT VALID"
+				continue
+			}
+			if p.isPunct("(") {
+				i, j := p.parens()
+				action = append(action, "("+p.text(i, j)+")")
+				continue
+			}
+			action = append(action, strings.ToUpper(p.next().val))
+		}
+		con.def = "FOREIGN KEY (" + quoteIdents(cols) + ") REFERENCES " + ref.prettyName() + "(" +
+			strings.Join(refCols, ", ") + ")"
+		if len(action) > 0 {
+			con.def += " " + strings.Join(action, " ")
+		}
+		con.def += p.deferrable() + notValid
+		if con.name == "" {
+			con.name = rel.name + "_" + strings.Join(cols, "_") + "_fkey"
+		}
+	case p.word("check"):
+		con.typ = 'c'
+		i, j := p.parens()
+		con.def = "CHECK (" + unqualify(p.text(i, j)) + ")"
+		if p.word("no", "inherit") {
+			con.def += " NO INHERIT"
+		}
+		if p.word("not", "valid") {
+			con.def += " NOT VALID"
+		}
+		if con.name == "" {
+			con.name = rel.name + "_check"
+			if col != nil {
+				con.name = rel.name + "_" + col.name + "_check"
+			}
+		}
+	case p.word("exclude"):
+		con.typ = 'x'
+		method := "btree"
+		if p.word("using") {
+			method, err = p.name()
+			if err != nil {
+				return err
+			}
+		}
+		i, j := p.parens()
+		var elems []string
+		for _, r := range splitList(p.statement, i, j) {
+			sub := p.sub(r[0], r[1])
+			k, l := sub.until(func() bool { return sub.isWord("with") })
+			elems = append(elems, sub.text(k, l))
+			if cols == nil {
+				cols = []string{sub.text(0, 1)}
+			}
+		}
+		k, l := p.rest()
+		rest, rawRest := "", ""
+		if k < l {
+			rawRest = " " + p.text(k, l)
+			rest = unqualify(rawRest)
+		}
+		con.def = "EXCLUDE USING " + method + " (" + unqualify(p.text(i, j)) + ")" + rest
+		if con.name == "" {
+			con.name = rel.name + "_" + strings.Join(cols, "_") + "_excl"
+		}
+		con.index = &index{rel: rel, name: con.name, method: method, cols: strings.Join(elems, ", "),
+			rest: rawRest, con: con}
+		p.cat.addIndex(con.index)
+	default:
+		return nil
+	}
+	rel.constraints = append(rel.constraints, con)
+	return nil
+}
+
+// deferrable reads constraint deferral options in the form pg_get_constraintdef renders them.
+func (p *parser) deferrable() string {
+	def := ""
+	for p.more() {
+		switch {
+		case p.word("deferrable"):
+			def += " DEFERRABLE"
+		case p.word("not", "deferrable"):
+		case p.word("initially", "deferred"):
+			def += " INITIALLY DEFERRED"
+		case p.word("initially", "immediate"):
+		default:
+			return def
+		}
+	}
+	return def
+}
+
+// alterRelation reads ALTER TABLE and the other ALTER statements that share its OWNER TO syntax.
+func (p *parser) alterRelation() error {
+	p.word("if", "exists")
+	p.word("only")
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	p.punct("*")
+	rel := p.cat.relation(schema, name)
+	if rel == nil {
+		return errUnknownRelation
+	}
+	i, j := p.rest()
+	for _, r := range splitList(p.statement, i, j) {
+		err = p.sub(r[0], r[1]).alterAction(rel)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *parser) alterAction(rel *relation) error {
+	var err error
+	switch {
+	case p.word("add"):
+		if p.isAnyWord("constraint", "primary", "unique", "foreign", "check", "exclude") {
+			return p.tableConstraint(rel)
+		}
+		p.word("column")
+		p.word("if", "not", "exists")
+		return p.columnDef(rel)
+	case p.word("alter"):
+		p.word("column")
+		var name string
+		name, err = p.name()
+		if err != nil {
+			return err
+		}
+		col := rel.column(name)
+		if col == nil {
+			return nil
+		}
+		switch {
+		case p.word("set", "default"):
+			i, j := p.rest()
+			col.def = unqualify(p.text(i, j))
+		case p.word("drop", "default"):
+			col.def = ""
+		case p.word("set", "not", "null"):
+			col.notNull = true
+		case p.word("drop", "not", "null"):
+			col.notNull = false
+		case p.word("add", "generated"):
+			p.i--
+			p.word("generated")
+			return p.generated(rel, col)
+		case p.word("set", "data", "type"), p.word("type"):
+			col.typ, err = p.typeName()
+		case p.word("options"):
+			col.fdwOptions, err = p.options(col.fdwOptions)
+		}
+	case p.word("options"):
+		rel.fdwOptions, err = p.options(rel.fdwOptions)
+	case p.word("owner", "to"):
+		rel.owner, err = p.name()
+		p.cat.owners = true
+	case p.word("attach", "partition"):
+		var schema, name string
+		schema, name, err = p.qualifiedName()
+		if err != nil {
+			return err
+		}
+		part := p.cat.relation(schema, name)
+		if part == nil {
+			return errUnknownRelation
+		}
+		i, j := p.rest()
+		part.parent, part.bound = rel, unqualify(squash(p.text(i, j)))
+	case p.word("detach", "partition"):
+		var schema, name string
+		schema, name, err = p.qualifiedName()
+		if part := p.cat.relation(schema, name); part != nil {
+			part.parent, part.bound = nil, ""
+		}
+	case p.word("enable", "row", "level", "security"):
+		rel.rowSecurity = true
+	case p.word("disable", "row", "level", "security"):
+		rel.rowSecurity = false
+	case p.word("force", "row", "level", "security"):
+		rel.forceRowSecurity = true
+	case p.word("no", "force", "row", "level", "security"):
+		rel.forceRowSecurity = false
+	case p.isAnyWord("enable", "disable"):
+		enabled := "O"
+		switch {
+		case p.word("disable"):
+			enabled = "D"
+		case p.word("enable", "replica"):
+			enabled = "R"
+		case p.word("enable", "always"):
+			enabled = "A"
+		default:
+			p.next()
+		}
+		if !p.word("trigger") {
+			return nil
+		}
+		var name string
+		name, err = p.name()
+		for _, t := range p.cat.triggers {
+			if t.rel == rel && t.name == name {
+				t.enabled = enabled
+			}
+		}
+	}
+	return err
+}
+
+// ==================================
+// Sequences
+// ==================================
+
+func (p *parser) createSequence() error {
+	p.word("if", "not", "exists")
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	rel := p.cat.addRelation(&relation{schema: schema, name: name, kind: 'S'})
+	rel.seq = p.sequenceOptions("bigint")
+	return nil
+}
+
+func newSequence(typ string) *sequence {
+	seq := &sequence{typ: typ, increment: "1"}
+	seq.fillDefaults()
+	return seq
+}
+
+// sequenceOptions reads the options of CREATE SEQUENCE or an identity column.
+func (p *parser) sequenceOptions(typ string) *sequence {
+	seq := &sequence{typ: typ, increment: "1"}
+	for p.more() {
+		switch {
+		case p.word("as"):
+			t, err := p.typeName()
+			if err == nil {
+				seq.typ = t.name
+			}
+		case p.word("increment"):
+			p.word("by")
+			seq.increment = p.number()
+		case p.word("no"):
+			p.next()
+		case p.word("minvalue"):
+			seq.min = p.number()
+		case p.word("maxvalue"):
+			seq.max = p.number()
+		case p.word("start"):
+			p.word("with")
+			seq.start = p.number()
+		case p.word("cycle"):
+			seq.cycle = true
+		case p.word("owned", "by"):
+			p.rest()
+		default:
+			p.next()
+		}
+	}
+	seq.fillDefaults()
+	return seq
+}
+
+// fillDefaults sets the limits and start value PostgreSQL would choose when they are not specified.
+func (s *sequence) fillDefaults() {
+	min, max := "-9223372036854775808", "9223372036854775807"
+	switch s.typ {
+	case "integer":
+		min, max = "-2147483648", "2147483647"
+	case "smallint":
+		min, max = "-32768", "32767"
+	}
+	descending := strings.HasPrefix(s.increment, "-")
+	if s.min == "" {
+		if descending {
+			s.min = min
+		} else {
+			s.min = "1"
+		}
+	}
+	if s.max == "" {
+		if descending {
+			s.max = "-1"
+		} else {
+			s.max = max
+		}
+	}
+	if s.start == "" {
+		if descending {
+			s.start = s.max
+		} else {
+			s.start = s.min
+		}
+	}
+}
+
+// ==================================
+// Indexes
+// ==================================
+
+func (p *parser) createIndex() error {
+	idx := &index{method: "btree"}
+	idx.unique = p.word("unique")
+	p.word("index")
+	p.word("concurrently")
+	p.word("if", "not", "exists")
+	if !p.isWord("on") {
+		n, err := p.name()
+		if err != nil {
+			return err
+		}
+		idx.name = n
+	}
+	p.word("on")
+	p.word("only")
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	idx.rel = p.cat.relation(schema, name)
+	if idx.rel == nil {
+		return errUnknownRelation
+	}
+	if p.word("using") {
+		idx.method, err = p.name()
+		if err != nil {
+			return err
+		}
+	}
+	i, j := p.parens()
+	idx.cols = p.text(i, j)
+	k, l := p.rest()
+	for m := k; m < l; m++ {
+		if p.toks[m].kind == wordToken && p.toks[m].val == "tablespace" && m+1 < l {
+			idx.rest = strings.TrimSpace(p.text(k, m) + " " + p.text(m+2, l))
+			break
+		}
+	}
+	if idx.rest == "" {
+		idx.rest = p.text(k, l)
+	}
+	if idx.rest != "" {
+		idx.rest = " " + idx.rest
+	}
+	if idx.name == "" {
+		first := p.sub(i, j)
+		col, _ := first.name()
+		idx.name = idx.rel.name + "_" + col + "_idx"
+	}
+	p.cat.addIndex(idx)
+	return nil
+}
+
+// alterIndex only notes the indexes of partitions that are attached to an index of their partitioned table, as they are
+// created along with the partition.
+func (p *parser) alterIndex() error {
+	p.word("if", "exists")
+	if _, _, err := p.qualifiedName(); err != nil {
+		return err
+	}
+	if !p.word("attach", "partition") {
+		return nil
+	}
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	idx := p.cat.index(schema, name)
+	if idx == nil {
+		return errUnknownRelation
+	}
+	idx.attached = true
+	return nil
+}
+
+// ==================================
+// Views
+// ==================================
+
+func (p *parser) createView(kind byte) error {
+	p.word("if", "not", "exists")
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	if p.isPunct("(") {
+		p.parens()
+	}
+	if p.word("using") {
+		p.next()
+	}
+	if p.word("with") {
+		p.parens()
+	}
+	if p.word("tablespace") {
+		p.next()
+	}
+	if !p.word("as") {
+		return p.errorf("expected AS")
+	}
+	i, j := p.rest()
+	for _, tail := range [][]string{{"with", "no", "data"}, {"with", "data"}, {"with", "check", "option"},
+		{"with", "cascaded", "check", "option"}, {"with", "local", "check", "option"}} {
+		if j-len(tail) > i && p.sub(j-len(tail), j).word(tail...) {
+			j -= len(tail)
+			break
+		}
+	}
+	p.cat.addRelation(&relation{schema: schema, name: name, kind: kind, definition: " " + unqualify(p.text(i, j)) + ";"})
+	return nil
+}
+
+// ==================================
+// Functions
+// ==================================
+
+// createFunction reads CREATE FUNCTION or, if kind is PROCEDURE, CREATE PROCEDURE.
+func (p *parser) createFunction(kind string) error {
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	f := &function{kind: kind, schema: schema, name: name}
+	i, j := p.parens()
+	for _, r := range splitList(p.statement, i, j) {
+		arg, err := p.sub(r[0], r[1]).argument()
+		if err != nil {
+			return err
+		}
+		f.args = append(f.args, arg)
+	}
+	for p.more() {
+		switch {
+		case p.word("returns", "null", "on", "null", "input"), p.word("strict"):
+			f.options = append(f.options, "STRICT")
+		case p.word("returns"):
+			err = p.returns(f)
+		case p.word("language"):
+			f.language, err = p.name()
+		case p.word("window"):
+			f.kind = "WINDOW"
+			f.options = append(f.options, "WINDOW")
+		case p.word("immutable"), p.word("stable"), p.word("volatile"), p.word("leakproof"):
+			f.options = append(f.options, strings.ToUpper(p.toks[p.i-1].val))
+		case p.word("not", "leakproof"), p.word("called", "on", "null", "input"):
+		case p.word("security", "definer"), p.word("external", "security", "definer"):
+			f.options = append(f.options, "SECURITY DEFINER")
+		case p.word("security", "invoker"), p.word("external", "security", "invoker"):
+		case p.word("parallel"):
+			f.options = append(f.options, "PARALLEL "+strings.ToUpper(p.next().val))
+		case p.word("cost"):
+			f.options = append(f.options, "COST "+p.number())
+		case p.word("rows"):
+			f.options = append(f.options, "ROWS "+p.number())
+		case p.word("support"):
+			s, n, err := p.qualifiedName()
+			if err == nil {
+				f.options = append(f.options, "SUPPORT "+unqualify(s+"."+n))
+			}
+		case p.word("set"):
+			param, _ := p.name()
+			var value string
+			if p.word("from", "current") {
+				value = "FROM CURRENT"
+			} else {
+				if !p.word("to") {
+					p.punct("=")
+				}
+				k, l := p.until(func() bool { return p.isAnyWord(functionOptionWords...) })
+				value = "TO " + p.text(k, l)
+			}
+			f.config = append(f.config, "SET "+param+" "+value)
+		case p.word("as"):
+			t := p.next()
+			if p.punct(",") {
+				f.probin = t.val
+				t = p.next()
+			}
+			f.body = t.val
+		case p.isWord("return"), p.isWord("begin"):
+			k, l := p.rest()
+			f.sqlBody = p.text(k, l)
+		case p.word("transform"):
+			p.until(func() bool { return p.isAnyWord(functionOptionWords...) })
+		default:
+			p.next()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	p.cat.functions = append(p.cat.functions, f)
+	return nil
+}
+
+// aggParamOrder is the order in which the catalog query writes the parameters of an aggregate.
+var aggParamOrder = []string{"sfunc", "stype", "sspace", "finalfunc", "finalfunc_extra", "finalfunc_modify",
+	"combinefunc", "serialfunc", "deserialfunc", "initcond", "msfunc", "minvfunc", "mstype", "msspace", "mfinalfunc",
+	"mfinalfunc_extra", "mfinalfunc_modify", "minitcond", "sortop", "parallel", "hypothetical"}
+
+// createAggregate reads CREATE AGGREGATE in the current syntax.
+func (p *parser) createAggregate() error {
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	f := &function{kind: "AGGREGATE", schema: schema, name: name}
+	i, j := p.parens()
+	if j > i+1 || !p.sub(i, j).punct("*") {
+		for _, r := range splitList(p.statement, i, j) {
+			k := r[0]
+			for ; k < r[1]; k++ {
+				if p.toks[k].kind == wordToken && p.toks[k].val == "order" {
+					break
+				}
+			}
+			orderBy := false
+			for _, part := range [][2]int{{r[0], k}, {k + 2, r[1]}} {
+				if part[0] < part[1] {
+					arg, err := p.sub(part[0], part[1]).argument()
+					if err != nil {
+						return err
+					}
+					arg.orderBy = orderBy
+					f.args = append(f.args, arg)
+				}
+				orderBy = k < r[1]
+			}
+		}
+	}
+	params := map[string]*aggParam{}
+	i, j = p.parens()
+	for _, r := range splitList(p.statement, i, j) {
+		sub := p.sub(r[0], r[1])
+		param := &aggParam{key: sub.next().val}
+		if sub.punct("=") {
+			err = sub.aggParam(param)
+			if err != nil {
+				return err
+			}
+		}
+		params[param.key] = param
+	}
+	defaultModify := "READ_ONLY"
+	for _, arg := range f.args {
+		if arg.orderBy {
+			defaultModify = "READ_WRITE"
+		}
+	}
+	for _, key := range aggParamOrder {
+		param := params[key]
+		if param == nil {
+			continue
+		}
+		switch param.value {
+		case "0":
+			if key == "sspace" || key == "msspace" {
+				continue
+			}
+		case "UNSAFE":
+			continue
+		case defaultModify:
+			if key == "finalfunc_modify" || key == "mfinalfunc_modify" {
+				continue
+			}
+		}
+		f.aggregate = append(f.aggregate, param)
+	}
+	f.returnType = p.aggregateReturnType(params)
+	p.cat.functions = append(p.cat.functions, f)
+	return nil
+}
+
+// aggParam reads the value of an aggregate parameter.
+func (p *parser) aggParam(param *aggParam) error {
+	var err error
+	switch param.key {
+	case "sfunc", "finalfunc", "combinefunc", "serialfunc", "deserialfunc", "msfunc", "minvfunc", "mfinalfunc":
+		param.value, err = p.regproc()
+	case "stype", "mstype":
+		param.typ, err = p.typeName()
+	case "initcond", "minitcond":
+		v := p.next().val
+		param.value = "'" + strings.Replace(v, "'", "''", -1) + "'"
+		if strings.Contains(v, `\`) {
+			param.value = "E" + strings.Replace(param.value, `\`, `\\`, -1)
+		}
+	case "sortop":
+		op := ""
+		if p.word("operator") {
+			i, j := p.parens()
+			op = strings.Replace(p.text(i, j), " ", "", -1)
+		} else {
+			i, j := p.rest()
+			op = strings.Replace(p.text(i, j), " ", "", -1)
+		}
+		if !strings.Contains(op, ".") {
+			op = "pg_catalog." + op
+		}
+		param.value = "OPERATOR(" + op + ")"
+	default:
+		param.value = strings.ToUpper(p.next().val)
+	}
+	return err
+}
+
+// aggregateReturnType returns the pg_type name of the type an aggregate returns: that of its final function if it has
+// one created in the dump, otherwise that of its state. It returns "" if the final function is not known.
+func (p *parser) aggregateReturnType(params map[string]*aggParam) string {
+	final := params["finalfunc"]
+	if final == nil {
+		if stype := params["stype"]; stype != nil && stype.typ != nil {
+			name := stype.typ.udtName()
+			if stype.typ.dims > 0 {
+				name = "_" + name
+			}
+			return name
+		}
+		return ""
+	}
+	for _, f := range p.cat.functions {
+		name := quoteIdent(f.name)
+		if f.schema != defaultNamespace {
+			name = quoteIdent(f.schema) + "." + name
+		}
+		if name == final.value {
+			return f.resultType()
+		}
+	}
+	return ""
+}
+
+// argument reads a single function argument.
+func (p *parser) argument() (*argument, error) {
+	arg := &argument{}
+	if p.isAnyWord("in", "out", "inout", "variadic") {
+		arg.mode = p.next().val
+		if arg.mode == "in" {
+			arg.mode = ""
+		}
+	}
+	start := p.i
+	t, err := p.typeName()
+	if err != nil || (p.more() && !p.isWord("default") && !p.isPunct("=")) {
+		p.i = start
+		arg.name, err = p.name()
+		if err != nil {
+			return nil, err
+		}
+		t, err = p.typeName()
+		if err != nil {
+			return nil, err
+		}
+	}
+	arg.typ = t
+	if p.word("default") || p.punct("=") {
+		i, j := p.rest()
+		arg.def = unqualify(p.text(i, j))
+	}
+	return arg, nil
+}
+
+// returns reads a function's return type.
+func (p *parser) returns(f *function) error {
+	if p.word("table") {
+		i, j := p.parens()
+		var cols []string
+		for _, r := range splitList(p.statement, i, j) {
+			sub := p.sub(r[0], r[1])
+			name, err := sub.name()
+			if err != nil {
+				return err
+			}
+			t, err := sub.typeName()
+			if err != nil {
+				return err
+			}
+			f.args = append(f.args, &argument{mode: "table", name: name, typ: t})
+			cols = append(cols, name)
+		}
+		f.returnType = "record"
+		return nil
+	}
+	setof := p.word("setof")
+	t, err := p.typeName()
+	if err != nil {
+		return err
+	}
+	f.args = append(f.args, &argument{mode: "return", typ: t})
+	if setof {
+		f.returns = "SETOF "
+	}
+	f.returnType = t.udtName()
+	if t.dims > 0 {
+		f.returnType = "_" + f.returnType
+	}
+	return nil
+}
+
+// ==================================
+// Operators
+// ==================================
+
+// operatorName reads a possibly schema qualified operator name, returning an empty schema if it is not qualified.
+func (p *parser) operatorName() (string, string, error) {
+	schema := ""
+	if t := p.peek(); t.kind == wordToken || t.kind == identToken {
+		schema, _ = p.name()
+		if !p.punct(".") {
+			return "", "", p.errorf("expected . after %s", schema)
+		}
+	}
+	t := p.next()
+	if t.kind != punctToken {
+		return "", "", p.errorf("expected an operator")
+	}
+	return schema, t.val, nil
+}
+
+// operandTypes reads a parenthesised pair of operand types, either of which may be NONE, rendered as format_type
+// would.
+func (p *parser) operandTypes() ([]string, error) {
+	i, j := p.parens()
+	var types []string
+	for _, r := range splitList(p.statement, i, j) {
+		sub := p.sub(r[0], r[1])
+		if sub.word("none") {
+			types = append(types, "NONE")
+			continue
+		}
+		t, err := sub.typeName()
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, t.format(p.cat))
+	}
+	return types, nil
+}
+
+func (p *parser) createOperator() error {
+	schema, name, err := p.operatorName()
+	if err != nil {
+		return err
+	}
+	if schema == "" {
+		schema = defaultNamespace
+	}
+	op := &operator{schema: schema, name: name, left: "NONE", commutator: "null", negator: "null", restrict: "null",
+		join: "null"}
+	i, j := p.parens()
+	for _, r := range splitList(p.statement, i, j) {
+		sub := p.sub(r[0], r[1])
+		key := sub.next().val
+		sub.punct("=")
+		switch key {
+		case "function", "procedure":
+			op.function, err = sub.regproc()
+		case "leftarg", "rightarg":
+			var t *typeName
+			t, err = sub.typeName()
+			if err == nil && key == "leftarg" {
+				op.left = t.format(p.cat)
+			} else if err == nil {
+				op.right = t.format(p.cat)
+			}
+		case "commutator":
+			op.commutator, err = sub.relatedOperator(schema)
+		case "negator":
+			op.negator, err = sub.relatedOperator(schema)
+		case "restrict":
+			op.restrict, err = sub.regproc()
+		case "join":
+			op.join, err = sub.regproc()
+		case "hashes":
+			op.hashes = true
+		case "merges":
+			op.merges = true
+		}
+		if err != nil {
+			return err
+		}
+	}
+	p.cat.operators = append(p.cat.operators, op)
+	return nil
+}
+
+// relatedOperator reads the commutator or negator of an operator in schema, which it is looked up in if it is not
+// qualified, and renders it as a qualified OPERATOR() name.
+func (p *parser) relatedOperator(schema string) (string, error) {
+	sub := p
+	if p.word("operator") {
+		sub = p.sub(p.parens())
+	}
+	opSchema, name, err := sub.operatorName()
+	if err != nil {
+		return "", err
+	}
+	if opSchema == "" {
+		opSchema = schema
+	}
+	return "OPERATOR(" + quoteIdent(opSchema) + "." + name + ")", nil
+}
+
+func (p *parser) createOperatorFamily() error {
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	if !p.word("using") {
+		return p.errorf("expected USING")
+	}
+	method, err := p.name()
+	if err != nil {
+		return err
+	}
+	if p.cat.opFamily(schema, name, method) == nil {
+		p.cat.opFamilies = append(p.cat.opFamilies, &opFamily{schema: schema, name: name, method: method})
+	}
+	return nil
+}
+
+// alterOperatorFamily reads the members ALTER OPERATOR FAMILY adds to a family outside of any class.
+func (p *parser) alterOperatorFamily() error {
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	if !p.word("using") {
+		return p.errorf("expected USING")
+	}
+	method, err := p.name()
+	if err != nil {
+		return err
+	}
+	f := p.cat.opFamily(schema, name, method)
+	if f == nil || !p.word("add") {
+		return nil
+	}
+	i, j := p.rest()
+	for _, r := range splitList(p.statement, i, j) {
+		member, err := p.sub(r[0], r[1]).opMember(method, "")
+		if err != nil {
+			return err
+		}
+		f.members = append(f.members, member)
+	}
+	return nil
+}
+
+func (p *parser) createOperatorClass() error {
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	c := &opClass{schema: schema, name: name, storage: "null"}
+	c.isDefault = p.word("default")
+	if !p.word("for", "type") {
+		return p.errorf("expected FOR TYPE")
+	}
+	t, err := p.typeName()
+	if err != nil {
+		return err
+	}
+	c.typ = t.format(p.cat)
+	if !p.word("using") {
+		return p.errorf("expected USING")
+	}
+	c.method, err = p.name()
+	if err != nil {
+		return err
+	}
+	famSchema, famName := schema, name
+	if p.word("family") {
+		famSchema, famName, err = p.qualifiedName()
+		if err != nil {
+			return err
+		}
+	}
+	c.family = p.cat.opFamily(famSchema, famName, c.method)
+	if c.family == nil {
+		c.family = &opFamily{schema: famSchema, name: famName, method: c.method}
+		p.cat.opFamilies = append(p.cat.opFamilies, c.family)
+	}
+	if !p.word("as") {
+		return p.errorf("expected AS")
+	}
+	i, j := p.rest()
+	for _, r := range splitList(p.statement, i, j) {
+		sub := p.sub(r[0], r[1])
+		if sub.word("storage") {
+			t, err := sub.typeName()
+			if err != nil {
+				return err
+			}
+			if storage := t.format(p.cat); storage != c.typ {
+				c.storage = storage
+			}
+			continue
+		}
+		member, err := sub.opMember(c.method, c.typ)
+		if err != nil {
+			return err
+		}
+		c.members = append(c.members, member)
+	}
+	p.cat.opClasses = append(p.cat.opClasses, c)
+	return nil
+}
+
+// opMember reads an OPERATOR or FUNCTION item of an operator class or family and renders it as the catalog query does,
+// with its operand types written out. Omitted operand types are those of the class, typ, or for the comparison and hash
+// functions of btree and hash, those of the function.
+func (p *parser) opMember(method, typ string) (string, error) {
+	kind := strings.ToUpper(p.next().val)
+	num := p.next().val
+	var types []string
+	var err error
+	switch kind {
+	case "OPERATOR":
+		schema, name, err := p.operatorName()
+		if err != nil {
+			return "", err
+		}
+		if schema != "" && schema != defaultNamespace && schema != "pg_catalog" {
+			name = quoteIdent(schema) + "." + name
+		}
+		if p.isPunct("(") {
+			types, err = p.operandTypes()
+			if err != nil {
+				return "", err
+			}
+		} else {
+			types = []string{typ, typ}
+		}
+		member := "OPERATOR " + num + " " + name + "(" + strings.Join(types, ",") + ")"
+		if p.word("for", "order", "by") {
+			schema, family, err := p.qualifiedName()
+			if err != nil {
+				return "", err
+			}
+			member += " FOR ORDER BY " + visibleName(schema, family)
+		}
+		return member, nil
+	case "FUNCTION":
+		if p.isPunct("(") {
+			types, err = p.operandTypes()
+			if err != nil {
+				return "", err
+			}
+		}
+		name, err := p.regproc()
+		if err != nil {
+			return "", err
+		}
+		args, err := p.operandTypes()
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case len(types) == 1:
+			types = append(types, types[0])
+		case len(types) > 0:
+		case method == "btree" && num == "1" && len(args) == 2:
+			types = args
+		case method == "hash" && len(args) > 0:
+			types = []string{args[0], args[0]}
+		default:
+			types = []string{typ, typ}
+		}
+		return "FUNCTION " + num + " (" + strings.Join(types, ", ") + ") " + name + "(" + strings.Join(args, ",") + ")",
+			nil
+	}
+	return "", p.errorf("expected OPERATOR or FUNCTION")
+}
+
+// ==================================
+// Triggers
+// ==================================
+
+func (p *parser) createTrigger(start int) error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	p.until(func() bool { return p.isWord("on") })
+	if !p.word("on") {
+		return p.errorf("expected ON")
+	}
+	schema, table, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	rel := p.cat.relation(schema, table)
+	if rel == nil {
+		return errUnknownRelation
+	}
+	def := "CREATE " + unqualify(squash(p.text(start, len(p.toks))))
+	def = strings.Replace(def, "EXECUTE PROCEDURE", "EXECUTE FUNCTION", 1)
+	p.cat.triggers = append(p.cat.triggers, &trigger{rel: rel, name: name, def: def, enabled: "O"})
+	return nil
+}
+
+func (p *parser) createEventTrigger() error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	if !p.word("on") {
+		return p.errorf("expected ON")
+	}
+	t := &eventTrigger{name: name, event: p.next().val, enabled: "O"}
+	if p.word("when") {
+		for p.word("tag", "in") || p.word("and", "tag", "in") {
+			i, j := p.parens()
+			for k := i; k < j; k++ {
+				if p.toks[k].kind == stringToken {
+					t.tags = append(t.tags, strings.ToUpper(p.toks[k].val))
+				}
+			}
+		}
+	}
+	if !p.word("execute", "function") && !p.word("execute", "procedure") {
+		return p.errorf("expected EXECUTE FUNCTION")
+	}
+	t.function, err = p.procName()
+	if err != nil {
+		return err
+	}
+	p.cat.evtTrigs = append(p.cat.evtTrigs, t)
+	return nil
+}
+
+func (p *parser) alterEventTrigger() error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	for _, t := range p.cat.evtTrigs {
+		if t.name != name {
+			continue
+		}
+		switch {
+		case p.word("disable"):
+			t.enabled = "D"
+		case p.word("enable", "replica"):
+			t.enabled = "R"
+		case p.word("enable", "always"):
+			t.enabled = "A"
+		case p.word("enable"):
+			t.enabled = "O"
+		}
+	}
+	return nil
+}
+
+// ==================================
+// Rules
+// ==================================
+
+func (p *parser) createRule(start int) error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	p.until(func() bool { return p.isWord("to") })
+	if !p.word("to") {
+		return p.errorf("expected TO")
+	}
+	schema, table, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	rel := p.cat.relation(schema, table)
+	if rel == nil {
+		return errUnknownRelation
+	}
+	var rules []*rule
+	for _, r := range p.cat.rules {
+		if r.rel != rel || r.name != name {
+			rules = append(rules, r)
+		}
+	}
+	def := "CREATE " + unqualify(p.text(start, len(p.toks)))
+	p.cat.rules = append(rules, &rule{rel: rel, name: name, def: def})
+	return nil
+}
+
+// ==================================
+// Policies
+// ==================================
+
+func (p *parser) createPolicy() error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	if !p.word("on") {
+		return p.errorf("expected ON")
+	}
+	schema, table, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	rel := p.cat.relation(schema, table)
+	if rel == nil {
+		return errUnknownRelation
+	}
+	pol := &policy{rel: rel, name: name, command: "ALL"}
+	if p.word("as") {
+		pol.restrictive = p.word("restrictive")
+		p.word("permissive")
+	}
+	if p.word("for") {
+		pol.command = strings.ToUpper(p.next().val)
+	}
+	if p.word("to") {
+		i, j := p.until(func() bool { return p.isAnyWord("using", "with") })
+		for _, r := range splitList(p.statement, i, j) {
+			role, quoted, err := p.sub(r[0], r[1]).nameToken()
+			if err != nil {
+				return err
+			}
+			if !quoted && role == "public" {
+				pol.roles = nil
+				break
+			}
+			pol.roles = append(pol.roles, role)
+		}
+	}
+	if p.word("using") {
+		i, j := p.parens()
+		pol.using = unqualify(p.text(i, j))
+	}
+	if p.word("with", "check") {
+		i, j := p.parens()
+		pol.check = unqualify(p.text(i, j))
+	}
+	p.cat.policies = append(p.cat.policies, pol)
+	return nil
+}
+
+// ==================================
+// Foreign data
+// ==================================
+
+func (p *parser) createForeignDataWrapper() error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	w := &foreignDataWrapper{name: name}
+	for p.more() && err == nil {
+		switch {
+		case p.word("handler"):
+			w.handler, err = p.procName()
+		case p.word("validator"):
+			w.validator, err = p.procName()
+		case p.word("options"):
+			w.options, err = p.options(nil)
+		default:
+			p.next()
+		}
+	}
+	if err != nil {
+		return err
+	}
+	p.cat.wrappers = append(p.cat.wrappers, w)
+	return nil
+}
+
+func (p *parser) createServer() error {
+	p.word("if", "not", "exists")
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	srv := &foreignServer{name: name}
+	for p.more() && err == nil {
+		switch {
+		case p.word("type"):
+			srv.typ = p.next().val
+		case p.word("version"):
+			srv.version = p.next().val
+		case p.word("foreign", "data", "wrapper"):
+			srv.fdw, err = p.name()
+		case p.word("options"):
+			srv.options, err = p.options(nil)
+		default:
+			p.next()
+		}
+	}
+	if err != nil {
+		return err
+	}
+	p.cat.servers = append(p.cat.servers, srv)
+	return nil
+}
+
+func (p *parser) createUserMapping() error {
+	p.word("if", "not", "exists")
+	if !p.word("for") {
+		return p.errorf("expected FOR")
+	}
+	user, quoted, err := p.nameToken()
+	if err != nil {
+		return err
+	}
+	if !quoted && user == "public" {
+		user = "PUBLIC"
+	}
+	if !p.word("server") {
+		return p.errorf("expected SERVER")
+	}
+	um := &userMapping{user: user}
+	um.server, err = p.name()
+	if err != nil {
+		return err
+	}
+	if p.word("options") {
+		um.options, err = p.options(nil)
+		if err != nil {
+			return err
+		}
+	}
+	p.cat.mappings = append(p.cat.mappings, um)
+	return nil
+}
+
+func (p *parser) createForeignTable() error {
+	p.word("if", "not", "exists")
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	rel := p.cat.addRelation(&relation{schema: schema, name: name, kind: 'f'})
+	i, j := p.parens()
+	for _, r := range splitList(p.statement, i, j) {
+		err = p.sub(r[0], r[1]).tableElement(rel)
+		if err != nil {
+			return err
+		}
+	}
+	if p.word("inherits") {
+		if err = p.inherits(rel); err != nil {
+			return err
+		}
+	}
+	if !p.word("server") {
+		return p.errorf("expected SERVER")
+	}
+	rel.server, err = p.name()
+	if err != nil {
+		return err
+	}
+	if p.word("options") {
+		rel.fdwOptions, err = p.options(nil)
+	}
+	return err
+}
+
+// procName reads the name of a function, rendered as regproc renders it.
+func (p *parser) procName() (string, error) {
+	schema, name, err := p.qualifiedName()
+	if err != nil || schema == defaultNamespace {
+		return quoteIdent(name), err
+	}
+	return quoteIdent(schema) + "." + quoteIdent(name), nil
+}
+
+// regproc reads a possibly schema qualified function name and renders it as regproc would under the default
+// search_path, which finds both public and pg_catalog.
+func (p *parser) regproc() (string, error) {
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return "", err
+	}
+	return visibleName(schema, name), nil
+}
+
+// visibleName renders the name of an object in schema as the catalog functions would under the default search_path.
+func visibleName(schema, name string) string {
+	if schema == defaultNamespace || schema == "pg_catalog" {
+		return quoteIdent(name)
+	}
+	return quoteIdent(schema) + "." + quoteIdent(name)
+}
+
+// options reads a parenthesised list of generic options, applying any ADD, SET and DROP actions to opts.
+func (p *parser) options(opts []string) ([]string, error) {
+	i, j := p.parens()
+	for _, r := range splitList(p.statement, i, j) {
+		sub := p.sub(r[0], r[1])
+		action := "add"
+		if sub.isAnyWord("add", "set", "drop") && sub.i+1 < len(sub.toks) && sub.toks[sub.i+1].kind != stringToken {
+			action = sub.next().val
+		}
+		name, err := sub.name()
+		if err != nil {
+			return nil, err
+		}
+		var kept []string
+		for _, opt := range opts {
+			if !strings.HasPrefix(opt, name+"=") {
+				kept = append(kept, opt)
+			}
+		}
+		if action == "drop" {
+			opts = kept
+			continue
+		}
+		value := sub.next()
+		if value.kind != stringToken {
+			return nil, sub.errorf("expected an option value")
+		}
+		if action == "set" {
+			for k, opt := range opts {
+				if strings.HasPrefix(opt, name+"=") {
+					opts[k] = name + "=" + value.val
+				}
+			}
+			continue
+		}
+		opts = append(kept, name+"="+value.val)
+	}
+	return opts, nil
+}
+
+// ==================================
+// Replication
+// ==================================
+
+func (p *parser) createPublication() error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	pub := &publication{name: name, publish: map[string]bool{"insert": true, "update": true, "delete": true, "truncate": true}}
+	if p.word("for", "all", "tables") {
+		pub.allTables = true
+	} else if p.word("for") {
+		i, j := p.until(func() bool { return p.isWord("with") })
+		err = p.sub(i, j).publicationObjects(pub, false)
+		if err != nil {
+			return err
+		}
+	}
+	if p.word("with") {
+		p.publicationParams(pub)
+	}
+	p.cat.pubs = append(p.cat.pubs, pub)
+	return nil
+}
+
+func (p *parser) alterPublication() error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	pub := p.cat.publication(name)
+	if pub == nil {
+		return nil
+	}
+	switch {
+	case p.word("add"):
+		return p.publicationObjects(pub, false)
+	case p.word("drop"):
+		return p.publicationObjects(pub, true)
+	case p.word("set"):
+		if p.isPunct("(") {
+			p.publicationParams(pub)
+			return nil
+		}
+		pub.tables, pub.schemas = nil, nil
+		return p.publicationObjects(pub, false)
+	}
+	return nil
+}
+
+// publicationObjects reads a list of TABLE and TABLES IN SCHEMA objects, adding them to pub or dropping them from it.
+func (p *parser) publicationObjects(pub *publication, drop bool) error {
+	inSchema := false
+	for _, r := range splitList(p.statement, p.i, len(p.toks)) {
+		sub := p.sub(r[0], r[1])
+		if sub.word("tables", "in", "schema") {
+			inSchema = true
+		} else if sub.word("table") {
+			inSchema = false
+		}
+		if inSchema {
+			name, err := sub.name()
+			if err != nil {
+				return err
+			}
+			var kept []string
+			for _, schema := range pub.schemas {
+				if schema != name {
+					kept = append(kept, schema)
+				}
+			}
+			if !drop {
+				kept = append(kept, name)
+			}
+			pub.schemas = kept
+			continue
+		}
+		sub.word("only")
+		schema, name, err := sub.qualifiedName()
+		if err != nil {
+			return err
+		}
+		sub.punct("*")
+		rel := p.cat.relation(schema, name)
+		if rel == nil {
+			return errUnknownRelation
+		}
+		var kept []*publishedTable
+		for _, t := range pub.tables {
+			if t.rel != rel {
+				kept = append(kept, t)
+			}
+		}
+		if !drop {
+			t := &publishedTable{rel: rel}
+			if sub.isPunct("(") {
+				sub.parens()
+			}
+			if sub.word("where") {
+				i, j := sub.parens()
+				t.where = unqualify(sub.text(i, j))
+			}
+			kept = append(kept, t)
+		}
+		pub.tables = kept
+	}
+	p.i = len(p.toks)
+	return nil
+}
+
+// publicationParams reads the parenthesised parameters of pub.
+func (p *parser) publicationParams(pub *publication) {
+	i, j := p.parens()
+	for _, r := range splitList(p.statement, i, j) {
+		sub := p.sub(r[0], r[1])
+		name := sub.next().val
+		sub.punct("=")
+		value := strings.ToLower(sub.next().val)
+		switch name {
+		case "publish":
+			pub.publish = map[string]bool{}
+			for _, op := range strings.Split(value, ",") {
+				pub.publish[strings.TrimSpace(op)] = true
+			}
+		case "publish_via_partition_root":
+			pub.viaRoot = value == "true" || value == "on" || value == "1"
+		}
+	}
+}
+
+func (p *parser) createSubscription() error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	sub := &subscription{name: name, enabled: true, slotName: name, streaming: "off", syncCommit: "off"}
+	if !p.word("connection") {
+		return p.errorf("expected CONNECTION")
+	}
+	sub.conninfo = p.next().val
+	if !p.word("publication") {
+		return p.errorf("expected PUBLICATION")
+	}
+	for len(sub.publications) == 0 || p.punct(",") {
+		pub, err := p.name()
+		if err != nil {
+			return err
+		}
+		sub.publications = append(sub.publications, pub)
+	}
+	if p.word("with") {
+		i, j := p.parens()
+		for _, r := range splitList(p.statement, i, j) {
+			param := p.sub(r[0], r[1])
+			name := param.next().val
+			param.punct("=")
+			t := param.next()
+			value := t.val
+			if t.kind == wordToken {
+				value = strings.ToLower(value)
+			}
+			on := value == "true" || value == "on" || value == "1"
+			switch name {
+			case "connect", "enabled":
+				sub.enabled = sub.enabled && on
+			case "slot_name":
+				if t.kind == wordToken && value == "none" {
+					value = ""
+				}
+				sub.slotName = value
+			case "binary":
+				sub.binary = on
+			case "streaming":
+				sub.streaming = value
+				if on {
+					sub.streaming = "on"
+				} else if value != "parallel" {
+					sub.streaming = "off"
+				}
+			case "synchronous_commit":
+				sub.syncCommit = value
+			}
+		}
+	}
+	p.cat.subs = append(p.cat.subs, sub)
+	return nil
+}
+
+// ==================================
+// Types
+// ==================================
+
+func (p *parser) createType() error {
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	p.cat.types[schema+"."+name] = true
+	if p.word("as") && p.isPunct("(") {
+		comp := &composite{schema: schema, name: name}
+		i, j := p.parens()
+		for _, r := range splitList(p.statement, i, j) {
+			sub := p.sub(r[0], r[1])
+			attr := &column{}
+			attr.name, err = sub.name()
+			if err != nil {
+				return err
+			}
+			attr.typ, err = sub.typeName()
+			if err != nil {
+				return err
+			}
+			comp.attrs = append(comp.attrs, attr)
+		}
+		p.cat.composites = append(p.cat.composites, comp)
+		return nil
+	}
+	if p.word("enum") {
+		e := &enum{schema: schema, name: name}
+		i, j := p.parens()
+		for _, r := range splitList(p.statement, i, j) {
+			t := p.sub(r[0], r[1]).next()
+			if t.kind != stringToken {
+				return p.errorf("expected an enum label")
+			}
+			e.labels = append(e.labels, t.val)
+		}
+		p.cat.enums = append(p.cat.enums, e)
+	}
+	return nil
+}
+
+// alterType reads the ALTER TYPE statements that add and rename enum labels.
+func (p *parser) alterType() error {
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	e := p.cat.enum(schema, name)
+	if e == nil {
+		return nil
+	}
+	switch {
+	case p.word("add", "value"):
+		p.word("if", "not", "exists")
+		label := p.next()
+		if label.kind != stringToken {
+			return p.errorf("expected an enum label")
+		}
+		if e.index(label.val) >= 0 {
+			return nil
+		}
+		pos := len(e.labels)
+		before := p.word("before")
+		if before || p.word("after") {
+			pos = e.index(p.next().val)
+			if pos < 0 {
+				return p.errorf("unknown enum label")
+			}
+			if !before {
+				pos++
+			}
+		}
+		e.labels = append(e.labels[:pos], append([]string{label.val}, e.labels[pos:]...)...)
+	case p.word("rename", "value"):
+		from := p.next()
+		if !p.word("to") {
+			return p.errorf("expected TO")
+		}
+		to := p.next()
+		i := e.index(from.val)
+		if i < 0 {
+			return p.errorf("unknown enum label")
+		}
+		e.labels[i] = to.val
+	}
+	return nil
+}
+
+func (p *parser) createDomain() error {
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	p.word("as")
+	d := &domain{schema: schema, name: name}
+	d.typ, err = p.typeName()
+	if err != nil {
+		return err
+	}
+	p.cat.domains = append(p.cat.domains, d)
+	for p.more() {
+		err = p.domainConstraint(d)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// domainConstraint reads a single DEFAULT, NULL, NOT NULL, CHECK or COLLATE clause of CREATE DOMAIN.
+func (p *parser) domainConstraint(d *domain) error {
+	name := ""
+	if p.word("constraint") {
+		var err error
+		name, err = p.name()
+		if err != nil {
+			return err
+		}
+	}
+	switch {
+	case p.word("not", "null"):
+		d.notNull = true
+	case p.word("null"):
+		d.notNull = false
+	case p.word("default"):
+		i, j := p.until(func() bool { return p.isAnyWord("not", "null", "constraint", "check", "collate") })
+		d.def = unqualify(p.text(i, j))
+	case p.word("collate"):
+		_, _, err := p.qualifiedName()
+		return err
+	case p.word("check"):
+		i, j := p.parens()
+		con := &constraint{name: name, typ: 'c', def: "CHECK (" + unqualify(p.text(i, j)) + ")"}
+		if p.word("not", "valid") {
+			con.def += " NOT VALID"
+		}
+		if con.name == "" {
+			con.name = d.name + "_check"
+		}
+		d.constraints = append(d.constraints, con)
+	default:
+		p.next()
+	}
+	return nil
+}
+
+// alterDomain reads the ALTER DOMAIN statements that change a domain's default, nullability and constraints.
+func (p *parser) alterDomain() error {
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	d := p.cat.domain(schema + "." + name)
+	if d == nil {
+		return nil
+	}
+	switch {
+	case p.word("add"):
+		return p.domainConstraint(d)
+	case p.word("set", "default"):
+		i, j := p.rest()
+		d.def = unqualify(p.text(i, j))
+	case p.word("drop", "default"):
+		d.def = ""
+	case p.word("set", "not", "null"):
+		d.notNull = true
+	case p.word("drop", "not", "null"):
+		d.notNull = false
+	case p.word("drop", "constraint"):
+		p.word("if", "exists")
+		conName, err := p.name()
+		if err != nil {
+			return err
+		}
+		for i, con := range d.constraints {
+			if con.name == conName {
+				d.constraints = append(d.constraints[:i], d.constraints[i+1:]...)
+				break
+			}
+		}
+	case p.word("validate", "constraint"):
+		conName, err := p.name()
+		if err != nil {
+			return err
+		}
+		for _, con := range d.constraints {
+			if con.name == conName {
+				con.def = strings.TrimSuffix(con.def, " NOT VALID")
+			}
+		}
+	}
+	return nil
+}
+
+// ==================================
+// Privileges
+// ==================================
+
+// grant reads GRANT or REVOKE on tables, views, sequences and their columns.
+func (p *parser) grant(revoke bool) error {
+	option := ""
+	if revoke && p.word("grant", "option", "for") {
+		// Removing only the grant option is too rare to be worth modelling.
+		return nil
+	}
+	type privilege struct {
+		chars string
+		cols  []string
+	}
+	var privs []privilege
+	i, _ := p.until(func() bool { return p.isWord("on") })
+	if !p.word("on") {
+		// Role membership
+		return nil
+	}
+	for _, r := range splitList(p.statement, i, p.i-1) {
+		sub := p.sub(r[0], r[1])
+		priv := privilege{}
+		w, err := sub.name()
+		if err != nil {
+			return err
+		}
+		if w == "all" {
+			sub.word("privileges")
+			priv.chars = "all"
+		} else {
+			priv.chars = privilegeChars[w]
+		}
+		if sub.isPunct("(") {
+			priv.cols, err = sub.names()
+			if err != nil {
+				return err
+			}
+		}
+		privs = append(privs, priv)
+	}
+	if !p.word("table") && !p.word("sequence") && !p.word("foreign", "table") && p.isAnyWord("function", "procedure",
+		"routine", "schema", "database", "all", "type", "domain", "language", "large", "foreign", "tablespace",
+		"parameter") {
+		return nil
+	}
+	var rels []*relation
+	for p.more() && !p.isWord("to") && !p.isWord("from") {
+		schema, name, err := p.qualifiedName()
+		if err != nil {
+			return err
+		}
+		if rel := p.cat.relation(schema, name); rel != nil {
+			rels = append(rels, rel)
+		}
+		p.punct(",")
+	}
+	p.next()
+	var grantees []string
+	for p.more() && !p.isWord("with") && !p.isWord("granted") && !p.isWord("cascade") && !p.isWord("restrict") {
+		p.word("group")
+		g, err := p.name()
+		if err != nil {
+			return err
+		}
+		grantees = append(grantees, g)
+		p.punct(",")
+	}
+	if p.word("with", "grant", "option") {
+		option = "*"
+	}
+	for _, rel := range rels {
+		grantor := rel.owner
+		if grantor == "" {
+			grantor = "postgres"
+		}
+		for _, priv := range privs {
+			chars := priv.chars
+			if priv.cols != nil {
+				if chars == "all" {
+					chars = "arwx"
+				}
+				for _, c := range priv.cols {
+					col := rel.column(c)
+					if col == nil {
+						continue
+					}
+					if col.acl == nil {
+						col.acl = &acl{}
+					}
+					applyPrivileges(col.acl, grantees, chars, option, grantor, revoke)
+				}
+				continue
+			}
+			if chars == "all" {
+				chars = allPrivileges[rel.kind]
+			}
+			if rel.acl == nil {
+				rel.acl = &acl{}
+				if rel.owner != "" {
+					rel.acl.grant(rel.owner, allPrivileges[rel.kind], rel.owner)
+				}
+			}
+			applyPrivileges(rel.acl, grantees, chars, option, grantor, revoke)
+		}
+	}
+	return nil
+}
+
+func applyPrivileges(a *acl, grantees []string, chars, option, grantor string, revoke bool) {
+	for _, g := range grantees {
+		if revoke {
+			a.revoke(g, chars)
+			continue
+		}
+		var privs string
+		for i := 0; i < len(chars); i++ {
+			privs += string(chars[i]) + option
+		}
+		a.grant(g, privs, grantor)
+	}
+}
+
+// ==================================
+// Comments
+// ==================================
+
+// commentTypes maps the object keywords of COMMENT ON that are compared to the object_type they are rendered as.
+var commentTypes = map[string]string{
+	"table":      "TABLE",
+	"column":     "COLUMN",
+	"view":       "VIEW",
+	"index":      "INDEX",
+	"sequence":   "SEQUENCE",
+	"function":   "FUNCTION",
+	"procedure":  "PROCEDURE",
+	"aggregate":  "AGGREGATE",
+	"constraint": "CONSTRAINT",
+	"type":       "TYPE",
+	"domain":     "DOMAIN",
+	"schema":     "SCHEMA",
+}
+
+func (p *parser) comment() error {
+	com := &comment{}
+	switch {
+	case p.word("materialized", "view"):
+		com.typ = "MATERIALIZED VIEW"
+	case p.word("foreign", "table"):
+		com.typ = "FOREIGN TABLE"
+	default:
+		com.typ = commentTypes[p.next().val]
+		if com.typ == "" {
+			return nil
+		}
+	}
+	var err error
+	switch com.typ {
+	case "SCHEMA":
+		com.name, err = p.name()
+		com.schema = com.name
+	case "COLUMN":
+		var names []string
+		for len(names) == 0 || p.punct(".") {
+			var n string
+			n, err = p.name()
+			if err != nil {
+				return err
+			}
+			names = append(names, n)
+		}
+		if len(names) == 2 {
+			names = append([]string{defaultNamespace}, names...)
+		}
+		if len(names) != 3 {
+			return p.errorf("expected a column name")
+		}
+		com.schema, com.table, com.name = names[0], names[1], names[2]
+	case "CONSTRAINT":
+		com.name, err = p.name()
+		if err != nil {
+			return err
+		}
+		if !p.word("on") {
+			return p.errorf("expected ON")
+		}
+		if p.isWord("domain") {
+			return nil
+		}
+		com.schema, com.table, err = p.qualifiedName()
+	case "FUNCTION", "PROCEDURE", "AGGREGATE":
+		com.schema, com.name, err = p.qualifiedName()
+		if err != nil {
+			return err
+		}
+		i, j := p.parens()
+		com.name += "(" + unqualify(squash(p.text(i, j))) + ")"
+	default:
+		com.schema, com.name, err = p.qualifiedName()
+	}
+	if err != nil {
+		return err
+	}
+	if !p.word("is") {
+		return p.errorf("expected IS")
+	}
+	for i, prev := range p.cat.comments {
+		if prev.typ == com.typ && prev.schema == com.schema && prev.table == com.table && prev.name == com.name {
+			p.cat.comments = append(p.cat.comments[:i], p.cat.comments[i+1:]...)
+			break
+		}
+	}
+	if p.word("null") {
+		return nil
+	}
+	t := p.next()
+	if t.kind != stringToken {
+		return p.errorf("expected a comment")
+	}
+	com.text = t.val
+	p.cat.comments = append(p.cat.comments, com)
+	return nil
+}
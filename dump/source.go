@@ -0,0 +1,1149 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package dump
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/facefunk/pgdiff"
+)
+
+// functionOptionOrder is the order in which pg_get_functiondef prints function options.
+var functionOptionOrder = []string{"WINDOW", "IMMUTABLE", "STABLE", "PARALLEL", "STRICT", "SECURITY DEFINER", "LEAKPROOF",
+	"COST", "ROWS", "SUPPORT"}
+
+// Source is a pgdiff.RowSource that produces the rows of each schema type from a plain SQL schema dump, as written by
+// pg_dump --schema-only or by hand.
+type Source struct {
+	name     string
+	dbSchema string
+	cat      *catalog
+}
+
+// NewSource parses sql, naming it name in notices and errors.
+func NewSource(name string, sql []byte, dbSchema string) (*Source, error) {
+	cat, err := parse(string(sql))
+	if err != nil {
+		return nil, pgdiff.NewError(fmt.Sprintf("%s: %s", name, err))
+	}
+	return &Source{name: name, dbSchema: dbSchema, cat: cat}, nil
+}
+
+// OpenSource reads and parses the dump in file.
+func OpenSource(file string, dbSchema string) (*Source, error) {
+	sql, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, pgdiff.NewError("reading dump: " + err.Error())
+	}
+	return NewSource(file, sql, dbSchema)
+}
+
+// Supports reports whether the dump can describe schemaType. Roles are not part of a database dump and ownership is
+// only known if the dump includes OWNER TO statements.
+func (s *Source) Supports(schemaType string) bool {
+	switch schemaType {
+	case pgdiff.RoleSchemaType:
+		return false
+	case pgdiff.OwnerSchemaType:
+		return s.cat.owners
+	}
+	return true
+}
+
+func (s *Source) Identify(num int) *pgdiff.Notice {
+	return pgdiff.NewNotice(fmt.Sprintf("-- dump%d: %s (schema %s)", num, s.name, s.dbSchema))
+}
+
+func (s *Source) Rows(schemaType string) ([]map[string]string, error) {
+	switch schemaType {
+	case pgdiff.SchemataSchemaType:
+		return s.schemataRows(), nil
+	case pgdiff.ExtensionSchemaType:
+		return s.extensionRows(), nil
+	case pgdiff.SequenceSchemaType:
+		return s.sequenceRows(), nil
+	case pgdiff.EnumSchemaType:
+		return s.enumRows(), nil
+	case pgdiff.DomainSchemaType:
+		return s.domainRows(), nil
+	case pgdiff.TypeSchemaType:
+		return s.typeRows(), nil
+	case pgdiff.ForeignDataWrapperSchemaType:
+		return s.foreignDataWrapperRows(), nil
+	case pgdiff.ServerSchemaType:
+		return s.serverRows(), nil
+	case pgdiff.UserMappingSchemaType:
+		return s.userMappingRows(), nil
+	case pgdiff.TableSchemaType:
+		return s.tableRows(), nil
+	case pgdiff.ForeignTableSchemaType:
+		return s.foreignTableRows(), nil
+	case pgdiff.ColumnSchemaType, pgdiff.TableColumnSchemaType:
+		return s.columnRows(schemaType), nil
+	case pgdiff.OperatorSchemaType:
+		return s.operatorRows(), nil
+	case pgdiff.OperatorClassSchemaType:
+		return s.operatorClassRows(), nil
+	case pgdiff.IndexSchemaType:
+		return s.indexRows(), nil
+	case pgdiff.ViewSchemaType:
+		return s.viewRows(), nil
+	case pgdiff.MatViewSchemaType:
+		return s.matViewRows(), nil
+	case pgdiff.ForeignKeySchemaType:
+		return s.foreignKeyRows(), nil
+	case pgdiff.CheckConstraintSchemaType:
+		return s.checkConstraintRows(), nil
+	case pgdiff.FunctionSchemaType:
+		return s.functionRows(), nil
+	case pgdiff.EventTriggerSchemaType:
+		return s.eventTriggerRows(), nil
+	case pgdiff.TriggerSchemaType:
+		return s.triggerRows(), nil
+	case pgdiff.RuleSchemaType:
+		return s.ruleRows(), nil
+	case pgdiff.PolicySchemaType:
+		return s.policyRows(), nil
+	case pgdiff.PublicationSchemaType:
+		return s.publicationRows(), nil
+	case pgdiff.SubscriptionSchemaType:
+		return s.subscriptionRows(), nil
+	case pgdiff.OwnerSchemaType:
+		return s.ownerRows(), nil
+	case pgdiff.GrantRelationshipSchemaType:
+		return s.grantRelationshipRows(), nil
+	case pgdiff.GrantAttributeSchemaType:
+		return s.grantAttributeRows(), nil
+	case pgdiff.CommentSchemaType:
+		return s.commentRows(), nil
+	}
+	return nil, pgdiff.NewError(fmt.Sprintf("%s cannot be read from a dump", schemaType))
+}
+
+// include reports whether objects in schema are selected, mirroring the schema filter in the catalog queries.
+func (s *Source) include(schema string) bool {
+	if s.dbSchema == "*" {
+		return !isSystemSchema(schema)
+	}
+	return schema == s.dbSchema
+}
+
+// isSystemSchema mirrors "NOT LIKE 'pg_%' AND <> 'information_schema'", where _ matches any character.
+func isSystemSchema(schema string) bool {
+	return (len(schema) >= 3 && strings.HasPrefix(schema, "pg")) || schema == "information_schema"
+}
+
+// prefix returns the schema part of a compare_name.
+func (s *Source) prefix(schema string) string {
+	if s.dbSchema == "*" {
+		return schema + "."
+	}
+	return ""
+}
+
+func null(s string) string {
+	if s == "" {
+		return "null"
+	}
+	return s
+}
+
+// jsonArray renders v as the json_agg catalog queries do, without escaping HTML characters.
+func jsonArray(v interface{}) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// optionsArray renders options as array_to_json renders them, with an empty array for none.
+func optionsArray(opts []string) string {
+	if opts == nil {
+		opts = []string{}
+	}
+	return jsonArray(opts)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "YES"
+	}
+	return "NO"
+}
+
+func (s *Source) schemataRows() []map[string]string {
+	var rows []map[string]string
+	for _, ns := range s.cat.namespaces {
+		if isSystemSchema(ns.name) {
+			continue
+		}
+		rows = append(rows, map[string]string{
+			"schema_name":                  ns.name,
+			"schema_owner":                 null(ns.owner),
+			"default_character_set_schema": "null",
+		})
+	}
+	return rows
+}
+
+func (s *Source) sequenceRows() []map[string]string {
+	var rows []map[string]string
+	for _, rel := range s.cat.relations {
+		if rel.kind != 'S' || rel.seq.identity || !s.include(rel.schema) {
+			continue
+		}
+		rows = append(rows, map[string]string{
+			"schema_name":   rel.schema,
+			"compare_name":  s.prefix(rel.schema) + rel.name,
+			"sequence_name": rel.name,
+			"data_type":     rel.seq.typ,
+			"start_value":   rel.seq.start,
+			"minimum_value": rel.seq.min,
+			"maximum_value": rel.seq.max,
+			"increment":     rel.seq.increment,
+			"cycle_option":  yesNo(rel.seq.cycle),
+		})
+	}
+	return rows
+}
+
+func (s *Source) extensionRows() []map[string]string {
+	var rows []map[string]string
+	for _, x := range s.cat.extensions {
+		if !s.include(x.schema) {
+			continue
+		}
+		rows = append(rows, map[string]string{
+			"compare_name":   x.name,
+			"extension_name": x.name,
+			"schema_name":    x.schema,
+			"version":        null(x.version),
+		})
+	}
+	return rows
+}
+
+func (s *Source) enumRows() []map[string]string {
+	var rows []map[string]string
+	for _, e := range s.cat.enums {
+		if !s.include(e.schema) {
+			continue
+		}
+		rows = append(rows, map[string]string{
+			"schema_name":  e.schema,
+			"compare_name": s.prefix(e.schema) + e.name,
+			"type_name":    e.name,
+			"labels":       jsonArray(append([]string{}, e.labels...)),
+		})
+	}
+	return rows
+}
+
+func (s *Source) domainRows() []map[string]string {
+	type jsonConstraint struct {
+		Name string `json:"name"`
+		Def  string `json:"def"`
+	}
+	var rows []map[string]string
+	for _, d := range s.cat.domains {
+		if !s.include(d.schema) {
+			continue
+		}
+		cons := []jsonConstraint{}
+		for _, con := range d.constraints {
+			cons = append(cons, jsonConstraint{con.name, con.def})
+		}
+		sort.Slice(cons, func(i, j int) bool { return cons[i].Name < cons[j].Name })
+		nullable := "YES"
+		if d.notNull {
+			nullable = "NO"
+		}
+		rows = append(rows, map[string]string{
+			"schema_name":    d.schema,
+			"compare_name":   s.prefix(d.schema) + d.name,
+			"domain_name":    d.name,
+			"data_type":      d.typ.formatTypmod(s.cat),
+			"domain_default": null(d.def),
+			"is_nullable":    nullable,
+			"constraints":    jsonArray(cons),
+		})
+	}
+	return rows
+}
+
+func (s *Source) typeRows() []map[string]string {
+	type jsonAttribute struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	}
+	var rows []map[string]string
+	for _, comp := range s.cat.composites {
+		if !s.include(comp.schema) {
+			continue
+		}
+		attrs := []jsonAttribute{}
+		for _, attr := range comp.attrs {
+			attrs = append(attrs, jsonAttribute{attr.name, attr.typ.formatTypmod(s.cat)})
+		}
+		rows = append(rows, map[string]string{
+			"schema_name":  comp.schema,
+			"compare_name": s.prefix(comp.schema) + comp.name,
+			"type_name":    comp.name,
+			"attributes":   jsonArray(attrs),
+		})
+	}
+	return rows
+}
+
+func (s *Source) tableRows() []map[string]string {
+	var rows []map[string]string
+	for _, rel := range s.cat.relations {
+		if rel.kind != 'r' || !s.include(rel.schema) {
+			continue
+		}
+		row := map[string]string{
+			"table_schema":       rel.schema,
+			"compare_name":       s.prefix(rel.schema) + rel.name,
+			"table_name":         rel.name,
+			"table_type":         "TABLE",
+			"is_insertable_into": "YES",
+			"partition_key":      null(rel.partitionKey),
+			"partition_columns":  "null",
+			"parent_schema":      "null",
+			"parent_table":       "null",
+			"partition_bound":    null(rel.bound),
+			"inherits":           "null",
+		}
+		if len(rel.partitionCols) > 0 {
+			var cols []string
+			for _, col := range rel.columns {
+				if rel.partitionedBy(col.name) {
+					def := quoteIdent(col.name) + " " + col.typ.formatTypmod(s.cat)
+					if col.notNull {
+						def += " NOT NULL"
+					}
+					cols = append(cols, def)
+				}
+			}
+			row["partition_columns"] = strings.Join(cols, ", ")
+		}
+		if rel.parent != nil {
+			row["parent_schema"], row["parent_table"] = rel.parent.schema, rel.parent.name
+		}
+		if len(rel.inherits) > 0 {
+			names := make([]string, len(rel.inherits))
+			for i, parent := range rel.inherits {
+				names[i] = parent.name
+				if parent.schema != rel.schema {
+					names[i] = parent.schema + "." + parent.name
+				}
+			}
+			row["inherits"] = strings.Join(names, ", ")
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func (s *Source) foreignDataWrapperRows() []map[string]string {
+	var rows []map[string]string
+	for _, w := range s.cat.wrappers {
+		rows = append(rows, map[string]string{
+			"compare_name": w.name,
+			"fdw_name":     w.name,
+			"handler":      null(w.handler),
+			"validator":    null(w.validator),
+			"options":      optionsArray(w.options),
+		})
+	}
+	return rows
+}
+
+func (s *Source) serverRows() []map[string]string {
+	var rows []map[string]string
+	for _, srv := range s.cat.servers {
+		rows = append(rows, map[string]string{
+			"compare_name":   srv.name,
+			"server_name":    srv.name,
+			"fdw_name":       srv.fdw,
+			"server_type":    null(srv.typ),
+			"server_version": null(srv.version),
+			"options":        optionsArray(srv.options),
+		})
+	}
+	return rows
+}
+
+// userMappingRows masks secret option values as the database source does.
+func (s *Source) userMappingRows() []map[string]string {
+	var rows []map[string]string
+	for _, um := range s.cat.mappings {
+		opts := pgdiff.MaskOptions(um.options)
+		rows = append(rows, map[string]string{
+			"compare_name": um.server + "." + um.user,
+			"server_name":  um.server,
+			"user_name":    um.user,
+			"options":      optionsArray(opts),
+		})
+	}
+	return rows
+}
+
+func (s *Source) foreignTableRows() []map[string]string {
+	type foreignColumn struct {
+		Name    string   `json:"name"`
+		Type    string   `json:"type"`
+		NotNull bool     `json:"not_null"`
+		Options []string `json:"options"`
+	}
+	var rows []map[string]string
+	for _, rel := range s.cat.relations {
+		if rel.kind != 'f' || !s.include(rel.schema) {
+			continue
+		}
+		cols := make([]foreignColumn, len(rel.columns))
+		for i, col := range rel.columns {
+			opts := col.fdwOptions
+			if opts == nil {
+				opts = []string{}
+			}
+			cols[i] = foreignColumn{Name: col.name, Type: col.typ.formatTypmod(s.cat), NotNull: col.notNull, Options: opts}
+		}
+		rows = append(rows, map[string]string{
+			"schema_name":  rel.schema,
+			"compare_name": s.prefix(rel.schema) + rel.name,
+			"table_name":   rel.name,
+			"server_name":  rel.server,
+			"options":      optionsArray(rel.fdwOptions),
+			"columns":      jsonArray(cols),
+		})
+	}
+	return rows
+}
+
+// columnRows returns the columns of every table. View columns cannot be known without planning the view's query, so
+// COLUMN rows only differ from TABLE_COLUMN rows in their compare_name.
+func (s *Source) columnRows(schemaType string) []map[string]string {
+	var rows []map[string]string
+	for _, rel := range s.cat.relations {
+		if rel.kind != 'r' || rel.parent != nil || !s.include(rel.schema) {
+			continue
+		}
+		for i, col := range rel.columns {
+			compareName := s.prefix(rel.schema) + rel.name + "." + col.name
+			if schemaType == pgdiff.ColumnSchemaType {
+				compareName = fmt.Sprintf("%s%s.%05d%s", s.prefix(rel.schema), rel.name, i+1, col.name)
+			}
+			dataType, udtName, maxLength := col.typ.dataType(s.cat)
+			row := map[string]string{
+				"table_schema":             rel.schema,
+				"compare_name":             compareName,
+				"table_name":               rel.name,
+				"column_name":              col.name,
+				"data_type":                dataType,
+				"is_nullable":              yesNo(!col.notNull),
+				"column_default":           null(col.def),
+				"character_maximum_length": maxLength,
+				"is_partition_key":         yesNo(rel.partitionedBy(col.name)),
+				"is_inherited":             yesNo(col.inherited),
+			}
+			if schemaType == pgdiff.ColumnSchemaType {
+				row["is_identity"] = yesNo(col.identity != "")
+				row["identity_generation"] = null(col.identity)
+				row["array_type"] = udtName[1:]
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+func (s *Source) operatorRows() []map[string]string {
+	var rows []map[string]string
+	for _, op := range s.cat.operators {
+		if !s.include(op.schema) {
+			continue
+		}
+		rows = append(rows, map[string]string{
+			"schema_name":   op.schema,
+			"compare_name":  s.prefix(op.schema) + op.name + "(" + op.left + ", " + op.right + ")",
+			"operator_name": op.name,
+			"left_type":     op.left,
+			"right_type":    op.right,
+			"function":      op.function,
+			"commutator":    op.commutator,
+			"negator":       op.negator,
+			"restrict":      op.restrict,
+			"join":          op.join,
+			"hashes":        yesNo(op.hashes),
+			"merges":        yesNo(op.merges),
+		})
+	}
+	return rows
+}
+
+func (s *Source) operatorClassRows() []map[string]string {
+	var rows []map[string]string
+	for _, f := range s.cat.opFamilies {
+		if !s.include(f.schema) {
+			continue
+		}
+		rows = append(rows, map[string]string{
+			"schema_name":   f.schema,
+			"compare_name":  s.prefix(f.schema) + f.name + " USING " + f.method,
+			"family_schema": f.schema,
+			"family_name":   f.name,
+			"access_method": f.method,
+			"class_name":    "null",
+			"is_default":    "null",
+			"type":          "null",
+			"storage":       "null",
+			"members":       membersArray(f.members),
+		})
+	}
+	for _, c := range s.cat.opClasses {
+		if !s.include(c.schema) {
+			continue
+		}
+		rows = append(rows, map[string]string{
+			"schema_name":   c.schema,
+			"compare_name":  s.prefix(c.family.schema) + c.family.name + " USING " + c.method + " CLASS " + s.prefix(c.schema) + c.name,
+			"family_schema": c.family.schema,
+			"family_name":   c.family.name,
+			"access_method": c.method,
+			"class_name":    c.name,
+			"is_default":    yesNo(c.isDefault),
+			"type":          c.typ,
+			"storage":       c.storage,
+			"members":       membersArray(c.members),
+		})
+	}
+	return rows
+}
+
+// membersArray renders the members of an operator class or family in the order the catalog query aggregates them:
+// operators before functions, then by strategy or support number.
+func membersArray(members []string) string {
+	sorted := append([]string{}, members...)
+	key := func(member string) (int, int) {
+		parts := strings.SplitN(member, " ", 3)
+		kind := 1
+		if parts[0] == "FUNCTION" {
+			kind = 2
+		}
+		num := 0
+		if len(parts) > 1 {
+			num, _ = strconv.Atoi(parts[1])
+		}
+		return kind, num
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		kind1, num1 := key(sorted[i])
+		kind2, num2 := key(sorted[j])
+		if kind1 != kind2 {
+			return kind1 < kind2
+		}
+		if num1 != num2 {
+			return num1 < num2
+		}
+		return sorted[i] < sorted[j]
+	})
+	return jsonArray(sorted)
+}
+
+func (s *Source) indexRows() []map[string]string {
+	var rows []map[string]string
+	for _, idx := range s.cat.indexes {
+		if idx.attached || !s.include(idx.rel.schema) {
+			continue
+		}
+		conDef, typ := "null", "null"
+		if idx.con != nil {
+			conDef, typ = idx.con.def, string(idx.con.typ)
+		}
+		rows = append(rows, map[string]string{
+			"compare_name":   s.prefix(idx.rel.schema) + idx.rel.name + "." + idx.name,
+			"schema_name":    idx.rel.schema,
+			"table_name":     idx.rel.name,
+			"index_name":     idx.name,
+			"pk":             fmt.Sprintf("%t", idx.primary),
+			"uq":             fmt.Sprintf("%t", idx.unique),
+			"index_def":      idx.def(true),
+			"constraint_def": conDef,
+			"typ":            typ,
+		})
+	}
+	return rows
+}
+
+// viewRows returns every view, as pg_views is not filtered by schema.
+func (s *Source) viewRows() []map[string]string {
+	var rows []map[string]string
+	for _, rel := range s.cat.relations {
+		if rel.kind != 'v' {
+			continue
+		}
+		rows = append(rows, map[string]string{
+			"viewname":   rel.schema + "." + rel.name,
+			"definition": rel.definition,
+		})
+	}
+	return rows
+}
+
+// matViewRows returns every materialized view along with its indexes, which pg_indexes renders schema qualified.
+func (s *Source) matViewRows() []map[string]string {
+	var rows []map[string]string
+	for _, rel := range s.cat.relations {
+		if rel.kind != 'm' {
+			continue
+		}
+		var defs []string
+		for _, idx := range s.cat.indexes {
+			if idx.rel == rel {
+				defs = append(defs, idx.def(false))
+			}
+		}
+		sort.Strings(defs)
+		indexDef := ""
+		if len(defs) > 0 {
+			indexDef = strings.Join(defs, ";\n\n") + ";"
+		}
+		rows = append(rows, map[string]string{
+			"matviewname": rel.schema + "." + rel.name,
+			"definition":  rel.definition,
+			"indexdef":    indexDef,
+		})
+	}
+	return rows
+}
+
+func (s *Source) foreignKeyRows() []map[string]string {
+	var rows []map[string]string
+	for _, rel := range s.cat.relations {
+		if !s.include(rel.schema) {
+			continue
+		}
+		for _, con := range rel.constraints {
+			if con.typ != 'f' {
+				continue
+			}
+			rows = append(rows, map[string]string{
+				"compare_name":   s.prefix(rel.schema) + rel.name + "." + con.name,
+				"schema_name":    rel.schema,
+				"table_name":     rel.name,
+				"fk_name":        con.name,
+				"constraint_def": con.def,
+			})
+		}
+	}
+	return rows
+}
+
+func (s *Source) checkConstraintRows() []map[string]string {
+	var rows []map[string]string
+	for _, rel := range s.cat.relations {
+		if !s.include(rel.schema) {
+			continue
+		}
+		for _, con := range rel.constraints {
+			if con.typ != 'c' {
+				continue
+			}
+			rows = append(rows, map[string]string{
+				"compare_name":    s.prefix(rel.schema) + rel.name + "." + con.name,
+				"schema_name":     rel.schema,
+				"table_name":      rel.name,
+				"constraint_name": con.name,
+				"constraint_def":  con.def,
+			})
+		}
+	}
+	return rows
+}
+
+// functionRows returns the functions, procedures and aggregates of the dump.
+func (s *Source) functionRows() []map[string]string {
+	var rows []map[string]string
+	for _, f := range s.cat.functions {
+		if !s.include(f.schema) {
+			continue
+		}
+		returnType := f.resultType()
+		def := s.functionDef(f)
+		if f.kind == "AGGREGATE" {
+			returnType = null(f.returnType)
+			def = s.aggregateDef(f)
+		}
+		argTypes := strings.Join(s.argTypes(f), ", ")
+		rows = append(rows, map[string]string{
+			"schema_name":   f.schema,
+			"compare_name":  s.prefix(f.schema) + f.name + "(" + argTypes + ")",
+			"function_name": f.name,
+			"fancy":         s.signature(f),
+			"arg_types":     argTypes,
+			"return_type":   returnType,
+			"result":        s.result(f),
+			"kind":          f.kind,
+			"identity_args": s.identityArgs(f),
+			"definition":    def,
+		})
+	}
+	return rows
+}
+
+// argTypes returns the types of the arguments that identify f, as pg_proc.proargtypes holds them. Output arguments
+// identify procedures, but not functions.
+func (s *Source) argTypes(f *function) []string {
+	var types []string
+	for _, arg := range f.args {
+		switch {
+		case arg.mode == "", arg.mode == "inout", arg.mode == "variadic", arg.mode == "out" && f.kind == "PROCEDURE":
+			types = append(types, arg.typ.format(s.cat))
+		}
+	}
+	return types
+}
+
+// signature renders f as a regprocedure.
+func (s *Source) signature(f *function) string {
+	name := quoteIdent(f.name)
+	if f.schema != defaultNamespace {
+		name = quoteIdent(f.schema) + "." + name
+	}
+	return name + "(" + strings.Join(s.argTypes(f), ",") + ")"
+}
+
+// result renders what f returns as pg_get_function_result would, or as null for procedures and aggregates, whose
+// results the catalog query leaves out.
+func (s *Source) result(f *function) string {
+	if f.kind == "PROCEDURE" || f.kind == "AGGREGATE" {
+		return "null"
+	}
+	var table []string
+	for _, arg := range f.args {
+		switch arg.mode {
+		case "return":
+			return f.returns + arg.typ.format(s.cat)
+		case "table":
+			table = append(table, quoteIdent(arg.name)+" "+arg.typ.format(s.cat))
+		}
+	}
+	if table != nil {
+		return "TABLE(" + strings.Join(table, ", ") + ")"
+	}
+	return "record"
+}
+
+// resultType returns the pg_type name of the type f returns. A procedure returns a record only if it has output
+// arguments.
+func (f *function) resultType() string {
+	if f.returnType != "" {
+		return f.returnType
+	}
+	if f.kind == "PROCEDURE" {
+		for _, arg := range f.args {
+			if arg.mode == "out" || arg.mode == "inout" {
+				return "record"
+			}
+		}
+		return "void"
+	}
+	return "record"
+}
+
+// identityArgs renders the arguments of f as pg_get_function_identity_arguments would, or as * for an aggregate
+// without any.
+func (s *Source) identityArgs(f *function) string {
+	if f.kind == "AGGREGATE" && len(f.args) == 0 {
+		return "*"
+	}
+	args := ""
+	for i, arg := range f.args {
+		if arg.mode == "return" || arg.mode == "table" {
+			continue
+		}
+		str := arg.typ.format(s.cat)
+		if arg.name != "" {
+			str = quoteIdent(arg.name) + " " + str
+		}
+		if arg.mode != "" {
+			str = strings.ToUpper(arg.mode) + " " + str
+		}
+		switch {
+		case arg.orderBy && i > 0:
+			str = " ORDER BY " + str
+		case arg.orderBy:
+			str = "ORDER BY " + str
+		case i > 0:
+			str = ", " + str
+		}
+		args += str
+	}
+	return args
+}
+
+// aggregateDef renders the aggregate f as the catalog query does.
+func (s *Source) aggregateDef(f *function) string {
+	args := s.identityArgs(f)
+	params := make([]string, len(f.aggregate))
+	for i, param := range f.aggregate {
+		params[i] = strings.ToUpper(param.key)
+		switch {
+		case param.typ != nil:
+			params[i] += " = " + param.typ.format(s.cat)
+		case param.value != "":
+			params[i] += " = " + param.value
+		}
+	}
+	return "CREATE OR REPLACE AGGREGATE " + quoteIdent(f.schema) + "." + quoteIdent(f.name) + "(" + args + ") (\n    " +
+		strings.Join(params, ",\n    ") + "\n)"
+}
+
+// functionDef renders f as pg_get_functiondef would.
+func (s *Source) functionDef(f *function) string {
+	var args []string
+	for _, arg := range f.args {
+		str := arg.typ.format(s.cat)
+		if arg.name != "" {
+			str = quoteIdent(arg.name) + " " + str
+		}
+		switch arg.mode {
+		case "return", "table":
+			continue
+		case "out", "inout", "variadic":
+			str = strings.ToUpper(arg.mode) + " " + str
+		}
+		if arg.def != "" {
+			str += " DEFAULT " + arg.def
+		}
+		args = append(args, str)
+	}
+
+	var b strings.Builder
+	keyword := "FUNCTION"
+	if f.kind == "PROCEDURE" {
+		keyword = "PROCEDURE"
+	}
+	b.WriteString("CREATE OR REPLACE " + keyword + " " + quoteIdent(f.schema) + "." + quoteIdent(f.name) + "(" +
+		strings.Join(args, ", ") + ")\n")
+	if f.kind != "PROCEDURE" {
+		b.WriteString(" RETURNS " + s.result(f) + "\n")
+	}
+	b.WriteString(" LANGUAGE " + quoteIdent(f.language) + "\n")
+
+	defaultCost := "100"
+	if f.language == "c" {
+		defaultCost = "1"
+	}
+	var opts string
+	for _, o := range functionOptionOrder {
+		for _, opt := range f.options {
+			if opt != o && !strings.HasPrefix(opt, o+" ") {
+				continue
+			}
+			switch opt {
+			case "PARALLEL UNSAFE", "COST " + defaultCost, "ROWS 0", "ROWS 1000":
+				continue
+			}
+			opts += " " + opt
+		}
+	}
+	if opts != "" {
+		b.WriteString(opts + "\n")
+	}
+	for _, c := range f.config {
+		b.WriteString(" " + c + "\n")
+	}
+
+	switch {
+	case f.sqlBody != "":
+		b.WriteString(f.sqlBody)
+	case f.language == "c":
+		b.WriteString("AS '" + strings.Replace(f.probin, "'", "''", -1) + "', '" +
+			strings.Replace(f.body, "'", "''", -1) + "'")
+	default:
+		tag := "$" + strings.ToLower(keyword) + "$"
+		for strings.Contains(f.body, tag) {
+			tag = tag[:len(tag)-1] + "x$"
+		}
+		b.WriteString("AS " + tag + f.body + tag)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func (s *Source) eventTriggerRows() []map[string]string {
+	var rows []map[string]string
+	for _, t := range s.cat.evtTrigs {
+		tags := make([]string, len(t.tags))
+		copy(tags, t.tags)
+		sort.Strings(tags)
+		for i, tag := range tags {
+			tags[i] = "'" + strings.Replace(tag, "'", "''", -1) + "'"
+		}
+		rows = append(rows, map[string]string{
+			"compare_name": t.name,
+			"trigger_name": t.name,
+			"event":        t.event,
+			"tags":         null(strings.Join(tags, ", ")),
+			"function":     t.function,
+			"enabled":      t.enabled,
+		})
+	}
+	return rows
+}
+
+func (s *Source) triggerRows() []map[string]string {
+	var rows []map[string]string
+	for _, t := range s.cat.triggers {
+		if !s.include(t.rel.schema) {
+			continue
+		}
+		rows = append(rows, map[string]string{
+			"schema_name":  t.rel.schema,
+			"compare_name": s.prefix(t.rel.schema) + t.rel.name + "." + t.name,
+			"table_name":   t.rel.name,
+			"trigger_name": t.name,
+			"trigger_def":  t.def,
+			"enabled":      t.enabled,
+		})
+	}
+	return rows
+}
+
+func (s *Source) ruleRows() []map[string]string {
+	var rows []map[string]string
+	for _, r := range s.cat.rules {
+		if !s.include(r.rel.schema) {
+			continue
+		}
+		rows = append(rows, map[string]string{
+			"schema_name":  r.rel.schema,
+			"compare_name": s.prefix(r.rel.schema) + r.rel.name + "." + r.name,
+			"table_name":   r.rel.name,
+			"rule_name":    r.name,
+			"rule_def":     r.def,
+		})
+	}
+	return rows
+}
+
+func (s *Source) policyRows() []map[string]string {
+	var rows []map[string]string
+	for _, rel := range s.cat.relations {
+		if !(rel.rowSecurity || rel.forceRowSecurity) || !s.include(rel.schema) {
+			continue
+		}
+		rows = append(rows, map[string]string{
+			"schema_name":        rel.schema,
+			"compare_name":       s.prefix(rel.schema) + rel.name,
+			"table_name":         rel.name,
+			"policy_name":        "null",
+			"row_security":       yesNo(rel.rowSecurity),
+			"force_row_security": yesNo(rel.forceRowSecurity),
+			"command":            "null",
+			"permissive":         "null",
+			"roles":              "null",
+			"using_expr":         "null",
+			"check_expr":         "null",
+		})
+	}
+	for _, pol := range s.cat.policies {
+		if !s.include(pol.rel.schema) {
+			continue
+		}
+		permissive := "PERMISSIVE"
+		if pol.restrictive {
+			permissive = "RESTRICTIVE"
+		}
+		roles := "PUBLIC"
+		if len(pol.roles) > 0 {
+			quoted := make([]string, len(pol.roles))
+			for i, role := range pol.roles {
+				quoted[i] = quoteIdent(role)
+			}
+			sort.Strings(quoted)
+			roles = strings.Join(quoted, ", ")
+		}
+		rows = append(rows, map[string]string{
+			"schema_name":        pol.rel.schema,
+			"compare_name":       s.prefix(pol.rel.schema) + pol.rel.name + "." + pol.name,
+			"table_name":         pol.rel.name,
+			"policy_name":        pol.name,
+			"row_security":       "null",
+			"force_row_security": "null",
+			"command":            pol.command,
+			"permissive":         permissive,
+			"roles":              roles,
+			"using_expr":         null(pol.using),
+			"check_expr":         null(pol.check),
+		})
+	}
+	return rows
+}
+
+func (s *Source) publicationRows() []map[string]string {
+	type publishedTable struct {
+		Name  string  `json:"name"`
+		Where *string `json:"where"`
+	}
+	var rows []map[string]string
+	for _, pub := range s.cat.pubs {
+		var ops []string
+		for _, op := range []string{"insert", "update", "delete", "truncate"} {
+			if pub.publish[op] {
+				ops = append(ops, op)
+			}
+		}
+		tables := []publishedTable{}
+		for _, t := range pub.tables {
+			pt := publishedTable{Name: quoteIdent(t.rel.schema) + "." + quoteIdent(t.rel.name)}
+			if t.where != "" {
+				where := t.where
+				pt.Where = &where
+			}
+			tables = append(tables, pt)
+		}
+		sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+		schemas := make([]string, len(pub.schemas))
+		for i, schema := range pub.schemas {
+			schemas[i] = quoteIdent(schema)
+		}
+		sort.Strings(schemas)
+		rows = append(rows, map[string]string{
+			"compare_name": pub.name,
+			"pub_name":     pub.name,
+			"all_tables":   yesNo(pub.allTables),
+			"publish":      strings.Join(ops, ", "),
+			"via_root":     yesNo(pub.viaRoot),
+			"tables":       jsonArray(tables),
+			"schemas":      null(strings.Join(schemas, ", ")),
+		})
+	}
+	return rows
+}
+
+// subscriptionRows masks the passwords in connection strings as the database source does.
+func (s *Source) subscriptionRows() []map[string]string {
+	var rows []map[string]string
+	for _, sub := range s.cat.subs {
+		pubs := make([]string, len(sub.publications))
+		for i, pub := range sub.publications {
+			pubs[i] = quoteIdent(pub)
+		}
+		sort.Strings(pubs)
+		rows = append(rows, map[string]string{
+			"compare_name":       sub.name,
+			"sub_name":           sub.name,
+			"enabled":            yesNo(sub.enabled),
+			"conninfo":           pgdiff.MaskConninfo(sub.conninfo),
+			"publications":       strings.Join(pubs, ", "),
+			"slot_name":          null(sub.slotName),
+			"binary":             fmt.Sprint(sub.binary),
+			"streaming":          sub.streaming,
+			"synchronous_commit": sub.syncCommit,
+		})
+	}
+	return rows
+}
+
+func (s *Source) commentRows() []map[string]string {
+	var rows []map[string]string
+	for _, com := range s.cat.comments {
+		if !s.include(com.schema) {
+			continue
+		}
+		compare := com.typ + " " + s.prefix(com.schema)
+		table := "null"
+		switch {
+		case com.typ == "SCHEMA":
+			compare = strings.TrimSuffix(strings.TrimSuffix(compare, "."), " ")
+		case com.table != "":
+			table = com.table
+			compare += com.table + "." + com.name
+		default:
+			compare += com.name
+		}
+		rows = append(rows, map[string]string{
+			"schema_name":  com.schema,
+			"compare_name": compare,
+			"object_type":  com.typ,
+			"table_name":   table,
+			"object_name":  com.name,
+			"comment":      com.text,
+		})
+	}
+	return rows
+}
+
+func (s *Source) ownerRows() []map[string]string {
+	types := map[byte]string{'r': "TABLE", 'S': "SEQUENCE", 'v': "VIEW"}
+	var rows []map[string]string
+	for _, rel := range s.cat.relations {
+		if types[rel.kind] == "" || !s.include(rel.schema) {
+			continue
+		}
+		rows = append(rows, map[string]string{
+			"schema_name":       rel.schema,
+			"compare_name":      s.prefix(rel.schema) + rel.name + "." + rel.name,
+			"relationship_name": rel.name,
+			"owner":             null(rel.owner),
+			"type":              types[rel.kind],
+		})
+	}
+	return rows
+}
+
+var grantTypes = map[byte]string{'r': "TABLE", 'v': "VIEW", 'S': "SEQUENCE", 'f': "FOREIGN TABLE"}
+
+func (s *Source) grantRelationshipRows() []map[string]string {
+	var rows []map[string]string
+	for _, rel := range s.cat.relations {
+		if grantTypes[rel.kind] == "" || rel.acl == nil || !s.include(rel.schema) {
+			continue
+		}
+		for _, item := range rel.acl.strings() {
+			rows = append(rows, map[string]string{
+				"schema_name":       rel.schema,
+				"compare_name":      s.prefix(rel.schema) + string(rel.kind) + "." + rel.name,
+				"type":              grantTypes[rel.kind],
+				"relationship_name": rel.name,
+				"relationship_acl":  item,
+			})
+		}
+	}
+	return rows
+}
+
+func (s *Source) grantAttributeRows() []map[string]string {
+	var rows []map[string]string
+	for _, rel := range s.cat.relations {
+		if rel.kind == 'S' || grantTypes[rel.kind] == "" || !s.include(rel.schema) {
+			continue
+		}
+		for _, col := range rel.columns {
+			if col.acl == nil {
+				continue
+			}
+			for _, item := range col.acl.strings() {
+				rows = append(rows, map[string]string{
+					"schema_name":       rel.schema,
+					"compare_name":      s.prefix(rel.schema) + string(rel.kind) + "." + rel.name + "." + col.name,
+					"type":              grantTypes[rel.kind],
+					"relationship_name": rel.name,
+					"attribute_name":    col.name,
+					"attribute_acl":     item,
+				})
+			}
+		}
+	}
+	return rows
+}
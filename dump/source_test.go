@@ -0,0 +1,765 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package dump
+
+import (
+	"testing"
+
+	"github.com/facefunk/pgdiff"
+	"github.com/stretchr/testify/assert"
+)
+
+const testDump = `--
+-- PostgreSQL database dump
+--
+
+\restrict abc123
+
+SET statement_timeout = 0;
+SELECT pg_catalog.set_config('search_path', '', false);
+
+CREATE SCHEMA s1;
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp" WITH SCHEMA s1;
+COMMENT ON EXTENSION "uuid-ossp" IS 'generate universally unique identifiers (UUIDs)';
+ALTER SCHEMA s1 OWNER TO u1;
+
+CREATE TYPE s1.mood AS ENUM (
+    'sad',
+    'happy'
+);
+ALTER TYPE s1.mood ADD VALUE 'ok' BEFORE 'happy';
+ALTER TYPE s1.mood OWNER TO u1;
+
+CREATE TYPE public.pair AS (
+    a integer,
+    b character varying(10),
+    at timestamp(3) with time zone
+);
+
+CREATE DOMAIN public.positive AS numeric(10,2) DEFAULT 1 NOT NULL
+	CONSTRAINT positive_check CHECK ((VALUE > (0)::numeric));
+ALTER DOMAIN public.positive ADD CONSTRAINT positive_small CHECK ((VALUE < (100)::numeric)) NOT VALID;
+
+CREATE FUNCTION public.touch() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    NEW.updated := now();
+    RETURN NEW;
+END;
+$$;
+ALTER FUNCTION public.touch() OWNER TO u1;
+
+CREATE FUNCTION s1.add(a integer, b integer DEFAULT 1) RETURNS integer
+    LANGUAGE sql IMMUTABLE STRICT
+    AS $$ SELECT a + b $$;
+
+CREATE TABLE public.parent (
+    id integer NOT NULL,
+    name character varying(50) DEFAULT 'x'::character varying,
+    tags text[],
+    updated timestamp with time zone
+);
+ALTER TABLE public.parent OWNER TO u1;
+
+CREATE SEQUENCE public.parent_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+ALTER SEQUENCE public.parent_id_seq OWNED BY public.parent.id;
+
+CREATE TABLE s1.child (
+    id bigint NOT NULL,
+    parent_id integer,
+    CONSTRAINT child_id_check CHECK ((id > 0))
+);
+ALTER TABLE s1.child ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME s1.child_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+CREATE VIEW public.names AS
+ SELECT parent.name
+   FROM public.parent;
+
+CREATE MATERIALIZED VIEW public.counts AS
+ SELECT count(*) AS count
+   FROM public.parent
+  WITH NO DATA;
+
+ALTER TABLE ONLY public.parent ALTER COLUMN id SET DEFAULT nextval('public.parent_id_seq'::regclass);
+ALTER TABLE ONLY public.parent
+    ADD CONSTRAINT parent_pkey PRIMARY KEY (id);
+CREATE UNIQUE INDEX parent_name_idx ON public.parent USING btree (name) WHERE (name IS NOT NULL);
+CREATE INDEX counts_idx ON public.counts USING btree (count);
+CREATE TRIGGER parent_touch BEFORE UPDATE ON public.parent FOR EACH ROW EXECUTE FUNCTION public.touch();
+CREATE POLICY "own rows" ON public.parent FOR UPDATE TO u2, u1 USING (((name)::text = CURRENT_USER)) WITH CHECK ((id > 0));
+ALTER TABLE public.parent ENABLE ROW LEVEL SECURITY;
+COMMENT ON SCHEMA s1 IS 'Second schema';
+COMMENT ON TABLE public.parent IS 'Parents';
+COMMENT ON COLUMN public.parent.name IS E'It''s the \\name';
+COMMENT ON CONSTRAINT child_id_check ON s1.child IS 'Positive ids';
+COMMENT ON FUNCTION s1.add(a integer, b integer) IS 'Adds';
+COMMENT ON INDEX public.parent_name_idx IS 'Old';
+COMMENT ON INDEX public.parent_name_idx IS NULL;
+ALTER TABLE ONLY s1.child
+    ADD CONSTRAINT child_parent_fk FOREIGN KEY (parent_id) REFERENCES public.parent(id) ON DELETE CASCADE;
+
+GRANT SELECT ON TABLE public.parent TO u2;
+GRANT SELECT(name),UPDATE(name) ON TABLE public.parent TO PUBLIC;
+`
+
+func testSource(t *testing.T, schema string) *Source {
+	s, err := NewSource("test.sql", []byte(testDump), schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func rows(t *testing.T, s *Source, schemaType string) []map[string]string {
+	r, err := s.Rows(schemaType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestSchemata(t *testing.T) {
+	r := rows(t, testSource(t, "*"), pgdiff.SchemataSchemaType)
+	assert.Len(t, r, 2)
+	assert.Equal(t, "s1", r[1]["schema_name"])
+	assert.Equal(t, "u1", r[1]["schema_owner"])
+}
+
+func TestExtension(t *testing.T) {
+	r := rows(t, testSource(t, "*"), pgdiff.ExtensionSchemaType)
+	assert.Equal(t, []map[string]string{{
+		"compare_name":   "uuid-ossp",
+		"extension_name": "uuid-ossp",
+		"schema_name":    "s1",
+		"version":        "null",
+	}}, r)
+	assert.Empty(t, rows(t, testSource(t, "public"), pgdiff.ExtensionSchemaType))
+}
+
+func TestEnum(t *testing.T) {
+	r := rows(t, testSource(t, "*"), pgdiff.EnumSchemaType)
+	assert.Equal(t, []map[string]string{{
+		"schema_name":  "s1",
+		"compare_name": "s1.mood",
+		"type_name":    "mood",
+		"labels":       `["sad","ok","happy"]`,
+	}}, r)
+}
+
+func TestType(t *testing.T) {
+	r := rows(t, testSource(t, "*"), pgdiff.TypeSchemaType)
+	assert.Equal(t, []map[string]string{{
+		"schema_name":  "public",
+		"compare_name": "public.pair",
+		"type_name":    "pair",
+		"attributes": `[{"name":"a","type":"integer"},{"name":"b","type":"character varying(10)"},` +
+			`{"name":"at","type":"timestamp(3) with time zone"}]`,
+	}}, r)
+}
+
+func TestDomain(t *testing.T) {
+	r := rows(t, testSource(t, "public"), pgdiff.DomainSchemaType)
+	assert.Equal(t, []map[string]string{{
+		"schema_name":    "public",
+		"compare_name":   "positive",
+		"domain_name":    "positive",
+		"data_type":      "numeric(10,2)",
+		"domain_default": "1",
+		"is_nullable":    "NO",
+		"constraints": `[{"name":"positive_check","def":"CHECK ((VALUE > (0)::numeric))"},` +
+			`{"name":"positive_small","def":"CHECK ((VALUE < (100)::numeric)) NOT VALID"}]`,
+	}}, r)
+}
+
+func TestSequence(t *testing.T) {
+	r := rows(t, testSource(t, "*"), pgdiff.SequenceSchemaType)
+	assert.Equal(t, []map[string]string{{
+		"schema_name":   "public",
+		"compare_name":  "public.parent_id_seq",
+		"sequence_name": "parent_id_seq",
+		"data_type":     "integer",
+		"start_value":   "1",
+		"minimum_value": "1",
+		"maximum_value": "2147483647",
+		"increment":     "1",
+		"cycle_option":  "NO",
+	}}, r)
+}
+
+func TestColumn(t *testing.T) {
+	s := testSource(t, "public")
+	r := rows(t, s, pgdiff.ColumnSchemaType)
+	assert.Len(t, r, 4)
+	assert.Equal(t, "parent.00001id", r[0]["compare_name"])
+	assert.Equal(t, "NO", r[0]["is_nullable"])
+	assert.Equal(t, "nextval('parent_id_seq'::regclass)", r[0]["column_default"])
+	assert.Equal(t, "character varying", r[1]["data_type"])
+	assert.Equal(t, "50", r[1]["character_maximum_length"])
+	assert.Equal(t, "'x'::character varying", r[1]["column_default"])
+	assert.Equal(t, "ARRAY", r[2]["data_type"])
+	assert.Equal(t, "text", r[2]["array_type"])
+
+	r = rows(t, testSource(t, "s1"), pgdiff.ColumnSchemaType)
+	assert.Equal(t, "YES", r[0]["is_identity"])
+	assert.Equal(t, "ALWAYS", r[0]["identity_generation"])
+	assert.Equal(t, "null", r[0]["column_default"])
+}
+
+func TestPartitions(t *testing.T) {
+	s, err := NewSource("partitions.sql", []byte(`
+CREATE TABLE public.events (
+    id integer NOT NULL,
+    created timestamp(3) with time zone NOT NULL
+)
+PARTITION BY RANGE (created);
+CREATE TABLE public.events_2020 (
+    id integer NOT NULL,
+    created timestamp(3) with time zone NOT NULL
+);
+CREATE TABLE public.events_default PARTITION OF public.events DEFAULT;
+ALTER TABLE ONLY public.events ATTACH PARTITION public.events_2020 FOR VALUES FROM ('2020-01-01 00:00:00+00') TO ('2021-01-01 00:00:00+00');
+CREATE INDEX events_created_idx ON ONLY public.events USING btree (created);
+CREATE INDEX events_2020_created_idx ON public.events_2020 USING btree (created);
+ALTER INDEX public.events_created_idx ATTACH PARTITION public.events_2020_created_idx;
+`), "public")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rows(t, s, pgdiff.TableSchemaType)
+	if assert.Len(t, r, 3) {
+		assert.Equal(t, "RANGE (created)", r[0]["partition_key"])
+		assert.Equal(t, "created timestamp(3) with time zone NOT NULL", r[0]["partition_columns"])
+		assert.Equal(t, "null", r[0]["parent_table"])
+		assert.Equal(t, "events", r[1]["parent_table"])
+		assert.Equal(t, "FOR VALUES FROM ('2020-01-01 00:00:00+00') TO ('2021-01-01 00:00:00+00')", r[1]["partition_bound"])
+		assert.Equal(t, "DEFAULT", r[2]["partition_bound"])
+	}
+	r = rows(t, s, pgdiff.ColumnSchemaType)
+	if assert.Len(t, r, 2) {
+		assert.Equal(t, "NO", r[0]["is_partition_key"])
+		assert.Equal(t, "YES", r[1]["is_partition_key"])
+	}
+	r = rows(t, s, pgdiff.IndexSchemaType)
+	if assert.Len(t, r, 1) {
+		assert.Equal(t, "events_created_idx", r[0]["index_name"])
+	}
+}
+
+func TestInheritance(t *testing.T) {
+	s, err := NewSource("inheritance.sql", []byte(`
+CREATE TABLE public.person (
+    id integer NOT NULL,
+    name text
+);
+CREATE TABLE s1.employee (
+    salary integer,
+    name text NOT NULL
+)
+INHERITS (public.person);
+`), "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rows(t, s, pgdiff.TableSchemaType)
+	if assert.Len(t, r, 2) {
+		assert.Equal(t, "null", r[0]["inherits"])
+		assert.Equal(t, "public.person", r[1]["inherits"])
+	}
+	r = rows(t, s, pgdiff.ColumnSchemaType)
+	var names []string
+	for _, row := range r[2:] {
+		names = append(names, row["compare_name"]+" "+row["is_nullable"]+" "+row["is_inherited"])
+	}
+	assert.Equal(t, []string{"s1.employee.00001id NO YES", "s1.employee.00002name NO NO", "s1.employee.00003salary YES NO"}, names)
+}
+
+func TestForeignData(t *testing.T) {
+	s, err := NewSource("foreign.sql", []byte(`
+CREATE EXTENSION IF NOT EXISTS postgres_fdw WITH SCHEMA public;
+CREATE FOREIGN DATA WRAPPER dummy VALIDATOR public.dummy_validator OPTIONS (debug 'true');
+CREATE SERVER loopback FOREIGN DATA WRAPPER postgres_fdw OPTIONS (
+    dbname 'db2',
+    host 'localhost'
+);
+ALTER SERVER loopback OWNER TO u1;
+CREATE USER MAPPING FOR u1 SERVER loopback OPTIONS (
+    password 'secret',
+    "user" 'u1'
+);
+CREATE USER MAPPING FOR public SERVER loopback;
+CREATE FOREIGN TABLE public.remote (
+    id integer OPTIONS (
+    column_name 'remote_id'
+) NOT NULL,
+    name character varying(20)
+)
+SERVER loopback
+OPTIONS (
+    schema_name 'public',
+    table_name 'local'
+);
+ALTER FOREIGN TABLE ONLY public.remote ALTER COLUMN name OPTIONS (
+    column_name 'remote_name'
+);
+ALTER FOREIGN TABLE public.remote OPTIONS (SET table_name 'other', DROP schema_name);
+`), "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []map[string]string{{
+		"compare_name": "dummy",
+		"fdw_name":     "dummy",
+		"handler":      "null",
+		"validator":    "dummy_validator",
+		"options":      `["debug=true"]`,
+	}}, rows(t, s, pgdiff.ForeignDataWrapperSchemaType))
+	assert.Equal(t, []map[string]string{{
+		"compare_name":   "loopback",
+		"server_name":    "loopback",
+		"fdw_name":       "postgres_fdw",
+		"server_type":    "null",
+		"server_version": "null",
+		"options":        `["dbname=db2","host=localhost"]`,
+	}}, rows(t, s, pgdiff.ServerSchemaType))
+	assert.Equal(t, []map[string]string{{
+		"compare_name": "loopback.u1",
+		"server_name":  "loopback",
+		"user_name":    "u1",
+		"options":      `["password=********","user=u1"]`,
+	}, {
+		"compare_name": "loopback.PUBLIC",
+		"server_name":  "loopback",
+		"user_name":    "PUBLIC",
+		"options":      "[]",
+	}}, rows(t, s, pgdiff.UserMappingSchemaType))
+	assert.Equal(t, []map[string]string{{
+		"schema_name":  "public",
+		"compare_name": "public.remote",
+		"table_name":   "remote",
+		"server_name":  "loopback",
+		"options":      `["table_name=other"]`,
+		"columns": `[{"name":"id","type":"integer","not_null":true,"options":["column_name=remote_id"]},` +
+			`{"name":"name","type":"character varying(20)","not_null":false,"options":["column_name=remote_name"]}]`,
+	}}, rows(t, s, pgdiff.ForeignTableSchemaType))
+	assert.Empty(t, rows(t, s, pgdiff.ColumnSchemaType))
+}
+
+func TestReplication(t *testing.T) {
+	s, err := NewSource("replication.sql", []byte(`
+CREATE TABLE public.a (id integer);
+CREATE TABLE s1.b (id integer);
+CREATE PUBLICATION pub WITH (publish = 'update, insert');
+CREATE PUBLICATION every FOR ALL TABLES WITH (publish = 'insert, update, delete, truncate');
+ALTER PUBLICATION pub ADD TABLE ONLY public.a WHERE ((id > 0));
+ALTER PUBLICATION pub ADD TABLE ONLY s1.b;
+ALTER PUBLICATION pub ADD TABLES IN SCHEMA s1;
+ALTER PUBLICATION pub DROP TABLE s1.b;
+CREATE SUBSCRIPTION sub CONNECTION 'host=replica password=secret' PUBLICATION pub, every WITH (connect = false, slot_name = 'sub', binary = true);
+`), "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []map[string]string{{
+		"compare_name": "pub",
+		"pub_name":     "pub",
+		"all_tables":   "NO",
+		"publish":      "insert, update",
+		"via_root":     "NO",
+		"tables":       `[{"name":"public.a","where":"(id > 0)"}]`,
+		"schemas":      "s1",
+	}, {
+		"compare_name": "every",
+		"pub_name":     "every",
+		"all_tables":   "YES",
+		"publish":      "insert, update, delete, truncate",
+		"via_root":     "NO",
+		"tables":       "[]",
+		"schemas":      "null",
+	}}, rows(t, s, pgdiff.PublicationSchemaType))
+	assert.Equal(t, []map[string]string{{
+		"compare_name":       "sub",
+		"sub_name":           "sub",
+		"enabled":            "NO",
+		"conninfo":           "host=replica password=********",
+		"publications":       "every, pub",
+		"slot_name":          "sub",
+		"binary":             "true",
+		"streaming":          "off",
+		"synchronous_commit": "off",
+	}}, rows(t, s, pgdiff.SubscriptionSchemaType))
+}
+
+func TestIndex(t *testing.T) {
+	r := rows(t, testSource(t, "public"), pgdiff.IndexSchemaType)
+	assert.Len(t, r, 3)
+	assert.Equal(t, "parent.parent_pkey", r[0]["compare_name"])
+	assert.Equal(t, "CREATE UNIQUE INDEX parent_pkey ON parent USING btree (id)", r[0]["index_def"])
+	assert.Equal(t, "PRIMARY KEY (id)", r[0]["constraint_def"])
+	assert.Equal(t, "p", r[0]["typ"])
+	assert.Equal(t, "true", r[0]["pk"])
+	assert.Equal(t, "CREATE UNIQUE INDEX parent_name_idx ON parent USING btree (name) WHERE name IS NOT NULL",
+		r[1]["index_def"])
+	assert.Equal(t, "null", r[1]["constraint_def"])
+}
+
+func TestViews(t *testing.T) {
+	s := testSource(t, "*")
+	r := rows(t, s, pgdiff.ViewSchemaType)
+	assert.Equal(t, []map[string]string{{
+		"viewname":   "public.names",
+		"definition": " SELECT parent.name\n   FROM parent;",
+	}}, r)
+	r = rows(t, s, pgdiff.MatViewSchemaType)
+	assert.Equal(t, []map[string]string{{
+		"matviewname": "public.counts",
+		"definition":  " SELECT count(*) AS count\n   FROM parent;",
+		"indexdef":    "CREATE INDEX counts_idx ON public.counts USING btree (count);",
+	}}, r)
+}
+
+func TestForeignKey(t *testing.T) {
+	r := rows(t, testSource(t, "*"), pgdiff.ForeignKeySchemaType)
+	assert.Len(t, r, 1)
+	assert.Equal(t, "s1.child.child_parent_fk", r[0]["compare_name"])
+	assert.Equal(t, "FOREIGN KEY (parent_id) REFERENCES parent(id) ON DELETE CASCADE", r[0]["constraint_def"])
+}
+
+func TestCheckConstraint(t *testing.T) {
+	r := rows(t, testSource(t, "*"), pgdiff.CheckConstraintSchemaType)
+	assert.Equal(t, []map[string]string{{
+		"compare_name":    "s1.child.child_id_check",
+		"schema_name":     "s1",
+		"table_name":      "child",
+		"constraint_name": "child_id_check",
+		"constraint_def":  "CHECK ((id > 0))",
+	}}, r)
+	assert.Empty(t, rows(t, testSource(t, "public"), pgdiff.CheckConstraintSchemaType))
+}
+
+func TestFunction(t *testing.T) {
+	r := rows(t, testSource(t, "*"), pgdiff.FunctionSchemaType)
+	assert.Len(t, r, 2)
+	assert.Equal(t, "touch()", r[0]["fancy"])
+	assert.Equal(t, "trigger", r[0]["return_type"])
+	assert.Equal(t, "s1.add(integer,integer)", r[1]["fancy"])
+	assert.Equal(t, "s1.add(integer, integer)", r[1]["compare_name"])
+	assert.Equal(t, "integer, integer", r[1]["arg_types"])
+	assert.Equal(t, "int4", r[1]["return_type"])
+	assert.Equal(t, "integer", r[1]["result"])
+	assert.Equal(t, "CREATE OR REPLACE FUNCTION s1.add(a integer, b integer DEFAULT 1)\n"+
+		" RETURNS integer\n"+
+		" LANGUAGE sql\n"+
+		" IMMUTABLE STRICT\n"+
+		"AS $function$ SELECT a + b $function$\n", r[1]["definition"])
+}
+
+func TestFunctionOverloads(t *testing.T) {
+	s, err := NewSource("overloads.sql", []byte(`
+CREATE FUNCTION public.f(a integer) RETURNS SETOF integer
+    LANGUAGE sql
+    AS $$ SELECT a $$;
+CREATE FUNCTION public.f(a text) RETURNS TABLE(x text)
+    LANGUAGE sql
+    AS $$ SELECT a $$;
+CREATE PROCEDURE public.p(IN a integer, OUT b integer)
+    LANGUAGE sql
+    AS $$ SELECT a $$;
+`), "public")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rows(t, s, pgdiff.FunctionSchemaType)
+	assert.Len(t, r, 3)
+	assert.Equal(t, "f(integer)", r[0]["compare_name"])
+	assert.Equal(t, "SETOF integer", r[0]["result"])
+	assert.Equal(t, "f(text)", r[1]["compare_name"])
+	assert.Equal(t, "TABLE(x text)", r[1]["result"])
+	assert.Equal(t, "p(integer, integer)", r[2]["compare_name"])
+	assert.Equal(t, "record", r[2]["return_type"])
+	assert.Equal(t, "null", r[2]["result"])
+}
+
+func TestRoutineKinds(t *testing.T) {
+	s, err := NewSource("routines.sql", []byte(`
+CREATE PROCEDURE public.reset(IN n integer)
+    LANGUAGE plpython3u
+    AS $$pass$$;
+CREATE FUNCTION public.sq(x double precision) RETURNS double precision
+    LANGUAGE sql IMMUTABLE
+    AS $$ SELECT x * x $$;
+CREATE AGGREGATE public.cnt(*) (
+    SFUNC = pg_catalog.int8inc,
+    STYPE = int8,
+    INITCOND = '0',
+    PARALLEL = unsafe
+);
+COMMENT ON AGGREGATE public.cnt(*) IS 'counts';
+CREATE AGGREGATE s1.sumsq(double precision) (
+    SFUNC = float8pl,
+    STYPE = double precision,
+    FINALFUNC = public.sq,
+    FINALFUNC_MODIFY = read_only,
+    SORTOP = >,
+    PARALLEL = safe
+);
+CREATE AGGREGATE s1.pct(double precision ORDER BY anyelement) (
+    SFUNC = ordered_set_transition,
+    STYPE = internal,
+    FINALFUNC = percentile_disc_final,
+    FINALFUNC_EXTRA
+);
+`), "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Like pg_dump, the comment query names an aggregate of no arguments with *
+	c := rows(t, s, pgdiff.CommentSchemaType)
+	if assert.Len(t, c, 1) {
+		assert.Equal(t, "AGGREGATE public.cnt(*)", c[0]["compare_name"])
+	}
+	r := rows(t, s, pgdiff.FunctionSchemaType)
+	assert.Len(t, r, 5)
+	assert.Equal(t, "PROCEDURE", r[0]["kind"])
+	assert.Equal(t, "n integer", r[0]["identity_args"])
+	assert.Equal(t, "void", r[0]["return_type"])
+	assert.Equal(t, "CREATE OR REPLACE PROCEDURE public.reset(n integer)\n"+
+		" LANGUAGE plpython3u\n"+
+		"AS $procedure$pass$procedure$\n", r[0]["definition"])
+	assert.Equal(t, "FUNCTION", r[1]["kind"])
+	assert.Equal(t, map[string]string{
+		"schema_name":   "public",
+		"compare_name":  "public.cnt()",
+		"function_name": "cnt",
+		"fancy":         "cnt()",
+		"arg_types":     "",
+		"return_type":   "int8",
+		"result":        "null",
+		"kind":          "AGGREGATE",
+		"identity_args": "*",
+		"definition":    "CREATE OR REPLACE AGGREGATE public.cnt(*) (\n    SFUNC = int8inc,\n    STYPE = bigint,\n    INITCOND = '0'\n)",
+	}, r[2])
+	assert.Equal(t, "float8", r[3]["return_type"])
+	assert.Equal(t, "CREATE OR REPLACE AGGREGATE s1.sumsq(double precision) (\n    SFUNC = float8pl,\n"+
+		"    STYPE = double precision,\n    FINALFUNC = sq,\n    SORTOP = OPERATOR(pg_catalog.>),\n"+
+		"    PARALLEL = SAFE\n)", r[3]["definition"])
+	assert.Equal(t, "null", r[4]["return_type"])
+	assert.Equal(t, "double precision ORDER BY anyelement", r[4]["identity_args"])
+	assert.Equal(t, "s1.pct(double precision,anyelement)", r[4]["fancy"])
+	assert.Equal(t, "CREATE OR REPLACE AGGREGATE s1.pct(double precision ORDER BY anyelement) (\n"+
+		"    SFUNC = ordered_set_transition,\n    STYPE = internal,\n    FINALFUNC = percentile_disc_final,\n"+
+		"    FINALFUNC_EXTRA\n)", r[4]["definition"])
+}
+
+func TestOperators(t *testing.T) {
+	s, err := NewSource("operators.sql", []byte(`
+CREATE OPERATOR public.<<< (
+    FUNCTION = public.box_left,
+    LEFTARG = box,
+    RIGHTARG = box,
+    COMMUTATOR = >>>,
+    NEGATOR = OPERATOR(s1.!<<<),
+    RESTRICT = positionsel,
+    JOIN = positionjoinsel
+);
+CREATE OPERATOR public.~~ (
+    FUNCTION = numeric_uminus,
+    RIGHTARG = numeric
+);
+CREATE OPERATOR FAMILY public.box_ops USING btree;
+CREATE OPERATOR CLASS public.box_ops
+    DEFAULT FOR TYPE box USING btree AS
+    FUNCTION 1 public.box_cmp(box,box),
+    OPERATOR 1 public.<<<;
+ALTER OPERATOR FAMILY public.box_ops USING btree ADD
+    OPERATOR 1 <(box,point);
+CREATE OPERATOR CLASS s1.int_ops FOR TYPE integer USING hash FAMILY public.box_ops AS
+    FUNCTION 1 hashint4(integer),
+    STORAGE integer;
+`), "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rows(t, s, pgdiff.OperatorSchemaType)
+	assert.Len(t, r, 2)
+	assert.Equal(t, map[string]string{
+		"schema_name":   "public",
+		"compare_name":  "public.<<<(box, box)",
+		"operator_name": "<<<",
+		"left_type":     "box",
+		"right_type":    "box",
+		"function":      "box_left",
+		"commutator":    "OPERATOR(public.>>>)",
+		"negator":       "OPERATOR(s1.!<<<)",
+		"restrict":      "positionsel",
+		"join":          "positionjoinsel",
+		"hashes":        "NO",
+		"merges":        "NO",
+	}, r[0])
+	assert.Equal(t, "public.~~(NONE, numeric)", r[1]["compare_name"])
+	assert.Equal(t, "null", r[1]["commutator"])
+
+	r = rows(t, s, pgdiff.OperatorClassSchemaType)
+	assert.Len(t, r, 4)
+	assert.Equal(t, "public.box_ops USING btree", r[0]["compare_name"])
+	assert.Equal(t, "null", r[0]["class_name"])
+	assert.Equal(t, `["OPERATOR 1 <(box,point)"]`, r[0]["members"])
+	assert.Equal(t, map[string]string{
+		"schema_name":   "public",
+		"compare_name":  "public.box_ops USING btree CLASS public.box_ops",
+		"family_schema": "public",
+		"family_name":   "box_ops",
+		"access_method": "btree",
+		"class_name":    "box_ops",
+		"is_default":    "YES",
+		"type":          "box",
+		"storage":       "null",
+		"members":       `["OPERATOR 1 <<<(box,box)","FUNCTION 1 (box, box) box_cmp(box,box)"]`,
+	}, r[2])
+	assert.Equal(t, "public.box_ops USING hash", r[1]["compare_name"])
+	assert.Equal(t, "public.box_ops USING hash CLASS s1.int_ops", r[3]["compare_name"])
+	assert.Equal(t, "NO", r[3]["is_default"])
+	assert.Equal(t, "null", r[3]["storage"])
+	assert.Equal(t, `["FUNCTION 1 (integer, integer) hashint4(integer)"]`, r[3]["members"])
+}
+
+func TestTrigger(t *testing.T) {
+	r := rows(t, testSource(t, "*"), pgdiff.TriggerSchemaType)
+	assert.Len(t, r, 1)
+	assert.Equal(t, "CREATE TRIGGER parent_touch BEFORE UPDATE ON parent FOR EACH ROW EXECUTE FUNCTION touch()",
+		r[0]["trigger_def"])
+	assert.Equal(t, "O", r[0]["enabled"])
+}
+
+func TestEventTrigger(t *testing.T) {
+	s, err := NewSource("events.sql", []byte(`
+CREATE EVENT TRIGGER audit ON ddl_command_end
+         WHEN TAG IN ('CREATE TABLE', 'alter table')
+   EXECUTE FUNCTION public.audit_ddl();
+ALTER EVENT TRIGGER audit DISABLE;
+CREATE EVENT TRIGGER drops ON sql_drop
+   EXECUTE PROCEDURE s1.log_drop();
+`), "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []map[string]string{{
+		"compare_name": "audit",
+		"trigger_name": "audit",
+		"event":        "ddl_command_end",
+		"tags":         "'ALTER TABLE', 'CREATE TABLE'",
+		"function":     "audit_ddl",
+		"enabled":      "D",
+	}, {
+		"compare_name": "drops",
+		"trigger_name": "drops",
+		"event":        "sql_drop",
+		"tags":         "null",
+		"function":     "s1.log_drop",
+		"enabled":      "O",
+	}}, rows(t, s, pgdiff.EventTriggerSchemaType))
+}
+
+func TestRule(t *testing.T) {
+	s, err := NewSource("rules.sql", []byte(`
+CREATE TABLE public.log (id integer, note text);
+CREATE TABLE public.archive (id integer);
+CREATE RULE archive_insert AS
+    ON INSERT TO public.log DO INSTEAD ( INSERT INTO public.archive (id)
+  VALUES (new.id); NOTIFY log;
+);
+CREATE OR REPLACE RULE no_update AS ON UPDATE TO public.log DO INSTEAD NOTHING;
+CREATE OR REPLACE RULE no_update AS ON UPDATE TO public.log WHERE (old.id < 0) DO INSTEAD NOTHING;
+`), "public")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []map[string]string{{
+		"schema_name":  "public",
+		"compare_name": "log.archive_insert",
+		"table_name":   "log",
+		"rule_name":    "archive_insert",
+		"rule_def":     "CREATE RULE archive_insert AS\n    ON INSERT TO log DO INSTEAD ( INSERT INTO archive (id)\n  VALUES (new.id); NOTIFY log;\n)",
+	}, {
+		"schema_name":  "public",
+		"compare_name": "log.no_update",
+		"table_name":   "log",
+		"rule_name":    "no_update",
+		"rule_def":     "CREATE RULE no_update AS ON UPDATE TO log WHERE (old.id < 0) DO INSTEAD NOTHING",
+	}}, rows(t, s, pgdiff.RuleSchemaType))
+}
+
+func TestPolicy(t *testing.T) {
+	r := rows(t, testSource(t, "public"), pgdiff.PolicySchemaType)
+	assert.Equal(t, []map[string]string{{
+		"schema_name":        "public",
+		"compare_name":       "parent",
+		"table_name":         "parent",
+		"policy_name":        "null",
+		"row_security":       "YES",
+		"force_row_security": "NO",
+		"command":            "null",
+		"permissive":         "null",
+		"roles":              "null",
+		"using_expr":         "null",
+		"check_expr":         "null",
+	}, {
+		"schema_name":        "public",
+		"compare_name":       "parent.own rows",
+		"table_name":         "parent",
+		"policy_name":        "own rows",
+		"row_security":       "null",
+		"force_row_security": "null",
+		"command":            "UPDATE",
+		"permissive":         "PERMISSIVE",
+		"roles":              "u1, u2",
+		"using_expr":         "((name)::text = CURRENT_USER)",
+		"check_expr":         "(id > 0)",
+	}}, r)
+}
+
+func TestComment(t *testing.T) {
+	r := rows(t, testSource(t, "*"), pgdiff.CommentSchemaType)
+	assert.Equal(t, []map[string]string{
+		{"schema_name": "s1", "compare_name": "SCHEMA s1", "object_type": "SCHEMA", "table_name": "null",
+			"object_name": "s1", "comment": "Second schema"},
+		{"schema_name": "public", "compare_name": "TABLE public.parent", "object_type": "TABLE", "table_name": "null",
+			"object_name": "parent", "comment": "Parents"},
+		{"schema_name": "public", "compare_name": "COLUMN public.parent.name", "object_type": "COLUMN",
+			"table_name": "parent", "object_name": "name", "comment": `It's the \name`},
+		{"schema_name": "s1", "compare_name": "CONSTRAINT s1.child.child_id_check", "object_type": "CONSTRAINT",
+			"table_name": "child", "object_name": "child_id_check", "comment": "Positive ids"},
+		{"schema_name": "s1", "compare_name": "FUNCTION s1.add(a integer, b integer)", "object_type": "FUNCTION",
+			"table_name": "null", "object_name": "add(a integer, b integer)", "comment": "Adds"},
+	}, r)
+	r = rows(t, testSource(t, "s1"), pgdiff.CommentSchemaType)
+	assert.Equal(t, "SCHEMA", r[0]["compare_name"])
+}
+
+func TestGrants(t *testing.T) {
+	s := testSource(t, "*")
+	assert.True(t, s.Supports(pgdiff.OwnerSchemaType))
+	assert.False(t, s.Supports(pgdiff.RoleSchemaType))
+	r := rows(t, s, pgdiff.GrantRelationshipSchemaType)
+	assert.Len(t, r, 2)
+	assert.Equal(t, "u1=arwdDxt/u1", r[0]["relationship_acl"])
+	assert.Equal(t, "u2=r/u1", r[1]["relationship_acl"])
+	r = rows(t, s, pgdiff.GrantAttributeSchemaType)
+	assert.Len(t, r, 1)
+	assert.Equal(t, "public.r.parent.name", r[0]["compare_name"])
+	assert.Equal(t, "=rw/u1", r[0]["attribute_acl"])
+}
@@ -0,0 +1,247 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package dump
+
+import (
+	"strings"
+)
+
+var (
+	// typeAliases maps the alternative spellings of built-in types to the names format_type prints.
+	typeAliases = map[string]string{
+		"int":         "integer",
+		"int4":        "integer",
+		"int8":        "bigint",
+		"int2":        "smallint",
+		"serial":      "integer",
+		"serial4":     "integer",
+		"bigserial":   "bigint",
+		"serial8":     "bigint",
+		"smallserial": "smallint",
+		"serial2":     "smallint",
+		"bool":        "boolean",
+		"float8":      "double precision",
+		"float":       "double precision",
+		"float4":      "real",
+		"decimal":     "numeric",
+		"varchar":     "character varying",
+		"char":        "character",
+		"bpchar":      "character",
+		"timestamptz": "timestamp with time zone",
+		"timestamp":   "timestamp without time zone",
+		"timetz":      "time with time zone",
+		"time":        "time without time zone",
+		"varbit":      "bit varying",
+	}
+
+	// udtNames maps the names format_type prints to pg_type.typname, where they differ.
+	udtNames = map[string]string{
+		"integer":                     "int4",
+		"bigint":                      "int8",
+		"smallint":                    "int2",
+		"boolean":                     "bool",
+		"double precision":            "float8",
+		"real":                        "float4",
+		"character varying":           "varchar",
+		"character":                   "bpchar",
+		"timestamp without time zone": "timestamp",
+		"timestamp with time zone":    "timestamptz",
+		"time without time zone":      "time",
+		"time with time zone":         "timetz",
+		"bit varying":                 "varbit",
+	}
+
+	serialTypes = map[string]bool{
+		"serial": true, "serial4": true, "bigserial": true, "serial8": true, "smallserial": true, "serial2": true,
+	}
+)
+
+// typeName reads a type reference at the current position.
+func (p *parser) typeName() (*typeName, error) {
+	t := &typeName{}
+	if p.isWord("setof") {
+		return nil, p.errorf("SETOF is only valid as a return type")
+	}
+	first, quoted, err := p.nameToken()
+	if err != nil {
+		return nil, err
+	}
+	if p.punct(".") {
+		t.schema = first
+		t.name, _, err = p.nameToken()
+		if err != nil {
+			return nil, err
+		}
+		if t.schema == "pg_catalog" {
+			t.schema = ""
+		}
+	} else if quoted {
+		t.name = first
+	} else {
+		t.name = first
+		switch first {
+		case "double":
+			if p.word("precision") {
+				t.name = "double precision"
+			}
+		case "national":
+			if p.word("character") || p.word("char") {
+				t.name, first = "character", "character"
+			}
+			if p.word("varying") {
+				t.name = "character varying"
+			}
+		case "character", "char":
+			if p.word("varying") {
+				t.name = "character varying"
+			}
+		case "bit":
+			if p.word("varying") {
+				t.name = "bit varying"
+			}
+		case "interval":
+			for p.isAnyWord("year", "month", "day", "hour", "minute", "second", "to") {
+				p.next()
+			}
+		}
+		if t.name == first {
+			if serialTypes[first] {
+				t.serial = true
+			}
+			if alias, ok := typeAliases[first]; ok {
+				t.name = alias
+			}
+		}
+	}
+	if p.isPunct("(") {
+		i, j := p.parens()
+		for _, m := range splitList(p.statement, i, j) {
+			t.mods = append(t.mods, p.text(m[0], m[1]))
+		}
+	}
+	if t.name == "timestamp without time zone" || t.name == "time without time zone" {
+		if p.word("with", "time", "zone") {
+			t.name = strings.Replace(t.name, "without", "with", 1)
+		} else {
+			p.word("without", "time", "zone")
+		}
+	}
+	if t.name == "double precision" && len(t.mods) == 1 && first == "float" {
+		if n := atoi(t.mods[0]); n > 0 && n <= 24 {
+			t.name = "real"
+		}
+		t.mods = nil
+	}
+	for {
+		if p.isPunct("[") {
+			p.next()
+			for p.more() && !p.isPunct("]") {
+				p.next()
+			}
+			p.punct("]")
+			t.dims++
+		} else if p.isWord("array") {
+			p.next()
+			if p.punct("[") {
+				for p.more() && !p.isPunct("]") {
+					p.next()
+				}
+				p.punct("]")
+			}
+			t.dims++
+		} else {
+			break
+		}
+	}
+	return t, nil
+}
+
+// builtin reports whether t is a pg_catalog type. Schema qualified types and types created in the dump are not.
+func (t *typeName) builtin(c *catalog) bool {
+	if t.schema != "" {
+		return false
+	}
+	return !c.types[defaultNamespace+"."+t.name] && c.domain(defaultNamespace+"."+t.name) == nil
+}
+
+func (t *typeName) qualifiedName() string {
+	schema := t.schema
+	if schema == "" {
+		schema = defaultNamespace
+	}
+	return schema + "." + t.name
+}
+
+// domain returns the type underlying t if t is a domain.
+func (t *typeName) domain(c *catalog) *typeName {
+	d := c.domain(t.qualifiedName())
+	if d == nil {
+		return nil
+	}
+	base := *d.typ
+	base.dims += t.dims
+	return &base
+}
+
+// format renders t as format_type would without a typmod, as used in function signatures.
+func (t *typeName) format(c *catalog) string {
+	var name string
+	if t.schema != "" && t.schema != defaultNamespace {
+		name = quoteIdent(t.schema) + "." + quoteIdent(t.name)
+	} else if t.builtin(c) {
+		name = t.name
+	} else {
+		name = quoteIdent(t.name)
+	}
+	return name + strings.Repeat("[]", t.dims)
+}
+
+// formatTypmod renders t as format_type would with its typmod, as used in column and attribute definitions.
+func (t *typeName) formatTypmod(c *catalog) string {
+	dims := strings.Repeat("[]", t.dims)
+	name := strings.TrimSuffix(t.format(c), dims)
+	if len(t.mods) == 0 {
+		return name + dims
+	}
+	mods := "(" + strings.Join(t.mods, ",") + ")"
+	if i := strings.Index(name, " with"); i >= 0 && strings.HasPrefix(t.name, "time") {
+		return name[:i] + mods + name[i:] + dims
+	}
+	return name + mods + dims
+}
+
+// udtName returns pg_type.typname for the element type of t.
+func (t *typeName) udtName() string {
+	if n, ok := udtNames[t.name]; ok && t.schema == "" {
+		return n
+	}
+	return t.name
+}
+
+// dataType returns information_schema.columns.data_type, udt_name and character_maximum_length for t.
+func (t *typeName) dataType(c *catalog) (string, string, string) {
+	if d := t.domain(c); d != nil {
+		return d.dataType(c)
+	}
+	udt := t.udtName()
+	if t.dims > 0 {
+		return "ARRAY", "_" + udt, "null"
+	}
+	if !t.builtin(c) {
+		return "USER-DEFINED", udt, "null"
+	}
+	maxLength := "null"
+	switch t.name {
+	case "character varying", "bit varying":
+		if len(t.mods) == 1 {
+			maxLength = t.mods[0]
+		}
+	case "character", "bit":
+		maxLength = "1"
+		if len(t.mods) == 1 {
+			maxLength = t.mods[0]
+		}
+	}
+	return t.name, udt, maxLength
+}
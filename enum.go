@@ -0,0 +1,215 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/joncrlsn/misc"
+)
+
+// ==================================
+// EnumRows definition
+// ==================================
+
+// EnumRows is a sortable string map
+type EnumRows []map[string]string
+
+func (slice EnumRows) Len() int {
+	return len(slice)
+}
+
+func (slice EnumRows) Less(i, j int) bool {
+	return slice[i]["compare_name"] < slice[j]["compare_name"]
+}
+
+func (slice EnumRows) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+// ==================================
+// EnumSchema definition
+// (implements Schema -- defined in pgdiff.go)
+// ==================================
+
+// EnumSchema holds a slice of rows from one of the databases as well as
+// a reference to the current row of data we're viewing.
+type EnumSchema struct {
+	rows     EnumRows
+	rowNum   int
+	done     bool
+	dbSchema string
+	other    *EnumSchema
+}
+
+func NewEnumSchema(rows EnumRows, dbSchema string) *EnumSchema {
+	return &EnumSchema{rows: rows, rowNum: -1, dbSchema: dbSchema}
+}
+
+// get returns the value from the current row for the given key
+func (c *EnumSchema) get(key string) string {
+	if c.rowNum >= len(c.rows) {
+		return ""
+	}
+	return c.rows[c.rowNum][key]
+}
+
+// labels returns the current row's labels in sort order. They are held as a JSON array.
+func (c *EnumSchema) labels() ([]string, error) {
+	var labels []string
+	err := json.Unmarshal([]byte(c.get("labels")), &labels)
+	if err != nil {
+		return nil, fmt.Errorf("reading labels of enum %s: %s", c.Identity(), err)
+	}
+	return labels, nil
+}
+
+// NextRow increments the rowNum and tells you whether or not there are more
+func (c *EnumSchema) NextRow() bool {
+	if c.rowNum >= len(c.rows)-1 {
+		c.done = true
+	}
+	c.rowNum = c.rowNum + 1
+	return !c.done
+}
+
+// Identity returns the qualified name of the current row's object
+func (c *EnumSchema) Identity() string {
+	return c.get("schema_name") + "." + c.get("type_name")
+}
+
+// Row returns a copy of the current row
+func (c *EnumSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
+// Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
+func (c *EnumSchema) Compare(obj Schema) (int, *Error) {
+	c2, ok := obj.(*EnumSchema)
+	if !ok {
+		return +999, NewError(fmt.Sprint("compare(obj) needs a EnumSchema instance", c2))
+	}
+	c.other = c2
+
+	val := misc.CompareStrings(c.get("compare_name"), c.other.get("compare_name"))
+	return val, nil
+}
+
+// typeName returns the qualified name of the enum in db2
+func (c *EnumSchema) typeName() string {
+	schema := c.other.dbSchema
+	if schema == "*" {
+		schema = c.get("schema_name")
+	}
+	return schema + "." + c.get("type_name")
+}
+
+// Add returns SQL to create the enum
+func (c *EnumSchema) Add() []Stringer {
+	labels, err := c.labels()
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	quoted := make([]string, len(labels))
+	for i, label := range labels {
+		quoted[i] = quoteLiteral(label)
+	}
+	return []Stringer{NewLine(fmt.Sprintf("CREATE TYPE %s AS ENUM (%s);", c.typeName(), strings.Join(quoted, ", ")))}
+}
+
+// Drop returns SQL to drop the enum
+func (c EnumSchema) Drop() []Stringer {
+	return []Stringer{NewLine(fmt.Sprintf("DROP TYPE %s.%s;", c.get("schema_name"), c.get("type_name")))}
+}
+
+// Change handles the case where the enum names match, but the labels do not. A single changed label is renamed, new
+// labels are added in the position they hold in db1 and, as PostgreSQL cannot drop or reorder labels, a warning is
+// given for anything else.
+func (c *EnumSchema) Change() []Stringer {
+	if c.get("labels") == c.other.get("labels") {
+		return nil
+	}
+	labels1, err := c.labels()
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	labels2, err := c.other.labels()
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	typeName := c.typeName()
+
+	if len(labels1) == len(labels2) {
+		diff := -1
+		for i := range labels1 {
+			if labels1[i] != labels2[i] {
+				if diff >= 0 {
+					diff = -1
+					break
+				}
+				diff = i
+			}
+		}
+		if diff >= 0 && !contains(labels2, labels1[diff]) {
+			return []Stringer{NewLine(fmt.Sprintf("ALTER TYPE %s RENAME VALUE %s TO %s;", typeName,
+				quoteLiteral(labels2[diff]), quoteLiteral(labels1[diff])))}
+		}
+	}
+
+	var strs []Stringer
+	var kept1, kept2 []string
+	for _, label := range labels2 {
+		if contains(labels1, label) {
+			kept2 = append(kept2, label)
+		} else {
+			strs = append(strs, NewWarning(fmt.Sprintf("-- WARNING: enum %s has label %s, which db1 does not, but "+
+				"PostgreSQL cannot drop enum labels.", typeName, quoteLiteral(label))))
+		}
+	}
+	for i, label := range labels1 {
+		if contains(labels2, label) {
+			kept1 = append(kept1, label)
+			continue
+		}
+		switch {
+		case i > 0:
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER TYPE %s ADD VALUE %s AFTER %s;", typeName,
+				quoteLiteral(label), quoteLiteral(labels1[i-1]))))
+		case len(labels2) > 0:
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER TYPE %s ADD VALUE %s BEFORE %s;", typeName,
+				quoteLiteral(label), quoteLiteral(labels2[0]))))
+		default:
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER TYPE %s ADD VALUE %s;", typeName, quoteLiteral(label))))
+		}
+	}
+	if strings.Join(kept1, "\x00") != strings.Join(kept2, "\x00") {
+		strs = append(strs, NewWarning(fmt.Sprintf("-- WARNING: enum %s orders its labels differently in db1 but "+
+			"PostgreSQL cannot reorder enum labels.", typeName)))
+	}
+	return strs
+}
+
+// quoteLiteral returns s as an SQL string literal, as quote_literal does. A string holding a backslash is written as
+// an escape string, so it reads the same whatever standard_conforming_strings is set to.
+func quoteLiteral(s string) string {
+	s = strings.Replace(s, "'", "''", -1)
+	if !strings.Contains(s, `\`) {
+		return "'" + s + "'"
+	}
+	return "E'" + strings.Replace(s, `\`, `\\`, -1) + "'"
+}
+
+func contains(strs []string, s string) bool {
+	for _, str := range strs {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func enumRow(labels string) map[string]string {
+	return map[string]string{"compare_name": "s.mood", "schema_name": "s", "type_name": "mood", "labels": labels}
+}
+
+func TestEnum(t *testing.T) {
+	assert.Equal(t, []Stringer{NewLine("CREATE TYPE s.mood AS ENUM ('sad', 'o''k');")},
+		diffOutput(NewEnumSchema(EnumRows{enumRow(`["sad","o'k"]`)}, "*"), NewEnumSchema(nil, "*")))
+	assert.Equal(t, []Stringer{NewLine("DROP TYPE s.mood;")}, diffOutput(NewEnumSchema(nil, "*"),
+		NewEnumSchema(EnumRows{enumRow(`[]`)}, "*")))
+	assert.Empty(t, diffOutput(NewEnumSchema(EnumRows{enumRow(`["sad", "ok"]`)}, "*"),
+		NewEnumSchema(EnumRows{enumRow(`["sad","ok"]`)}, "*")))
+
+	assert.Equal(t, []Stringer{NewLine("ALTER TYPE s.mood RENAME VALUE 'ok' TO 'fine';")},
+		diffOutput(NewEnumSchema(EnumRows{enumRow(`["sad","fine","happy"]`)}, "*"),
+			NewEnumSchema(EnumRows{enumRow(`["sad","ok","happy"]`)}, "*")))
+
+	assert.Equal(t, []Stringer{
+		NewLine("ALTER TYPE s.mood ADD VALUE 'awful' BEFORE 'sad';"),
+		NewLine("ALTER TYPE s.mood ADD VALUE 'ok' AFTER 'sad';"),
+		NewLine("ALTER TYPE s.mood ADD VALUE 'ecstatic' AFTER 'happy';"),
+	}, diffOutput(NewEnumSchema(EnumRows{enumRow(`["awful","sad","ok","happy","ecstatic"]`)}, "*"),
+		NewEnumSchema(EnumRows{enumRow(`["sad","happy"]`)}, "*")))
+
+	assert.Equal(t, []Stringer{
+		NewWarning("-- WARNING: enum s.mood has label 'meh', which db1 does not, but PostgreSQL cannot drop enum labels."),
+		NewLine("ALTER TYPE s.mood ADD VALUE 'ok' AFTER 'sad';"),
+		NewWarning("-- WARNING: enum s.mood orders its labels differently in db1 but PostgreSQL cannot reorder enum labels."),
+	}, diffOutput(NewEnumSchema(EnumRows{enumRow(`["happy","sad","ok"]`)}, "*"),
+		NewEnumSchema(EnumRows{enumRow(`["sad","meh","happy"]`)}, "*")))
+
+	strs := diffOutput(NewEnumSchema(EnumRows{enumRow(`["sad"`)}, "*"), NewEnumSchema(nil, "*"))
+	if assert.Len(t, strs, 1) {
+		assert.IsType(t, NewError(""), strs[0])
+	}
+}
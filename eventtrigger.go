@@ -0,0 +1,131 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"fmt"
+
+	"github.com/joncrlsn/misc"
+)
+
+// eventTriggerEnabling maps the enabled state of an event trigger, as pg_event_trigger holds it, to the ALTER EVENT
+// TRIGGER action that sets it.
+var eventTriggerEnabling = map[string]string{"O": "ENABLE", "D": "DISABLE", "R": "ENABLE REPLICA", "A": "ENABLE ALWAYS"}
+
+// ==================================
+// EventTriggerRows definition
+// ==================================
+
+// EventTriggerRows is a sortable string map
+type EventTriggerRows []map[string]string
+
+func (slice EventTriggerRows) Len() int {
+	return len(slice)
+}
+
+func (slice EventTriggerRows) Less(i, j int) bool {
+	return slice[i]["compare_name"] < slice[j]["compare_name"]
+}
+
+func (slice EventTriggerRows) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+// ==================================
+// EventTriggerSchema definition
+// (implements Schema -- defined in pgdiff.go)
+// ==================================
+
+// EventTriggerSchema holds a slice of rows from one of the databases as well as
+// a reference to the current row of data we're viewing.
+//
+// tags holds the quoted tags of the WHEN TAG IN filter, or "null" if there is none, and function is the trigger's
+// function as regproc renders it.
+type EventTriggerSchema struct {
+	rows     EventTriggerRows
+	rowNum   int
+	done     bool
+	dbSchema string
+	other    *EventTriggerSchema
+}
+
+func NewEventTriggerSchema(rows EventTriggerRows, dbSchema string) *EventTriggerSchema {
+	return &EventTriggerSchema{rows: rows, rowNum: -1, dbSchema: dbSchema}
+}
+
+// get returns the value from the current row for the given key
+func (c *EventTriggerSchema) get(key string) string {
+	if c.rowNum >= len(c.rows) {
+		return ""
+	}
+	return c.rows[c.rowNum][key]
+}
+
+// NextRow increments the rowNum and tells you whether or not there are more
+func (c *EventTriggerSchema) NextRow() bool {
+	if c.rowNum >= len(c.rows)-1 {
+		c.done = true
+	}
+	c.rowNum = c.rowNum + 1
+	return !c.done
+}
+
+// Identity returns the name of the current row's event trigger, which is unique within the database
+func (c *EventTriggerSchema) Identity() string {
+	return c.get("trigger_name")
+}
+
+// Row returns a copy of the current row
+func (c *EventTriggerSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
+// Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
+func (c *EventTriggerSchema) Compare(obj Schema) (int, *Error) {
+	c2, ok := obj.(*EventTriggerSchema)
+	if !ok {
+		return +999, NewError(fmt.Sprint("compare(obj) needs a EventTriggerSchema instance", c2))
+	}
+	c.other = c2
+
+	val := misc.CompareStrings(c.get("compare_name"), c.other.get("compare_name"))
+	return val, nil
+}
+
+// Add returns SQL to create the event trigger, then to set its enabled state if it is not simply enabled
+func (c *EventTriggerSchema) Add() []Stringer {
+	name := quoteIdent(c.get("trigger_name"))
+	def := fmt.Sprintf("CREATE EVENT TRIGGER %s ON %s", name, c.get("event"))
+	if c.get("tags") != "null" {
+		def += fmt.Sprintf(" WHEN TAG IN (%s)", c.get("tags"))
+	}
+	strs := []Stringer{NewLine(fmt.Sprintf("%s EXECUTE FUNCTION %s();", def, c.get("function")))}
+	if c.get("enabled") != "O" {
+		strs = append(strs, NewLine(fmt.Sprintf("ALTER EVENT TRIGGER %s %s;", name, eventTriggerEnabling[c.get("enabled")])))
+	}
+	return strs
+}
+
+// Drop returns SQL to drop the event trigger
+func (c EventTriggerSchema) Drop() []Stringer {
+	return []Stringer{NewLine(fmt.Sprintf("DROP EVENT TRIGGER %s;", quoteIdent(c.get("trigger_name"))))}
+}
+
+// Change handles the case where the event trigger names match, but the event, tags, function or enabled state do
+// not. Only the enabled state can be altered, so otherwise the event trigger is dropped and created again.
+func (c *EventTriggerSchema) Change() []Stringer {
+	name := quoteIdent(c.get("trigger_name"))
+	if c.get("event") != c.other.get("event") || c.get("tags") != c.other.get("tags") ||
+		c.get("function") != c.other.get("function") {
+		strs := []Stringer{NewLine(fmt.Sprintf("DROP EVENT TRIGGER %s;", name))}
+		return append(strs, c.Add()...)
+	}
+	if c.get("enabled") != c.other.get("enabled") {
+		return []Stringer{NewLine(fmt.Sprintf("ALTER EVENT TRIGGER %s %s;", name, eventTriggerEnabling[c.get("enabled")]))}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func eventTriggerRow(event, tags, enabled string) map[string]string {
+	return map[string]string{"compare_name": "audit", "trigger_name": "audit", "event": event, "tags": tags,
+		"function": "audit_ddl", "enabled": enabled}
+}
+
+func TestEventTrigger(t *testing.T) {
+	assert.Equal(t, []Stringer{
+		NewLine("CREATE EVENT TRIGGER audit ON ddl_command_end WHEN TAG IN ('ALTER TABLE', 'CREATE TABLE') " +
+			"EXECUTE FUNCTION audit_ddl();"),
+		NewLine("ALTER EVENT TRIGGER audit ENABLE ALWAYS;"),
+	}, diffOutput(NewEventTriggerSchema(EventTriggerRows{
+		eventTriggerRow("ddl_command_end", "'ALTER TABLE', 'CREATE TABLE'", "A"),
+	}, "*"), NewEventTriggerSchema(nil, "*")))
+	assert.Equal(t, []Stringer{NewLine("DROP EVENT TRIGGER audit;")},
+		diffOutput(NewEventTriggerSchema(nil, "*"),
+			NewEventTriggerSchema(EventTriggerRows{eventTriggerRow("sql_drop", "null", "O")}, "*")))
+
+	assert.Empty(t, diffOutput(NewEventTriggerSchema(EventTriggerRows{eventTriggerRow("sql_drop", "null", "O")}, "*"),
+		NewEventTriggerSchema(EventTriggerRows{eventTriggerRow("sql_drop", "null", "O")}, "*")))
+	assert.Equal(t, []Stringer{NewLine("ALTER EVENT TRIGGER audit DISABLE;")},
+		diffOutput(NewEventTriggerSchema(EventTriggerRows{eventTriggerRow("sql_drop", "null", "D")}, "*"),
+			NewEventTriggerSchema(EventTriggerRows{eventTriggerRow("sql_drop", "null", "O")}, "*")))
+	assert.Equal(t, []Stringer{
+		NewLine("DROP EVENT TRIGGER audit;"),
+		NewLine("CREATE EVENT TRIGGER audit ON sql_drop EXECUTE FUNCTION audit_ddl();"),
+	}, diffOutput(NewEventTriggerSchema(EventTriggerRows{eventTriggerRow("sql_drop", "null", "O")}, "*"),
+		NewEventTriggerSchema(EventTriggerRows{eventTriggerRow("ddl_command_end", "null", "O")}, "*")))
+}
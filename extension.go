@@ -0,0 +1,140 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/joncrlsn/misc"
+)
+
+// plainIdentRegex matches identifiers that need no quotes.
+var plainIdentRegex = regexp.MustCompile(`^[a-z_][a-z0-9_$]*$`)
+
+// ==================================
+// ExtensionRows definition
+// ==================================
+
+// ExtensionRows is a sortable string map
+type ExtensionRows []map[string]string
+
+func (slice ExtensionRows) Len() int {
+	return len(slice)
+}
+
+func (slice ExtensionRows) Less(i, j int) bool {
+	return slice[i]["compare_name"] < slice[j]["compare_name"]
+}
+
+func (slice ExtensionRows) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+// ==================================
+// ExtensionSchema definition
+// (implements Schema -- defined in pgdiff.go)
+// ==================================
+
+// ExtensionSchema holds a slice of rows from one of the databases as well as
+// a reference to the current row of data we're viewing.
+type ExtensionSchema struct {
+	rows     ExtensionRows
+	rowNum   int
+	done     bool
+	dbSchema string
+	other    *ExtensionSchema
+}
+
+func NewExtensionSchema(rows ExtensionRows, dbSchema string) *ExtensionSchema {
+	return &ExtensionSchema{rows: rows, rowNum: -1, dbSchema: dbSchema}
+}
+
+// get returns the value from the current row for the given key
+func (c *ExtensionSchema) get(key string) string {
+	if c.rowNum >= len(c.rows) {
+		return ""
+	}
+	return c.rows[c.rowNum][key]
+}
+
+// NextRow increments the rowNum and tells you whether or not there are more
+func (c *ExtensionSchema) NextRow() bool {
+	if c.rowNum >= len(c.rows)-1 {
+		c.done = true
+	}
+	c.rowNum = c.rowNum + 1
+	return !c.done
+}
+
+// Identity returns the name of the current row's extension, which is unique within the database
+func (c *ExtensionSchema) Identity() string {
+	return c.get("extension_name")
+}
+
+// Row returns a copy of the current row
+func (c *ExtensionSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
+// Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
+func (c *ExtensionSchema) Compare(obj Schema) (int, *Error) {
+	c2, ok := obj.(*ExtensionSchema)
+	if !ok {
+		return +999, NewError(fmt.Sprint("compare(obj) needs a ExtensionSchema instance", c2))
+	}
+	c.other = c2
+
+	val := misc.CompareStrings(c.get("compare_name"), c.other.get("compare_name"))
+	return val, nil
+}
+
+// schema returns the schema the extension should be in in db2
+func (c *ExtensionSchema) schema() string {
+	schema := c.other.dbSchema
+	if schema == "*" {
+		schema = c.get("schema_name")
+	}
+	return schema
+}
+
+// Add returns SQL to create the extension. The version is left to the default if it is not known, as in a dump.
+func (c *ExtensionSchema) Add() []Stringer {
+	version := ""
+	if c.get("version") != "null" {
+		version = " VERSION " + quoteLiteral(c.get("version"))
+	}
+	return []Stringer{NewLine(fmt.Sprintf("CREATE EXTENSION %s WITH SCHEMA %s%s;", quoteIdent(c.get("extension_name")),
+		c.schema(), version))}
+}
+
+// Drop returns SQL to drop the extension
+func (c ExtensionSchema) Drop() []Stringer {
+	return []Stringer{NewLine(fmt.Sprintf("DROP EXTENSION %s;", quoteIdent(c.get("extension_name"))))}
+}
+
+// Change handles the case where the extension names match, but the schema or known version do not
+func (c *ExtensionSchema) Change() []Stringer {
+	var strs []Stringer
+	name := quoteIdent(c.get("extension_name"))
+	if c.dbSchema == "*" && c.get("schema_name") != c.other.get("schema_name") {
+		strs = append(strs, NewLine(fmt.Sprintf("ALTER EXTENSION %s SET SCHEMA %s;", name, c.schema())))
+	}
+	if c.get("version") != c.other.get("version") && c.get("version") != "null" && c.other.get("version") != "null" {
+		strs = append(strs, NewLine(fmt.Sprintf("ALTER EXTENSION %s UPDATE TO %s;", name, quoteLiteral(c.get("version")))))
+	}
+	return strs
+}
+
+// quoteIdent returns name, quoted if it is not a plain lower case identifier, eg. uuid-ossp.
+func quoteIdent(name string) string {
+	if plainIdentRegex.MatchString(name) {
+		return name
+	}
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func extensionRow(name, schema, version string) map[string]string {
+	return map[string]string{"compare_name": name, "extension_name": name, "schema_name": schema, "version": version}
+}
+
+func TestExtension(t *testing.T) {
+	assert.Equal(t, []Stringer{
+		NewLine("DROP EXTENSION pgcrypto;"),
+		NewLine(`CREATE EXTENSION "uuid-ossp" WITH SCHEMA ext VERSION '1.1';`),
+	}, diffOutput(NewExtensionSchema(ExtensionRows{extensionRow("uuid-ossp", "ext", "1.1")}, "*"),
+		NewExtensionSchema(ExtensionRows{extensionRow("pgcrypto", "public", "1.3")}, "*")))
+	assert.Equal(t, []Stringer{NewLine("CREATE EXTENSION citext WITH SCHEMA public;")},
+		diffOutput(NewExtensionSchema(ExtensionRows{extensionRow("citext", "other", "null")}, "public"),
+			NewExtensionSchema(nil, "public")))
+
+	assert.Equal(t, []Stringer{
+		NewLine("ALTER EXTENSION postgis SET SCHEMA ext;"),
+		NewLine("ALTER EXTENSION postgis UPDATE TO '3.4.0';"),
+	}, diffOutput(NewExtensionSchema(ExtensionRows{extensionRow("postgis", "ext", "3.4.0")}, "*"),
+		NewExtensionSchema(ExtensionRows{extensionRow("postgis", "public", "3.3.2")}, "*")))
+	assert.Empty(t, diffOutput(NewExtensionSchema(ExtensionRows{extensionRow("postgis", "public", "null")}, "*"),
+		NewExtensionSchema(ExtensionRows{extensionRow("postgis", "public", "3.3.2")}, "*")))
+}
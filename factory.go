@@ -0,0 +1,422 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import "sort"
+
+type (
+	// RowSource supplies the unsorted rows behind each schema type. Each row is keyed by column name and holds the
+	// same string values as the catalog queries in the db package, including "null" for NULL.
+	RowSource interface {
+		Rows(schemaType string) ([]map[string]string, error)
+		Identify(num int) *Notice
+	}
+
+	// PartialSchemaFactory is implemented by SchemaFactory and RowSource instances that can only produce some of the
+	// schema types.
+	PartialSchemaFactory interface {
+		Supports(schemaType string) bool
+	}
+
+	// LoadErrorReporter is implemented by SchemaFactory instances whose source only partly loaded. The errors are
+	// reported alongside the comparison instead of preventing it.
+	LoadErrorReporter interface {
+		LoadErrors() []*Error
+	}
+
+	// Executor is implemented by SchemaFactory instances that can run SQL against their source, ie. a database.
+	Executor interface {
+		Exec(query string) error
+	}
+
+	// RowSchemaFactory is a SchemaFactory that sorts the rows supplied by a RowSource into each type of Schema.
+	RowSchemaFactory struct {
+		source   RowSource
+		dbSchema string
+		notValid bool
+	}
+
+	// CheckNotValidSetter is implemented by SchemaFactory instances whose CHECK constraints can be added NOT VALID.
+	CheckNotValidSetter interface {
+		SetCheckNotValid(notValid bool)
+	}
+)
+
+func NewRowSchemaFactory(source RowSource, dbSchema string) *RowSchemaFactory {
+	return &RowSchemaFactory{source: source, dbSchema: dbSchema}
+}
+
+// SetCheckNotValid makes the CHECK constraints of tables and domains be added NOT VALID and then validated in a separate
+// statement, so writes are only briefly blocked while the existing rows are checked.
+func (f *RowSchemaFactory) SetCheckNotValid(notValid bool) {
+	f.notValid = notValid
+}
+
+// Supports reports whether the underlying RowSource can produce rows for schemaType.
+func (f *RowSchemaFactory) Supports(schemaType string) bool {
+	if p, ok := f.source.(PartialSchemaFactory); ok {
+		return p.Supports(schemaType)
+	}
+	return true
+}
+
+// Rows returns the underlying RowSource's rows for schemaType, making every RowSchemaFactory a RowSource too.
+func (f *RowSchemaFactory) Rows(schemaType string) ([]map[string]string, error) {
+	return f.source.Rows(schemaType)
+}
+
+// Schemata returns a SchemataSchema built from the source's SCHEMA rows
+func (f *RowSchemaFactory) Schemata() (*SchemataSchema, error) {
+	rows, err := f.source.Rows(SchemataSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := SchemataRows(rows)
+	sort.Sort(r)
+	return NewSchemataSchema(r), nil
+}
+
+// Extension returns an ExtensionSchema built from the source's EXTENSION rows
+func (f *RowSchemaFactory) Extension() (*ExtensionSchema, error) {
+	rows, err := f.source.Rows(ExtensionSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := ExtensionRows(rows)
+	sort.Sort(r)
+	return NewExtensionSchema(r, f.dbSchema), nil
+}
+
+// Role returns a RoleSchema built from the source's ROLE rows
+func (f *RowSchemaFactory) Role() (*RoleSchema, error) {
+	rows, err := f.source.Rows(RoleSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := RoleRows(rows)
+	sort.Sort(r)
+	return NewRoleSchema(r), nil
+}
+
+// ForeignDataWrapper returns a ForeignDataWrapperSchema built from the source's FOREIGN_DATA_WRAPPER rows
+func (f *RowSchemaFactory) ForeignDataWrapper() (*ForeignDataWrapperSchema, error) {
+	rows, err := f.source.Rows(ForeignDataWrapperSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := ForeignDataWrapperRows(rows)
+	sort.Sort(r)
+	return NewForeignDataWrapperSchema(r, f.dbSchema), nil
+}
+
+// Server returns a ServerSchema built from the source's SERVER rows
+func (f *RowSchemaFactory) Server() (*ServerSchema, error) {
+	rows, err := f.source.Rows(ServerSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := ServerRows(rows)
+	sort.Sort(r)
+	return NewServerSchema(r, f.dbSchema), nil
+}
+
+// UserMapping returns a UserMappingSchema built from the source's USER_MAPPING rows
+func (f *RowSchemaFactory) UserMapping() (*UserMappingSchema, error) {
+	rows, err := f.source.Rows(UserMappingSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := UserMappingRows(rows)
+	sort.Sort(r)
+	return NewUserMappingSchema(r, f.dbSchema), nil
+}
+
+// Sequence returns a SequenceSchema built from the source's SEQUENCE rows
+func (f *RowSchemaFactory) Sequence() (*SequenceSchema, error) {
+	rows, err := f.source.Rows(SequenceSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := SequenceRows(rows)
+	sort.Sort(r)
+	return NewSequenceSchema(r, f.dbSchema), nil
+}
+
+// Enum returns an EnumSchema built from the source's ENUM rows
+func (f *RowSchemaFactory) Enum() (*EnumSchema, error) {
+	rows, err := f.source.Rows(EnumSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := EnumRows(rows)
+	sort.Sort(r)
+	return NewEnumSchema(r, f.dbSchema), nil
+}
+
+// Domain returns a DomainSchema built from the source's DOMAIN rows
+func (f *RowSchemaFactory) Domain() (*DomainSchema, error) {
+	rows, err := f.source.Rows(DomainSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := DomainRows(rows)
+	sort.Sort(r)
+	return NewDomainSchema(r, f.dbSchema, f.notValid), nil
+}
+
+// Type returns a TypeSchema built from the source's TYPE rows
+func (f *RowSchemaFactory) Type() (*TypeSchema, error) {
+	rows, err := f.source.Rows(TypeSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := TypeRows(rows)
+	sort.Sort(r)
+	return NewTypeSchema(r, f.dbSchema), nil
+}
+
+// Table returns a TableSchema built from the source's TABLE rows
+func (f *RowSchemaFactory) Table() (*TableSchema, error) {
+	rows, err := f.source.Rows(TableSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := TableRows(rows)
+	sort.Sort(r)
+	return NewTableSchema(r, f.dbSchema), nil
+}
+
+// ForeignTable returns a ForeignTableSchema built from the source's FOREIGN_TABLE rows
+func (f *RowSchemaFactory) ForeignTable() (*ForeignTableSchema, error) {
+	rows, err := f.source.Rows(ForeignTableSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := ForeignTableRows(rows)
+	sort.Sort(r)
+	return NewForeignTableSchema(r, f.dbSchema), nil
+}
+
+// Column returns a ColumnSchema built from the source's COLUMN rows
+func (f *RowSchemaFactory) Column() (*ColumnSchema, error) {
+	return f.columnSchema(ColumnSchemaType)
+}
+
+// TableColumn returns a ColumnSchema built from the source's TABLE_COLUMN rows
+func (f *RowSchemaFactory) TableColumn() (*ColumnSchema, error) {
+	return f.columnSchema(TableColumnSchemaType)
+}
+
+func (f *RowSchemaFactory) columnSchema(schemaType string) (*ColumnSchema, error) {
+	rows, err := f.source.Rows(schemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := ColumnRows(rows)
+	sort.Sort(r)
+	return NewColumnSchema(r, f.dbSchema), nil
+}
+
+// Operator returns an OperatorSchema built from the source's OPERATOR rows
+func (f *RowSchemaFactory) Operator() (*OperatorSchema, error) {
+	rows, err := f.source.Rows(OperatorSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := OperatorRows(rows)
+	sort.Sort(r)
+	return NewOperatorSchema(r, f.dbSchema), nil
+}
+
+// OperatorClass returns an OperatorClassSchema built from the source's OPERATOR_CLASS rows
+func (f *RowSchemaFactory) OperatorClass() (*OperatorClassSchema, error) {
+	rows, err := f.source.Rows(OperatorClassSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := OperatorClassRows(rows)
+	sort.Sort(r)
+	return NewOperatorClassSchema(r, f.dbSchema), nil
+}
+
+// Index returns an IndexSchema built from the source's INDEX rows
+func (f *RowSchemaFactory) Index() (*IndexSchema, error) {
+	rows, err := f.source.Rows(IndexSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := IndexRows(rows)
+	sort.Sort(r)
+	return NewIndexSchema(r, f.dbSchema), nil
+}
+
+// View returns a ViewSchema built from the source's VIEW rows
+func (f *RowSchemaFactory) View() (*ViewSchema, error) {
+	rows, err := f.source.Rows(ViewSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := ViewRows(rows)
+	sort.Sort(r)
+	return NewViewSchema(r), nil
+}
+
+// MatView returns a MatViewSchema built from the source's MATVIEW rows
+func (f *RowSchemaFactory) MatView() (*MatViewSchema, error) {
+	rows, err := f.source.Rows(MatViewSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := MatViewRows(rows)
+	sort.Sort(r)
+	return NewMatViewSchema(r), nil
+}
+
+// ForeignKey returns a ForeignKeySchema built from the source's FOREIGN_KEY rows
+func (f *RowSchemaFactory) ForeignKey() (*ForeignKeySchema, error) {
+	rows, err := f.source.Rows(ForeignKeySchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := ForeignKeyRows(rows)
+	sort.Sort(r)
+	return NewForeignKeySchema(r, f.dbSchema), nil
+}
+
+// CheckConstraint returns a CheckConstraintSchema built from the source's CHECK_CONSTRAINT rows
+func (f *RowSchemaFactory) CheckConstraint() (*CheckConstraintSchema, error) {
+	rows, err := f.source.Rows(CheckConstraintSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := CheckConstraintRows(rows)
+	sort.Sort(r)
+	return NewCheckConstraintSchema(r, f.dbSchema, f.notValid), nil
+}
+
+// Function returns a FunctionSchema built from the source's FUNCTION rows
+func (f *RowSchemaFactory) Function() (*FunctionSchema, error) {
+	rows, err := f.source.Rows(FunctionSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := FunctionRows(rows)
+	sort.Sort(r)
+	return NewFunctionSchema(r, f.dbSchema), nil
+}
+
+// EventTrigger returns an EventTriggerSchema built from the source's EVENT_TRIGGER rows
+func (f *RowSchemaFactory) EventTrigger() (*EventTriggerSchema, error) {
+	rows, err := f.source.Rows(EventTriggerSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := EventTriggerRows(rows)
+	sort.Sort(r)
+	return NewEventTriggerSchema(r, f.dbSchema), nil
+}
+
+// Trigger returns a TriggerSchema built from the source's TRIGGER rows
+func (f *RowSchemaFactory) Trigger() (*TriggerSchema, error) {
+	rows, err := f.source.Rows(TriggerSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := TriggerRows(rows)
+	sort.Sort(r)
+	return NewTriggerSchema(r, f.dbSchema), nil
+}
+
+// Rule returns a RuleSchema built from the source's RULE rows
+func (f *RowSchemaFactory) Rule() (*RuleSchema, error) {
+	rows, err := f.source.Rows(RuleSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := RuleRows(rows)
+	sort.Sort(r)
+	return NewRuleSchema(r, f.dbSchema), nil
+}
+
+// Policy returns a PolicySchema built from the source's POLICY rows
+func (f *RowSchemaFactory) Policy() (*PolicySchema, error) {
+	rows, err := f.source.Rows(PolicySchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := PolicyRows(rows)
+	sort.Sort(r)
+	return NewPolicySchema(r, f.dbSchema), nil
+}
+
+// Publication returns a PublicationSchema built from the source's PUBLICATION rows
+func (f *RowSchemaFactory) Publication() (*PublicationSchema, error) {
+	rows, err := f.source.Rows(PublicationSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := PublicationRows(rows)
+	sort.Sort(r)
+	return NewPublicationSchema(r, f.dbSchema), nil
+}
+
+// Subscription returns a SubscriptionSchema built from the source's SUBSCRIPTION rows
+func (f *RowSchemaFactory) Subscription() (*SubscriptionSchema, error) {
+	rows, err := f.source.Rows(SubscriptionSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := SubscriptionRows(rows)
+	sort.Sort(r)
+	return NewSubscriptionSchema(r, f.dbSchema), nil
+}
+
+// Owner returns an OwnerSchema built from the source's OWNER rows
+func (f *RowSchemaFactory) Owner() (*OwnerSchema, error) {
+	rows, err := f.source.Rows(OwnerSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := OwnerRows(rows)
+	sort.Sort(r)
+	return NewOwnerSchema(r), nil
+}
+
+// GrantRelationship returns a GrantRelationshipSchema built from the source's GRANT_RELATIONSHIP rows
+func (f *RowSchemaFactory) GrantRelationship() (*GrantRelationshipSchema, error) {
+	rows, err := f.source.Rows(GrantRelationshipSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := GrantRelationshipRows(rows)
+	sort.Sort(r)
+	return NewGrantRelationshipSchema(r, f.dbSchema), nil
+}
+
+// GrantAttribute returns a GrantAttributeSchema built from the source's GRANT_ATTRIBUTE rows
+func (f *RowSchemaFactory) GrantAttribute() (*GrantAttributeSchema, error) {
+	rows, err := f.source.Rows(GrantAttributeSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := GrantAttributeRows(rows)
+	sort.Sort(r)
+	return NewGrantAttributeSchema(r, f.dbSchema), nil
+}
+
+// Comment returns a CommentSchema built from the source's COMMENT rows
+func (f *RowSchemaFactory) Comment() (*CommentSchema, error) {
+	rows, err := f.source.Rows(CommentSchemaType)
+	if err != nil {
+		return nil, err
+	}
+	r := CommentRows(rows)
+	sort.Sort(r)
+	return NewCommentSchema(r, f.dbSchema), nil
+}
+
+// Identify returns a Notice identifying the underlying RowSource
+func (f *RowSchemaFactory) Identify(num int) *Notice {
+	return f.source.Identify(num)
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"fmt"
+
+	"github.com/joncrlsn/misc"
+)
+
+// ==================================
+// ForeignDataWrapperRows definition
+// ==================================
+
+// ForeignDataWrapperRows is a sortable string map
+type ForeignDataWrapperRows []map[string]string
+
+func (slice ForeignDataWrapperRows) Len() int {
+	return len(slice)
+}
+
+func (slice ForeignDataWrapperRows) Less(i, j int) bool {
+	return slice[i]["compare_name"] < slice[j]["compare_name"]
+}
+
+func (slice ForeignDataWrapperRows) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+// ==================================
+// ForeignDataWrapperSchema definition
+// (implements Schema -- defined in pgdiff.go)
+// ==================================
+
+// ForeignDataWrapperSchema holds a slice of rows from one of the databases as well as
+// a reference to the current row of data we're viewing.
+type ForeignDataWrapperSchema struct {
+	rows     ForeignDataWrapperRows
+	rowNum   int
+	done     bool
+	dbSchema string
+	other    *ForeignDataWrapperSchema
+}
+
+func NewForeignDataWrapperSchema(rows ForeignDataWrapperRows, dbSchema string) *ForeignDataWrapperSchema {
+	return &ForeignDataWrapperSchema{rows: rows, rowNum: -1, dbSchema: dbSchema}
+}
+
+// get returns the value from the current row for the given key
+func (c *ForeignDataWrapperSchema) get(key string) string {
+	if c.rowNum >= len(c.rows) {
+		return ""
+	}
+	return c.rows[c.rowNum][key]
+}
+
+// NextRow increments the rowNum and tells you whether or not there are more
+func (c *ForeignDataWrapperSchema) NextRow() bool {
+	if c.rowNum >= len(c.rows)-1 {
+		c.done = true
+	}
+	c.rowNum = c.rowNum + 1
+	return !c.done
+}
+
+// Identity returns the name of the current row's foreign data wrapper, which is unique within the database
+func (c *ForeignDataWrapperSchema) Identity() string {
+	return c.get("fdw_name")
+}
+
+// Row returns a copy of the current row
+func (c *ForeignDataWrapperSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
+// Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
+func (c *ForeignDataWrapperSchema) Compare(obj Schema) (int, *Error) {
+	c2, ok := obj.(*ForeignDataWrapperSchema)
+	if !ok {
+		return +999, NewError(fmt.Sprint("compare(obj) needs a ForeignDataWrapperSchema instance", c2))
+	}
+	c.other = c2
+
+	val := misc.CompareStrings(c.get("compare_name"), c.other.get("compare_name"))
+	return val, nil
+}
+
+// Add returns SQL to create the foreign data wrapper
+func (c *ForeignDataWrapperSchema) Add() []Stringer {
+	opts, err := parseOptions(c.get("options"))
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	def := "CREATE FOREIGN DATA WRAPPER " + quoteIdent(c.get("fdw_name"))
+	if c.get("handler") != "null" {
+		def += " HANDLER " + c.get("handler")
+	}
+	if c.get("validator") != "null" {
+		def += " VALIDATOR " + c.get("validator")
+	}
+	return []Stringer{NewLine(def + opts.create() + ";")}
+}
+
+// Drop returns SQL to drop the foreign data wrapper
+func (c ForeignDataWrapperSchema) Drop() []Stringer {
+	return []Stringer{NewLine(fmt.Sprintf("DROP FOREIGN DATA WRAPPER %s;", quoteIdent(c.get("fdw_name"))))}
+}
+
+// Change handles the case where the foreign data wrapper names match, but the handler, validator or options do not
+func (c *ForeignDataWrapperSchema) Change() []Stringer {
+	opts1, err := parseOptions(c.get("options"))
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	opts2, err := parseOptions(c.other.get("options"))
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	alter := ""
+	if c.get("handler") != c.other.get("handler") {
+		if c.get("handler") == "null" {
+			alter += " NO HANDLER"
+		} else {
+			alter += " HANDLER " + c.get("handler")
+		}
+	}
+	if c.get("validator") != c.other.get("validator") {
+		if c.get("validator") == "null" {
+			alter += " NO VALIDATOR"
+		} else {
+			alter += " VALIDATOR " + c.get("validator")
+		}
+	}
+	alter += opts1.alter(opts2)
+	if alter == "" {
+		return nil
+	}
+	return []Stringer{NewLine(fmt.Sprintf("ALTER FOREIGN DATA WRAPPER %s%s;", quoteIdent(c.get("fdw_name")), alter))}
+}
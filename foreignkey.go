@@ -80,6 +80,19 @@ func (c *ForeignKeySchema) NextRow() bool {
 	return !c.done
 }
 
+// Identity returns the qualified name of the current row's object
+func (c *ForeignKeySchema) Identity() string {
+	return c.get("schema_name") + "." + c.get("table_name") + "." + c.get("fk_name")
+}
+
+// Row returns a copy of the current row
+func (c *ForeignKeySchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
 // Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
 func (c *ForeignKeySchema) Compare(obj Schema) (int, *Error) {
 	c2, ok := obj.(*ForeignKeySchema)
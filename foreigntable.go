@@ -0,0 +1,246 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/joncrlsn/misc"
+)
+
+// ==================================
+// ForeignTableRows definition
+// ==================================
+
+// ForeignTableRows is a sortable string map
+type ForeignTableRows []map[string]string
+
+func (slice ForeignTableRows) Len() int {
+	return len(slice)
+}
+
+func (slice ForeignTableRows) Less(i, j int) bool {
+	return slice[i]["compare_name"] < slice[j]["compare_name"]
+}
+
+func (slice ForeignTableRows) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+// foreignColumn is a column of a foreign table, as held in the JSON array of a row's columns
+type foreignColumn struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	NotNull bool     `json:"not_null"`
+	Options []string `json:"options"`
+}
+
+// parseForeignColumns reads the columns of a foreign table from the JSON array raw
+func parseForeignColumns(raw string) ([]foreignColumn, error) {
+	var cols []foreignColumn
+	err := json.Unmarshal([]byte(raw), &cols)
+	if err != nil {
+		return nil, fmt.Errorf("reading foreign table columns %s: %s", raw, err)
+	}
+	return cols, nil
+}
+
+// options returns the column's options
+func (col foreignColumn) options() (*options, error) {
+	raw, err := json.Marshal(col.Options)
+	if err != nil {
+		return nil, err
+	}
+	return parseOptions(string(raw))
+}
+
+// def returns the column's definition as CREATE FOREIGN TABLE and ADD COLUMN expect it
+func (col foreignColumn) def() (string, error) {
+	opts, err := col.options()
+	if err != nil {
+		return "", err
+	}
+	def := quoteIdent(col.Name) + " " + col.Type + opts.create()
+	if col.NotNull {
+		def += " NOT NULL"
+	}
+	return def, nil
+}
+
+// ==================================
+// ForeignTableSchema definition
+// (implements Schema -- defined in pgdiff.go)
+// ==================================
+
+// ForeignTableSchema holds a slice of rows from one of the databases as well as
+// a reference to the current row of data we're viewing.
+//
+// Each row holds a whole foreign table, with its columns in a JSON array, as foreign tables are left out of COLUMN.
+type ForeignTableSchema struct {
+	rows     ForeignTableRows
+	rowNum   int
+	done     bool
+	dbSchema string
+	other    *ForeignTableSchema
+}
+
+func NewForeignTableSchema(rows ForeignTableRows, dbSchema string) *ForeignTableSchema {
+	return &ForeignTableSchema{rows: rows, rowNum: -1, dbSchema: dbSchema}
+}
+
+// get returns the value from the current row for the given key
+func (c *ForeignTableSchema) get(key string) string {
+	if c.rowNum >= len(c.rows) {
+		return ""
+	}
+	return c.rows[c.rowNum][key]
+}
+
+// NextRow increments the rowNum and tells you whether or not there are more
+func (c *ForeignTableSchema) NextRow() bool {
+	if c.rowNum >= len(c.rows)-1 {
+		c.done = true
+	}
+	c.rowNum = c.rowNum + 1
+	return !c.done
+}
+
+// Identity returns the qualified name of the current row's foreign table
+func (c *ForeignTableSchema) Identity() string {
+	return c.get("schema_name") + "." + c.get("table_name")
+}
+
+// Row returns a copy of the current row
+func (c *ForeignTableSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
+// Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
+func (c *ForeignTableSchema) Compare(obj Schema) (int, *Error) {
+	c2, ok := obj.(*ForeignTableSchema)
+	if !ok {
+		return +999, NewError(fmt.Sprint("compare(obj) needs a ForeignTableSchema instance", c2))
+	}
+	c.other = c2
+
+	val := misc.CompareStrings(c.get("compare_name"), c.other.get("compare_name"))
+	return val, nil
+}
+
+// table returns the qualified name of the foreign table in db2
+func (c *ForeignTableSchema) table() string {
+	schema := c.other.dbSchema
+	if schema == "*" {
+		schema = c.get("schema_name")
+	}
+	return schema + "." + c.get("table_name")
+}
+
+// Add returns SQL to create the foreign table
+func (c *ForeignTableSchema) Add() []Stringer {
+	opts, err := parseOptions(c.get("options"))
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	cols, err := parseForeignColumns(c.get("columns"))
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	defs := make([]string, len(cols))
+	for i, col := range cols {
+		defs[i], err = col.def()
+		if err != nil {
+			return []Stringer{NewError(err.Error())}
+		}
+	}
+	return []Stringer{NewLine(fmt.Sprintf("CREATE FOREIGN TABLE %s (%s) SERVER %s%s;", c.table(), strings.Join(defs, ", "),
+		quoteIdent(c.get("server_name")), opts.create()))}
+}
+
+// Drop returns SQL to drop the foreign table
+func (c ForeignTableSchema) Drop() []Stringer {
+	return []Stringer{NewLine(fmt.Sprintf("DROP FOREIGN TABLE %s.%s;", c.get("schema_name"), c.get("table_name")))}
+}
+
+// Change handles the case where the foreign table names match, but the server, options or columns do not. The server
+// of a foreign table cannot be altered, so a warning is given instead.
+func (c *ForeignTableSchema) Change() []Stringer {
+	opts1, err := parseOptions(c.get("options"))
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	opts2, err := parseOptions(c.other.get("options"))
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	cols1, err := parseForeignColumns(c.get("columns"))
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	cols2, err := parseForeignColumns(c.other.get("columns"))
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	var strs []Stringer
+	table := c.table()
+	if c.get("server_name") != c.other.get("server_name") {
+		strs = append(strs, NewWarning(fmt.Sprintf("-- WARNING: foreign table %s uses server %s in db1 but %s in db2. "+
+			"PostgreSQL cannot change it, so the foreign table must be dropped and created again.", table,
+			c.get("server_name"), c.other.get("server_name"))))
+	}
+	if alter := opts1.alter(opts2); alter != "" {
+		strs = append(strs, NewLine(fmt.Sprintf("ALTER FOREIGN TABLE %s%s;", table, alter)))
+	}
+
+	named2 := map[string]foreignColumn{}
+	for _, col := range cols2 {
+		named2[col.Name] = col
+	}
+	named1 := map[string]bool{}
+	for _, col1 := range cols1 {
+		named1[col1.Name] = true
+		name := quoteIdent(col1.Name)
+		col2, ok := named2[col1.Name]
+		if !ok {
+			def, err := col1.def()
+			if err != nil {
+				return []Stringer{NewError(err.Error())}
+			}
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER FOREIGN TABLE %s ADD COLUMN %s;", table, def)))
+			continue
+		}
+		if col1.Type != col2.Type {
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER FOREIGN TABLE %s ALTER COLUMN %s TYPE %s;", table, name, col1.Type)))
+		}
+		if col1.NotNull != col2.NotNull {
+			action := "DROP"
+			if col1.NotNull {
+				action = "SET"
+			}
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER FOREIGN TABLE %s ALTER COLUMN %s %s NOT NULL;", table, name, action)))
+		}
+		colOpts1, err := col1.options()
+		if err != nil {
+			return []Stringer{NewError(err.Error())}
+		}
+		colOpts2, err := col2.options()
+		if err != nil {
+			return []Stringer{NewError(err.Error())}
+		}
+		if alter := colOpts1.alter(colOpts2); alter != "" {
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER FOREIGN TABLE %s ALTER COLUMN %s%s;", table, name, alter)))
+		}
+	}
+	for _, col2 := range cols2 {
+		if !named1[col2.Name] {
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER FOREIGN TABLE %s DROP COLUMN %s;", table, quoteIdent(col2.Name))))
+		}
+	}
+	return strs
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func foreignTableRow(server, options, columns string) map[string]string {
+	return map[string]string{"compare_name": "s.remote", "schema_name": "s", "table_name": "remote",
+		"server_name": server, "options": options, "columns": columns}
+}
+
+func TestForeignTable(t *testing.T) {
+	cols := `[{"name":"id","type":"integer","not_null":true,"options":["column_name=remote_id"]},` +
+		`{"name":"Name","type":"text","not_null":false,"options":[]}]`
+	assert.Equal(t, []Stringer{
+		NewLine(`CREATE FOREIGN TABLE s.remote (id integer OPTIONS (column_name 'remote_id') NOT NULL, "Name" text) ` +
+			`SERVER loopback OPTIONS (table_name 'local');`),
+	}, diffOutput(NewForeignTableSchema(ForeignTableRows{
+		foreignTableRow("loopback", `["table_name=local"]`, cols),
+	}, "*"), NewForeignTableSchema(nil, "*")))
+	assert.Equal(t, []Stringer{NewLine("DROP FOREIGN TABLE s.remote;")},
+		diffOutput(NewForeignTableSchema(nil, "*"),
+			NewForeignTableSchema(ForeignTableRows{foreignTableRow("loopback", "[]", cols)}, "*")))
+	assert.Empty(t, diffOutput(NewForeignTableSchema(ForeignTableRows{foreignTableRow("loopback", "[]", cols)}, "*"),
+		NewForeignTableSchema(ForeignTableRows{foreignTableRow("loopback", "[]", cols)}, "*")))
+
+	cols2 := `[{"name":"id","type":"bigint","not_null":false,"options":[]},` +
+		`{"name":"gone","type":"text","not_null":false,"options":null}]`
+	assert.Equal(t, []Stringer{
+		NewWarning("-- WARNING: foreign table s.remote uses server loopback in db1 but other in db2. PostgreSQL cannot " +
+			"change it, so the foreign table must be dropped and created again."),
+		NewLine("ALTER FOREIGN TABLE s.remote OPTIONS (ADD table_name 'local');"),
+		NewLine("ALTER FOREIGN TABLE s.remote ALTER COLUMN id TYPE integer;"),
+		NewLine("ALTER FOREIGN TABLE s.remote ALTER COLUMN id SET NOT NULL;"),
+		NewLine("ALTER FOREIGN TABLE s.remote ALTER COLUMN id OPTIONS (ADD column_name 'remote_id');"),
+		NewLine(`ALTER FOREIGN TABLE s.remote ADD COLUMN "Name" text;`),
+		NewLine("ALTER FOREIGN TABLE s.remote DROP COLUMN gone;"),
+	}, diffOutput(NewForeignTableSchema(ForeignTableRows{
+		foreignTableRow("loopback", `["table_name=local"]`, cols),
+	}, "*"), NewForeignTableSchema(ForeignTableRows{foreignTableRow("other", "[]", cols2)}, "*")))
+}
@@ -67,6 +67,19 @@ func (c *FunctionSchema) NextRow() bool {
 	return !c.done
 }
 
+// Identity returns the qualified name and argument types of the current row's routine, which tell overloads apart
+func (c *FunctionSchema) Identity() string {
+	return c.signature(c.get("schema_name"))
+}
+
+// Row returns a copy of the current row
+func (c *FunctionSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
 // Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
 func (c *FunctionSchema) Compare(obj Schema) (int, *Error) {
 	c2, ok := obj.(*FunctionSchema)
@@ -80,58 +93,91 @@ func (c *FunctionSchema) Compare(obj Schema) (int, *Error) {
 	return val, nil
 }
 
-// Add returns SQL to create the function
-func (c *FunctionSchema) Add() []Stringer {
+// kind returns the kind of the current row's routine: FUNCTION, PROCEDURE, AGGREGATE or WINDOW. Rows that predate
+// the kind column hold functions.
+func (c *FunctionSchema) kind() string {
+	if kind := c.get("kind"); kind != "" {
+		return kind
+	}
+	return "FUNCTION"
+}
+
+// keyword returns the keyword CREATE and DROP use for the current row's routine, a window function being a FUNCTION
+func (c *FunctionSchema) keyword() string {
+	if kind := c.kind(); kind != "WINDOW" {
+		return kind
+	}
+	return "FUNCTION"
+}
+
+// definition returns the definition of the current row's routine, moved to db2's schema if the schemas differ
+func (c *FunctionSchema) definition() string {
 	// If we are comparing two different schemas against each other, we need to do some
 	// modification of the first function definition, so we create it in the right dbSchema
 	functionDef := c.get("definition")
 	if c.dbSchema != c.other.dbSchema {
 		functionDef = strings.Replace(
 			functionDef,
-			fmt.Sprintf("FUNCTION %s.%s(", c.get("schema_name"), c.get("function_name")),
-			fmt.Sprintf("FUNCTION %s.%s(", c.other.dbSchema, c.get("function_name")),
+			fmt.Sprintf("%s %s.%s(", c.keyword(), c.get("schema_name"), c.get("function_name")),
+			fmt.Sprintf("%s %s.%s(", c.keyword(), c.other.dbSchema, c.get("function_name")),
 			-1)
 	}
+	return functionDef
+}
 
+// Add returns SQL to create the function, procedure or aggregate
+func (c *FunctionSchema) Add() []Stringer {
 	return []Stringer{
 		NewNotice("-- STATEMENT-BEGIN"),
-		NewLine(functionDef + ";"),
+		NewLine(c.definition() + ";"),
 		NewNotice("-- STATEMENT-END"),
 	}
 }
 
-// Drop returns SQL to drop the function
+// signature returns the name of the current row's routine in the given schema, followed by its argument types
+func (c *FunctionSchema) signature(schema string) string {
+	return schema + "." + c.get("function_name") + "(" + c.get("arg_types") + ")"
+}
+
+// Drop returns SQL to drop the function, procedure or aggregate. An ordered-set aggregate is identified by its
+// arguments in the form CREATE AGGREGATE takes them.
 func (c FunctionSchema) Drop() []Stringer {
+	switch c.kind() {
+	case "PROCEDURE":
+		return []Stringer{NewLine(fmt.Sprintf("DROP PROCEDURE %s CASCADE;", c.signature(c.get("schema_name"))))}
+	case "AGGREGATE":
+		return []Stringer{NewLine(fmt.Sprintf("DROP AGGREGATE %s.%s(%s) CASCADE;", c.get("schema_name"),
+			c.get("function_name"), c.get("identity_args")))}
+	}
 	return []Stringer{
 		NewNotice("-- Note that CASCADE in the statement below will also drop any triggers depending on this function."),
-		NewNotice("-- Also, if there are two functions with this name, you will want to add arguments to identify the correct one to drop."),
-		NewNotice("-- (See http://www.postgresql.org/docs/9.4/interactive/sql-dropfunction.html) "),
-		NewLine(fmt.Sprintf("DROP FUNCTION %s.%s CASCADE;", c.get("schema_name"), c.get("function_name"))),
+		NewLine(fmt.Sprintf("DROP FUNCTION %s CASCADE;", c.signature(c.get("schema_name")))),
 	}
 }
 
-// Change handles the case where the function names match, but the definition does not
+// Change handles the case where the signatures match, but the definition does not. CREATE OR REPLACE can change
+// neither the kind of a routine nor what it returns, so in those cases the routine is dropped and created again. A
+// return type or result that is not known, as for some aggregates in dumps and for functions in migrated snapshots, is
+// taken to be unchanged.
 func (c FunctionSchema) Change() []Stringer {
+	if c.kind() != c.other.kind() || !sameOrUnknown(c.get("result"), c.other.get("result")) ||
+		!sameOrUnknown(c.get("return_type"), c.other.get("return_type")) {
+		return append(c.other.Drop(), c.Add()...)
+	}
 	if c.get("definition") == c.other.get("definition") {
 		return nil
 	}
 
-	// If we are comparing two different schemas against each other, we need to do some
-	// modification of the first function definition, so we create it in the right dbSchema
-	functionDef := c.get("definition")
-	if c.dbSchema != c.other.dbSchema {
-		functionDef = strings.Replace(
-			functionDef,
-			fmt.Sprintf("FUNCTION %s.%s(", c.get("schema_name"), c.get("function_name")),
-			fmt.Sprintf("FUNCTION %s.%s(", c.other.dbSchema, c.get("function_name")),
-			-1)
-	}
-
 	// The definition column has everything needed to rebuild the function
 	return []Stringer{
 		NewNotice("-- This function is different so we'll recreate it:"),
 		NewNotice("-- STATEMENT-BEGIN"),
-		NewLine(fmt.Sprintf("%s;", functionDef)),
+		NewLine(fmt.Sprintf("%s;", c.definition())),
 		NewNotice("-- STATEMENT-END"),
 	}
 }
+
+// sameOrUnknown reports whether two values match or either is not known
+func sameOrUnknown(v1, v2 string) bool {
+	return v1 == v2 || v1 == "null" || v2 == "null"
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func functionRow(kind, identityArgs, def string) map[string]string {
+	return map[string]string{"compare_name": "f(integer)", "schema_name": "s1", "function_name": "f", "kind": kind,
+		"arg_types": "integer", "identity_args": identityArgs, "return_type": "void", "result": "null", "definition": def}
+}
+
+func TestFunction(t *testing.T) {
+	proc := "CREATE OR REPLACE PROCEDURE s1.f(a integer)\n LANGUAGE plpython3u\nAS $procedure$pass$procedure$\n"
+	assert.Equal(t, []Stringer{
+		NewNotice("-- STATEMENT-BEGIN"),
+		NewLine("CREATE OR REPLACE PROCEDURE s2.f(a integer)\n LANGUAGE plpython3u\nAS $procedure$pass$procedure$\n;"),
+		NewNotice("-- STATEMENT-END"),
+	}, diffOutput(NewFunctionSchema(FunctionRows{functionRow("PROCEDURE", "a integer", proc)}, "s1"),
+		NewFunctionSchema(nil, "s2")))
+	assert.Equal(t, []Stringer{NewLine("DROP PROCEDURE s1.f(integer) CASCADE;")},
+		diffOutput(NewFunctionSchema(nil, "s1"),
+			NewFunctionSchema(FunctionRows{functionRow("PROCEDURE", "a integer", proc)}, "s1")))
+
+	agg := "CREATE OR REPLACE AGGREGATE s1.f(double precision ORDER BY anyelement) (\n    SFUNC = ordered_set_transition,\n" +
+		"    STYPE = internal,\n    FINALFUNC = percentile_disc_final,\n    FINALFUNC_EXTRA\n)"
+	assert.Equal(t, []Stringer{NewLine("DROP AGGREGATE s1.f(double precision ORDER BY anyelement) CASCADE;")},
+		diffOutput(NewFunctionSchema(nil, "s1"), NewFunctionSchema(FunctionRows{
+			functionRow("AGGREGATE", "double precision ORDER BY anyelement", agg),
+		}, "s1")))
+
+	// A routine that changes kind is dropped first
+	fn := "CREATE OR REPLACE FUNCTION s1.f(a integer)\n RETURNS void\n LANGUAGE sql\nAS $function$ SELECT $function$\n"
+	assert.Equal(t, []Stringer{
+		NewLine("DROP PROCEDURE s1.f(integer) CASCADE;"),
+		NewNotice("-- STATEMENT-BEGIN"),
+		NewLine(fn + ";"),
+		NewNotice("-- STATEMENT-END"),
+	}, diffOutput(NewFunctionSchema(FunctionRows{functionRow("FUNCTION", "a integer", fn)}, "s1"),
+		NewFunctionSchema(FunctionRows{functionRow("PROCEDURE", "a integer", proc)}, "s1")))
+
+	// Rows without a kind hold functions
+	row := functionRow("", "a integer", fn)
+	delete(row, "kind")
+	assert.Empty(t, diffOutput(NewFunctionSchema(FunctionRows{row}, "s1"),
+		NewFunctionSchema(FunctionRows{functionRow("FUNCTION", "a integer", fn)}, "s1")))
+
+	// Overloads are told apart by their argument types
+	text := functionRow("FUNCTION", "a text", fn)
+	text["compare_name"], text["arg_types"] = "f(text)", "text"
+	assert.Equal(t, []Stringer{
+		NewNotice("-- Note that CASCADE in the statement below will also drop any triggers depending on this function."),
+		NewLine("DROP FUNCTION s1.f(text) CASCADE;"),
+	}, diffOutput(NewFunctionSchema(FunctionRows{functionRow("FUNCTION", "a integer", fn)}, "s1"),
+		NewFunctionSchema(FunctionRows{functionRow("FUNCTION", "a integer", fn), text}, "s1")))
+
+	// A function whose return type changes is dropped first
+	returnsInt := functionRow("FUNCTION", "a integer", "CREATE OR REPLACE FUNCTION s1.f(a integer)\n RETURNS integer\n"+
+		" LANGUAGE sql\nAS $function$ SELECT a $function$\n")
+	returnsInt["return_type"], returnsInt["result"] = "int4", "integer"
+	returnsVoid := functionRow("FUNCTION", "a integer", fn)
+	returnsVoid["result"] = "void"
+	assert.Equal(t, []Stringer{
+		NewNotice("-- Note that CASCADE in the statement below will also drop any triggers depending on this function."),
+		NewLine("DROP FUNCTION s1.f(integer) CASCADE;"),
+		NewNotice("-- STATEMENT-BEGIN"),
+		NewLine(returnsInt["definition"] + ";"),
+		NewNotice("-- STATEMENT-END"),
+	}, diffOutput(NewFunctionSchema(FunctionRows{returnsInt}, "s1"),
+		NewFunctionSchema(FunctionRows{returnsVoid}, "s1")))
+
+	// An unknown return type is taken to be unchanged
+	unknown := functionRow("AGGREGATE", "*", agg)
+	unknown["return_type"] = "null"
+	known := functionRow("AGGREGATE", "*", agg)
+	known["return_type"] = "int8"
+	assert.Empty(t, diffOutput(NewFunctionSchema(FunctionRows{unknown}, "s1"),
+		NewFunctionSchema(FunctionRows{known}, "s1")))
+
+	// So is the unknown result of a function in a migrated snapshot
+	migrated := functionRow("FUNCTION", "null", fn)
+	assert.Empty(t, diffOutput(NewFunctionSchema(FunctionRows{migrated}, "s1"),
+		NewFunctionSchema(FunctionRows{returnsVoid}, "s1")))
+}
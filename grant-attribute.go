@@ -91,6 +91,20 @@ func (c *GrantAttributeSchema) NextRow() bool {
 	return !c.done
 }
 
+// Identity returns the qualified name of the current row's object followed by the grantee
+func (c *GrantAttributeSchema) Identity() string {
+	role, _ := parseAcl(c.get("attribute_acl"))
+	return fmt.Sprintf("%s.%s.%s (%s)", c.get("schema_name"), c.get("relationship_name"), c.get("attribute_name"), role)
+}
+
+// Row returns a copy of the current row
+func (c *GrantAttributeSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
 // Compare tells you, in one pass, whether or not the first row matches, is less than,
 // or greater than the second row.
 func (c *GrantAttributeSchema) Compare(obj Schema) (int, *Error) {
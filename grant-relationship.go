@@ -91,6 +91,20 @@ func (c *GrantRelationshipSchema) NextRow() bool {
 	return !c.done
 }
 
+// Identity returns the qualified name of the current row's object followed by the grantee
+func (c *GrantRelationshipSchema) Identity() string {
+	role, _ := parseAcl(c.get("relationship_acl"))
+	return fmt.Sprintf("%s.%s (%s)", c.get("schema_name"), c.get("relationship_name"), role)
+}
+
+// Row returns a copy of the current row
+func (c *GrantRelationshipSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
 // Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
 func (c *GrantRelationshipSchema) Compare(obj Schema) (int, *Error) {
 	c2, ok := obj.(*GrantRelationshipSchema)
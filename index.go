@@ -80,6 +80,19 @@ func (c *IndexSchema) NextRow() bool {
 	return !c.done
 }
 
+// Identity returns the qualified name of the current row's object
+func (c *IndexSchema) Identity() string {
+	return c.get("schema_name") + "." + c.get("table_name") + "." + c.get("index_name")
+}
+
+// Row returns a copy of the current row
+func (c *IndexSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
 // Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
 func (c *IndexSchema) Compare(obj Schema) (int, *Error) {
 	c2, ok := obj.(*IndexSchema)
@@ -142,7 +155,7 @@ func (c *IndexSchema) Add() []Stringer {
 func (c *IndexSchema) Drop() []Stringer {
 	var strs []Stringer
 	if c.get("constraint_def") != "null" {
-		strs = append(strs, NewNotice("-- Warning, this may drop foreign keys pointing at this column.  Make sure you re-run the FOREIGN_KEY diff after running this SQL."),
+		strs = append(strs, NewWarning("-- Warning, this may drop foreign keys pointing at this column.  Make sure you re-run the FOREIGN_KEY diff after running this SQL."),
 			NewLine(fmt.Sprintf("ALTER TABLE %s.%s DROP CONSTRAINT %s CASCADE; -- %s", c.get("schema_name"), c.get("table_name"), c.get("index_name"), c.get("constraint_def"))))
 	}
 	strs = append(strs, NewLine(fmt.Sprintf("DROP INDEX %s.%s;", c.get("schema_name"), c.get("index_name"))))
@@ -12,12 +12,18 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"os"
 	"strings"
 
 	"github.com/facefunk/pgdiff"
+	"github.com/facefunk/pgdiff/apply"
 	"github.com/facefunk/pgdiff/db"
+	"github.com/facefunk/pgdiff/dump"
+	"github.com/facefunk/pgdiff/migrations"
+	"github.com/facefunk/pgdiff/schemadir"
+	"github.com/facefunk/pgdiff/snapshot"
 	flag "github.com/ogier/pflag"
 )
 
@@ -31,15 +37,34 @@ that can be found here: http://opensource.org/licenses/MIT
 `
 	usageFormat = `pgdiff - version %s
 usage: %s [<options>] <schemaType>
-Compares the schema between two PostgreSQL databases and generates alter statements 
+       %s [<options>] check <schemaType>
+       %s [<options>] apply [<schemaType>]
+       %s [<options>] snapshot <file>
+Compares the schema between two PostgreSQL databases or schema dumps and generates alter statements 
 that can be *manually* run against the second database.
 
+The check command instead prints a summary of the objects that differ for each schema type and
+exits with status 0 if there are none, 3 if there are any, or 1 if the comparison failed.
+
+The apply command instead generates the SQL for each schema type in turn, ALL by default, shows it,
+offers to open it in $EDITOR and, if confirmed, runs it against the second database.
+
+The snapshot command instead writes every schema type read from the first source to a JSON file,
+or standard output if <file> is -, which can later be compared using --snapshot1 or --snapshot2.
+
 Options:
 %s
 <schemaType> can be: %s
 `
 )
 
+// Exit statuses of the check command.
+const (
+	exitNoDrift = 0
+	exitError   = 1
+	exitDrift   = 3
+)
+
 var commandLineModules []pgdiff.CommandLineModule
 
 /*
@@ -51,22 +76,39 @@ func main() {
 	globalModule := &pgdiff.GlobalModule{}
 	sourceModule := &pgdiff.SourceModule{}
 	DBModule := &db.Module{}
+	DumpModule := &dump.Module{}
+	MigrationsModule := migrations.NewModule(DBModule)
+	SchemaDirModule := schemadir.NewModule(DBModule)
+	SnapshotModule := &snapshot.Module{}
 
 	commandLineModules = []pgdiff.CommandLineModule{
 		initModule,
 		globalModule,
 		sourceModule,
 		DBModule,
+		DumpModule,
+		SnapshotModule,
+		MigrationsModule,
+		SchemaDirModule,
 	}
 
 	configModules := []pgdiff.ConfigModule{
 		globalModule,
 		sourceModule,
 		DBModule,
+		DumpModule,
+		SnapshotModule,
+		MigrationsModule,
+		SchemaDirModule,
 	}
 
+	// MigrationsModule and SchemaDirModule must precede DBModule, as they share DBModule's server configuration.
 	modules := []pgdiff.Module{
+		MigrationsModule,
+		SchemaDirModule,
 		DBModule,
+		DumpModule,
+		SnapshotModule,
 	}
 
 	for _, mod := range commandLineModules {
@@ -100,25 +142,140 @@ func main() {
 		}
 	}
 
+	if args[0] == "snapshot" {
+		fatal(takeSnapshot(modules, sourceModule, args[1:]))
+		return
+	}
+
+	checkDrift := args[0] == "check"
+	if checkDrift {
+		args = args[1:]
+		if len(args) == 0 {
+			log.Fatal("Error: the check command requires SchemaType: " + pgdiff.SchemaTypes)
+		}
+	}
+
+	conf := globalModule.Config()
+
 	facs, err := pgdiff.FactoriesFromModules(modules, sourceModule)
 	check("generating SchemaFactories", err)
+	for _, fac := range facs {
+		if s, ok := fac.(pgdiff.CheckNotValidSetter); ok {
+			s.SetCheckNotValid(conf.NotValid)
+		}
+	}
 
-	strs := pgdiff.CompareByFactoriesAndArgs(facs[1], facs[2], args)
-	output := globalModule.Config().Output
-	pgdiff.PrintStringers(strs, output, os.Stdout, os.Stderr)
+	if args[0] == "apply" {
+		err = applyChanges(facs, args[1:])
+		closeFactories(facs)
+		fatal(err)
+		return
+	}
+
+	report := pgdiff.ReportByFactoriesAndArgs(facs[1], facs[2], args)
+	closeFactories(facs)
+
+	if checkDrift {
+		os.Exit(printCheck(report, conf))
+	}
+	if conf.Format == pgdiff.FormatJSON {
+		err = report.PrintJSON(conf.Output, os.Stdout, os.Stderr)
+		check("printing JSON", err)
+	} else {
+		pgdiff.PrintStringers(report.Stringers(), conf.Output, os.Stdout, os.Stderr)
+	}
+}
 
+// printCheck prints report without any SQL and returns the exit status of the check command.
+func printCheck(report *pgdiff.Report, conf *pgdiff.GlobalConfig) int {
+	if conf.Format == pgdiff.FormatJSON {
+		err := report.PrintJSON(conf.Output&^pgdiff.OutputLine, os.Stdout, os.Stderr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: printing JSON: %s\n", err)
+			return exitError
+		}
+	} else {
+		pgdiff.PrintStringers(report.Summary(), pgdiff.OutputNotice, os.Stdout, os.Stderr)
+	}
+	switch {
+	case report.Failed():
+		return exitError
+	case report.Drifted():
+		return exitDrift
+	}
+	return exitNoDrift
+}
+
+// takeSnapshot writes a snapshot of the first source to the file named by args. Errors are returned once the factory
+// is closed, so that a scratch database is dropped.
+func takeSnapshot(modules []pgdiff.Module, sourceModule *pgdiff.SourceModule, args []string) error {
+	if len(args) != 1 {
+		return pgdiff.NewError("the snapshot command requires a single file argument")
+	}
+	fac, err := pgdiff.FactoryFromModules(modules, sourceModule, 1)
+	if err != nil {
+		return pgdiff.NewError("generating SchemaFactory: " + err.Error())
+	}
+	if fac == nil {
+		return pgdiff.NewError("a properly configured first datasource is required")
+	}
+	defer closeFactory(fac)
+
+	source, ok := fac.(pgdiff.RowSource)
+	if !ok {
+		return pgdiff.NewError("the first datasource cannot be snapshotted")
+	}
+	snap, err := snapshot.Take(source, sourceModule.Config(1).(*pgdiff.SourceConfig).Schema)
+	if err != nil {
+		return pgdiff.NewError("taking snapshot: " + err.Error())
+	}
+	err = snap.WriteFile(args[0])
+	if err != nil {
+		return pgdiff.NewError("writing snapshot: " + err.Error())
+	}
+	return nil
+}
+
+// applyChanges interactively runs the SQL for each schema type named by args, or ALL if there are none, against the
+// second datasource. The caller closes the factories.
+func applyChanges(facs map[int]pgdiff.SchemaFactory, args []string) error {
+	if len(args) == 0 {
+		args = []string{pgdiff.AllSchemaType}
+	}
+	dir, err := ioutil.TempDir("", "pgdiff-apply-")
+	if err != nil {
+		return pgdiff.NewError("creating SQL file directory: " + err.Error())
+	}
+	session, err := apply.NewSession(facs[1], facs[2], os.Stdin, os.Stdout, dir, os.Getenv("EDITOR"))
+	if err != nil {
+		return pgdiff.NewError("starting apply: " + err.Error())
+	}
+	err = session.Run(pgdiff.SchemaTypesFromArgs(args))
+	fmt.Printf("The SQL files are in %s\n", dir)
+	if err != nil {
+		return pgdiff.NewError("applying changes: " + err.Error())
+	}
+	return nil
+}
+
+func closeFactories(facs map[int]pgdiff.SchemaFactory) {
 	for _, fac := range facs {
-		if closer, ok := fac.(io.Closer); ok {
-			err = closer.Close()
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: closing factory: %s\n", err)
-			}
+		closeFactory(fac)
+	}
+}
+
+func closeFactory(fac pgdiff.SchemaFactory) {
+	if closer, ok := fac.(io.Closer); ok {
+		err := closer.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: closing factory: %s\n", err)
 		}
 	}
 }
 
 func usage() {
-	fmt.Fprintf(os.Stderr, usageFormat, pgdiff.Version, os.Args[0], alignedFlagDefaults(), pgdiff.SchemaTypes)
+	fmt.Fprintf(os.Stderr, usageFormat, pgdiff.Version, os.Args[0], os.Args[0], os.Args[0], os.Args[0],
+		alignedFlagDefaults(), pgdiff.SchemaTypes)
 	os.Exit(2)
 }
 
@@ -128,6 +285,13 @@ func check(msg string, err error) {
 	}
 }
 
+// fatal exits if err, which already says what failed, is set. Any factories must be closed first.
+func fatal(err error) {
+	if err != nil {
+		log.Fatal("Error: ", err)
+	}
+}
+
 func alignedFlagDefaults() string {
 	var def string
 	for _, mod := range commandLineModules {
@@ -0,0 +1,132 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/facefunk/pgdiff"
+	"github.com/facefunk/pgdiff/db"
+	flag "github.com/ogier/pflag"
+)
+
+type (
+	// Module is a pgdiff.Module that applies a directory of migrations to a scratch database and compares that. The
+	// server the scratch database is created on is configured through the db.Module, whose database name, if given,
+	// is only used to connect to the server.
+	Module struct {
+		db    *db.Module
+		vals1 configVals
+		vals2 configVals
+		conf1 Config
+		conf2 Config
+	}
+	Config struct {
+		Dir    string
+		server *db.Config
+	}
+	configVals struct {
+		Dir string
+	}
+
+	// factory is the scratch database the migrations were applied to.
+	factory struct {
+		*db.ScratchDatabase
+		dir  string
+		host string
+	}
+)
+
+func NewModule(dbModule *db.Module) *Module {
+	return &Module{db: dbModule}
+}
+
+func (m *Module) Name() string {
+	return "Migrations source"
+}
+
+func (m *Module) RegisterFlags(flagSet *flag.FlagSet) {
+	flagSet.StringVar(&m.vals1.Dir, "migrations1", "", "first migrations directory, applied to a scratch database on host1")
+	flagSet.StringVar(&m.vals2.Dir, "migrations2", "", "second migrations directory, applied to a scratch database on host2")
+}
+
+func (m *Module) ConfigureFromFlags() {
+	setConf(&m.conf1, &m.vals1)
+	setConf(&m.conf2, &m.vals2)
+}
+
+func setConf(conf *Config, vals *configVals) {
+	conf.Dir = vals.Dir
+}
+
+func (m *Module) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	conf := struct {
+		Migrations1 *configVals
+		Migrations2 *configVals
+	}{
+		&configVals{},
+		&configVals{},
+	}
+	err := unmarshal(&conf)
+	if err != nil {
+		return err
+	}
+	setConf(&m.conf1, conf.Migrations1)
+	setConf(&m.conf2, conf.Migrations2)
+	return nil
+}
+
+func (m *Module) Config(i int) pgdiff.Config {
+	var conf *Config
+	switch i {
+	case 1:
+		conf = &m.conf1
+	case 2:
+		conf = &m.conf2
+	default:
+		panic("there are only 2 possible configs.")
+	}
+	conf.server = m.db.Config(i).(*db.Config)
+	return conf
+}
+
+func (m *Module) Factory(conf pgdiff.Config) (pgdiff.SchemaFactory, error) {
+	c, ok := conf.(*Config)
+	if !ok {
+		return nil, pgdiff.NewError("Factory requires migrations.Config instance")
+	}
+	migrations, err := readDir(c.Dir)
+	if err != nil {
+		return nil, err
+	}
+	scratch, err := db.NewScratchDatabase(c.server.DbInfo)
+	if err != nil {
+		return nil, err
+	}
+	for _, mig := range migrations {
+		var sql string
+		sql, err = mig.upSQL()
+		if err == nil {
+			err = scratch.Exec(sql)
+		}
+		if err != nil {
+			scratch.Close()
+			return nil, pgdiff.NewError(fmt.Sprintf("applying migration %s: %s", mig.file, err))
+		}
+	}
+	return &factory{scratch, c.Dir, c.server.DbHost}, nil
+}
+
+func (c *Config) SetSourceConfig(conf *pgdiff.SourceConfig) {
+	c.server.SetSourceConfig(conf)
+}
+
+func (c *Config) Valid() bool {
+	return c.Dir != "" && c.server.DbUser != "" && c.server.DbHost != "" && c.server.DbPort != 0 &&
+		c.server.DbSchema != ""
+}
+
+func (f *factory) Identify(num int) *pgdiff.Notice {
+	return pgdiff.NewNotice(fmt.Sprintf("-- migrations%d: %s applied to %s on %s", num, f.dir, f.Name(), f.host))
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"testing"
+
+	"github.com/facefunk/pgdiff"
+	"github.com/facefunk/pgdiff/db"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	_ pgdiff.Module = (*Module)(nil)
+	_ pgdiff.Config = (*Config)(nil)
+)
+
+func TestYAML(t *testing.T) {
+	in := `
+migrations1:
+  dir: migrations
+db1:
+  host: testy.com
+`
+	dbModule := &db.Module{}
+	err := yaml.Unmarshal([]byte(in), dbModule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := NewModule(dbModule)
+	err = yaml.Unmarshal([]byte(in), out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := out.Config(1).(*Config)
+	assert.Equal(t, "migrations", conf.Dir)
+	assert.Equal(t, "testy.com", conf.server.DbHost)
+	conf.SetSourceConfig(&pgdiff.SourceConfig{User: "u1", Schema: "*"})
+	assert.True(t, conf.Valid())
+	assert.False(t, out.Config(2).Valid())
+}
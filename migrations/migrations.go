@@ -0,0 +1,95 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/facefunk/pgdiff"
+)
+
+const (
+	downSuffix  = ".down.sql"
+	gooseMarker = "-- +goose"
+)
+
+// migration is a single numbered migration file.
+type migration struct {
+	version uint64
+	file    string
+}
+
+// readDir returns the up migrations in dir in the order they apply. Files are named in the golang-migrate style,
+// 1_create_users.up.sql, or the goose style, 20220601093000_create_users.sql. Down migrations and files without a
+// leading version number are ignored.
+func readDir(dir string) ([]migration, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, pgdiff.NewError("reading migrations: " + err.Error())
+	}
+	var migrations []migration
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || !strings.HasSuffix(name, ".sql") || strings.HasSuffix(name, downSuffix) {
+			continue
+		}
+		digits := 0
+		for digits < len(name) && name[digits] >= '0' && name[digits] <= '9' {
+			digits++
+		}
+		if digits == 0 {
+			continue
+		}
+		version, err := strconv.ParseUint(name[:digits], 10, 64)
+		if err != nil {
+			return nil, pgdiff.NewError(fmt.Sprintf("reading migration %s: %s", name, err))
+		}
+		migrations = append(migrations, migration{version, filepath.Join(dir, name)})
+	}
+	sort.SliceStable(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].version == migrations[i-1].version {
+			return nil, pgdiff.NewError(fmt.Sprintf("migrations %s and %s share version %d", migrations[i-1].file,
+				migrations[i].file, migrations[i].version))
+		}
+	}
+	return migrations, nil
+}
+
+// upSQL reads the SQL that applies m. Goose files hold both directions, so only the Up section is returned.
+func (m migration) upSQL() (string, error) {
+	b, err := ioutil.ReadFile(m.file)
+	if err != nil {
+		return "", pgdiff.NewError("reading migration: " + err.Error())
+	}
+	src := string(b)
+	if !strings.Contains(src, gooseMarker) {
+		return src, nil
+	}
+	var up []string
+	in := false
+	for _, line := range strings.Split(src, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, gooseMarker) {
+			switch strings.ToLower(strings.TrimSpace(trimmed[len(gooseMarker):])) {
+			case "up":
+				in = true
+			case "down":
+				in = false
+			}
+			continue
+		}
+		if in {
+			up = append(up, line)
+		}
+	}
+	return strings.Join(up, "\n"), nil
+}
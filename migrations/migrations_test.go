@@ -0,0 +1,83 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testDir(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "pgdiff-migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		err = ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestReadDir(t *testing.T) {
+	dir := testDir(t, map[string]string{
+		"10_add_email.up.sql":     "",
+		"10_add_email.down.sql":   "",
+		"2_create_users.up.sql":   "",
+		"2_create_users.down.sql": "",
+		"README.md":               "",
+		"seed.sql":                "",
+	})
+	defer os.RemoveAll(dir)
+
+	migrations, err := readDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []migration{
+		{2, filepath.Join(dir, "2_create_users.up.sql")},
+		{10, filepath.Join(dir, "10_add_email.up.sql")},
+	}, migrations)
+}
+
+func TestReadDirDuplicate(t *testing.T) {
+	dir := testDir(t, map[string]string{
+		"1_a.up.sql": "",
+		"1_b.up.sql": "",
+	})
+	defer os.RemoveAll(dir)
+
+	_, err := readDir(dir)
+	assert.Error(t, err)
+}
+
+func TestGooseUpSQL(t *testing.T) {
+	dir := testDir(t, map[string]string{
+		"20220601093000_create_users.sql": `-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE users (id integer);
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE users;
+`,
+	})
+	defer os.RemoveAll(dir)
+
+	migrations, err := readDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sql, err := migrations[0].upSQL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "CREATE TABLE users (id integer);\n", sql)
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/joncrlsn/misc"
+)
+
+// ==================================
+// OperatorRows definition
+// ==================================
+
+// OperatorRows is a sortable string map
+type OperatorRows []map[string]string
+
+func (slice OperatorRows) Len() int {
+	return len(slice)
+}
+
+func (slice OperatorRows) Less(i, j int) bool {
+	return slice[i]["compare_name"] < slice[j]["compare_name"]
+}
+
+func (slice OperatorRows) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+// ==================================
+// OperatorSchema definition
+// (implements Schema -- defined in pgdiff.go)
+// ==================================
+
+// OperatorSchema holds a slice of rows from one of the databases as well as
+// a reference to the current row of data we're viewing.
+//
+// Each row holds an operator. left_type is NONE for prefix operators, function, restrict and join are rendered as
+// regproc would render them and commutator and negator as qualified OPERATOR() names.
+type OperatorSchema struct {
+	rows     OperatorRows
+	rowNum   int
+	done     bool
+	dbSchema string
+	other    *OperatorSchema
+}
+
+func NewOperatorSchema(rows OperatorRows, dbSchema string) *OperatorSchema {
+	return &OperatorSchema{rows: rows, rowNum: -1, dbSchema: dbSchema}
+}
+
+// get returns the value from the current row for the given key
+func (c *OperatorSchema) get(key string) string {
+	if c.rowNum >= len(c.rows) {
+		return ""
+	}
+	return c.rows[c.rowNum][key]
+}
+
+// NextRow increments the rowNum and tells you whether or not there are more
+func (c *OperatorSchema) NextRow() bool {
+	if c.rowNum >= len(c.rows)-1 {
+		c.done = true
+	}
+	c.rowNum = c.rowNum + 1
+	return !c.done
+}
+
+// Identity returns the qualified name and argument types of the current row's operator
+func (c *OperatorSchema) Identity() string {
+	return c.get("schema_name") + "." + c.get("operator_name") + "(" + c.get("left_type") + ", " + c.get("right_type") + ")"
+}
+
+// Row returns a copy of the current row
+func (c *OperatorSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
+// Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
+func (c *OperatorSchema) Compare(obj Schema) (int, *Error) {
+	c2, ok := obj.(*OperatorSchema)
+	if !ok {
+		return +999, NewError(fmt.Sprint("compare(obj) needs a OperatorSchema instance", c2))
+	}
+	c.other = c2
+
+	val := misc.CompareStrings(c.get("compare_name"), c.other.get("compare_name"))
+	return val, nil
+}
+
+// schema returns the schema the operator should be in in db2
+func (c *OperatorSchema) schema() string {
+	schema := c.other.dbSchema
+	if schema == "*" {
+		schema = c.get("schema_name")
+	}
+	return schema
+}
+
+// operator returns the name of the operator in the given schema followed by its argument types, as DROP OPERATOR and
+// ALTER OPERATOR take it
+func (c *OperatorSchema) operator(schema string) string {
+	return fmt.Sprintf("%s.%s (%s, %s)", schema, c.get("operator_name"), c.get("left_type"), c.get("right_type"))
+}
+
+// related returns the commutator or negator held in key, moved to db2's schema if it is in the operator's own schema
+func (c *OperatorSchema) related(key string) string {
+	prefix := "OPERATOR(" + c.get("schema_name") + "."
+	if !strings.HasPrefix(c.get(key), prefix) {
+		return c.get(key)
+	}
+	return "OPERATOR(" + c.schema() + "." + strings.TrimPrefix(c.get(key), prefix)
+}
+
+// Add returns SQL to create the operator. A commutator or negator that does not exist yet is created as a shell and
+// filled in when it is created itself.
+func (c *OperatorSchema) Add() []Stringer {
+	params := []string{"FUNCTION = " + c.get("function")}
+	if c.get("left_type") != "NONE" {
+		params = append(params, "LEFTARG = "+c.get("left_type"))
+	}
+	params = append(params, "RIGHTARG = "+c.get("right_type"))
+	for _, key := range []string{"commutator", "negator", "restrict", "join"} {
+		if c.get(key) == "null" {
+			continue
+		}
+		value := c.get(key)
+		if key == "commutator" || key == "negator" {
+			value = c.related(key)
+		}
+		params = append(params, strings.ToUpper(key)+" = "+value)
+	}
+	if c.get("hashes") == "YES" {
+		params = append(params, "HASHES")
+	}
+	if c.get("merges") == "YES" {
+		params = append(params, "MERGES")
+	}
+	return []Stringer{NewLine(fmt.Sprintf("CREATE OPERATOR %s.%s (%s);", c.schema(), c.get("operator_name"),
+		strings.Join(params, ", ")))}
+}
+
+// Drop returns SQL to drop the operator
+func (c OperatorSchema) Drop() []Stringer {
+	return []Stringer{NewLine(fmt.Sprintf("DROP OPERATOR %s;", c.operator(c.get("schema_name"))))}
+}
+
+// Change handles the case where the operators match, but their definitions do not. Only the restriction and join
+// estimators can be altered, so in any other case the operator is dropped and created again.
+func (c *OperatorSchema) Change() []Stringer {
+	for _, key := range []string{"function", "commutator", "negator", "hashes", "merges"} {
+		if c.get(key) != c.other.get(key) {
+			strs := []Stringer{NewLine(fmt.Sprintf("DROP OPERATOR %s;", c.operator(c.schema())))}
+			return append(strs, c.Add()...)
+		}
+	}
+	var params []string
+	for _, key := range []string{"restrict", "join"} {
+		if c.get(key) == c.other.get(key) {
+			continue
+		}
+		value := c.get(key)
+		if value == "null" {
+			value = "NONE"
+		}
+		params = append(params, strings.ToUpper(key)+" = "+value)
+	}
+	if params == nil {
+		return nil
+	}
+	return []Stringer{NewLine(fmt.Sprintf("ALTER OPERATOR %s SET (%s);", c.operator(c.schema()),
+		strings.Join(params, ", ")))}
+}
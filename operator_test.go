@@ -0,0 +1,55 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func operatorRow(schema, function, commutator, restrict string) map[string]string {
+	return map[string]string{"compare_name": "<<<(box, box)", "schema_name": schema, "operator_name": "<<<",
+		"left_type": "box", "right_type": "box", "function": function, "commutator": commutator, "negator": "null",
+		"restrict": restrict, "join": "null", "hashes": "NO", "merges": "NO"}
+}
+
+func TestOperator(t *testing.T) {
+	assert.Equal(t, []Stringer{
+		NewLine("CREATE OPERATOR s2.<<< (FUNCTION = box_left, LEFTARG = box, RIGHTARG = box, COMMUTATOR = OPERATOR(s2.>>>), RESTRICT = positionsel);"),
+	}, diffOutput(NewOperatorSchema(OperatorRows{
+		operatorRow("s1", "box_left", "OPERATOR(s1.>>>)", "positionsel"),
+	}, "s1"), NewOperatorSchema(nil, "s2")))
+	assert.Equal(t, []Stringer{NewLine("DROP OPERATOR s.<<< (box, box);")},
+		diffOutput(NewOperatorSchema(nil, "s"),
+			NewOperatorSchema(OperatorRows{operatorRow("s", "box_left", "null", "null")}, "s")))
+
+	prefix := operatorRow("s", "numeric_uminus", "null", "null")
+	prefix["left_type"], prefix["right_type"] = "NONE", "numeric"
+	assert.Equal(t, []Stringer{NewLine("CREATE OPERATOR s.<<< (FUNCTION = numeric_uminus, RIGHTARG = numeric);")},
+		diffOutput(NewOperatorSchema(OperatorRows{prefix}, "s"), NewOperatorSchema(nil, "s")))
+
+	assert.Empty(t, diffOutput(NewOperatorSchema(OperatorRows{operatorRow("s", "box_left", "null", "null")}, "s"),
+		NewOperatorSchema(OperatorRows{operatorRow("s", "box_left", "null", "null")}, "s")))
+	assert.Equal(t, []Stringer{NewLine("ALTER OPERATOR s.<<< (box, box) SET (RESTRICT = NONE);")},
+		diffOutput(NewOperatorSchema(OperatorRows{operatorRow("s", "box_left", "null", "null")}, "s"),
+			NewOperatorSchema(OperatorRows{operatorRow("s", "box_left", "null", "positionsel")}, "s")))
+	assert.Equal(t, []Stringer{
+		NewLine("DROP OPERATOR s.<<< (box, box);"),
+		NewLine("CREATE OPERATOR s.<<< (FUNCTION = box_below, LEFTARG = box, RIGHTARG = box);"),
+	}, diffOutput(NewOperatorSchema(OperatorRows{operatorRow("s", "box_below", "null", "null")}, "s"),
+		NewOperatorSchema(OperatorRows{operatorRow("s", "box_left", "null", "null")}, "s")))
+}
+
+func TestOperatorAfterFunction(t *testing.T) {
+	// Dumps and snapshots have no dependencies to order by, so the functions operators use must come first.
+	for _, types := range [][]string{schemaTypes, AllSchemaTypes} {
+		rank := map[string]int{}
+		for i, schemaType := range types {
+			rank[schemaType] = i
+		}
+		assert.Less(t, rank[FunctionSchemaType], rank[OperatorSchemaType])
+		assert.Less(t, rank[FunctionSchemaType], rank[OperatorClassSchemaType])
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/joncrlsn/misc"
+)
+
+// ==================================
+// OperatorClassRows definition
+// ==================================
+
+// OperatorClassRows is a sortable string map
+type OperatorClassRows []map[string]string
+
+func (slice OperatorClassRows) Len() int {
+	return len(slice)
+}
+
+func (slice OperatorClassRows) Less(i, j int) bool {
+	return slice[i]["compare_name"] < slice[j]["compare_name"]
+}
+
+func (slice OperatorClassRows) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+// ==================================
+// OperatorClassSchema definition
+// (implements Schema -- defined in pgdiff.go)
+// ==================================
+
+// OperatorClassSchema holds a slice of rows from one of the databases as well as
+// a reference to the current row of data we're viewing.
+//
+// There are two kinds of row. A row with a class_name of "null" holds an operator family and the members added to it
+// outside of any class, every other row holds an operator class and its members. A family's row sorts just before the
+// rows of its classes. members is a JSON array of the OPERATOR and FUNCTION items of CREATE OPERATOR CLASS, with the
+// argument types of each written out.
+type OperatorClassSchema struct {
+	rows     OperatorClassRows
+	rowNum   int
+	done     bool
+	dbSchema string
+	other    *OperatorClassSchema
+}
+
+func NewOperatorClassSchema(rows OperatorClassRows, dbSchema string) *OperatorClassSchema {
+	return &OperatorClassSchema{rows: rows, rowNum: -1, dbSchema: dbSchema}
+}
+
+// get returns the value from the current row for the given key
+func (c *OperatorClassSchema) get(key string) string {
+	if c.rowNum >= len(c.rows) {
+		return ""
+	}
+	return c.rows[c.rowNum][key]
+}
+
+// isFamily tells you whether the current row holds an operator family rather than an operator class
+func (c *OperatorClassSchema) isFamily() bool {
+	return c.get("class_name") == "null"
+}
+
+// NextRow increments the rowNum and tells you whether or not there are more
+func (c *OperatorClassSchema) NextRow() bool {
+	if c.rowNum >= len(c.rows)-1 {
+		c.done = true
+	}
+	c.rowNum = c.rowNum + 1
+	return !c.done
+}
+
+// Identity returns the qualified name and access method of the current row's family or class
+func (c *OperatorClassSchema) Identity() string {
+	if c.isFamily() {
+		return c.get("schema_name") + "." + c.get("family_name") + " USING " + c.get("access_method")
+	}
+	return c.get("schema_name") + "." + c.get("class_name") + " USING " + c.get("access_method")
+}
+
+// Row returns a copy of the current row
+func (c *OperatorClassSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
+// Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
+func (c *OperatorClassSchema) Compare(obj Schema) (int, *Error) {
+	c2, ok := obj.(*OperatorClassSchema)
+	if !ok {
+		return +999, NewError(fmt.Sprint("compare(obj) needs a OperatorClassSchema instance", c2))
+	}
+	c.other = c2
+
+	val := misc.CompareStrings(c.get("compare_name"), c.other.get("compare_name"))
+	return val, nil
+}
+
+// schema returns the schema the family or class should be in in db2
+func (c *OperatorClassSchema) schema() string {
+	schema := c.other.dbSchema
+	if schema == "*" {
+		schema = c.get("schema_name")
+	}
+	return schema
+}
+
+// familyName returns the qualified name of the family in db2, moved along with the class if they share a schema
+func (c *OperatorClassSchema) familyName() string {
+	schema := c.get("family_schema")
+	if schema == c.get("schema_name") {
+		schema = c.schema()
+	}
+	return schema + "." + c.get("family_name")
+}
+
+// family returns the qualified name of the family in db2 followed by its access method
+func (c *OperatorClassSchema) family() string {
+	return c.familyName() + " USING " + c.get("access_method")
+}
+
+// class returns the qualified name of the class in the given schema followed by its access method
+func (c *OperatorClassSchema) class(schema string) string {
+	return schema + "." + c.get("class_name") + " USING " + c.get("access_method")
+}
+
+// members returns the members of the current row's family or class
+func (c *OperatorClassSchema) members() ([]string, *Error) {
+	var members []string
+	err := json.Unmarshal([]byte(c.get("members")), &members)
+	if err != nil {
+		return nil, NewError(fmt.Sprintf("reading members of %s: %s", c.Identity(), err))
+	}
+	return members, nil
+}
+
+// Add returns SQL to create the family, along with the members that belong to no class, or to create the class
+func (c *OperatorClassSchema) Add() []Stringer {
+	members, err := c.members()
+	if err != nil {
+		return []Stringer{err}
+	}
+	if c.isFamily() {
+		strs := []Stringer{NewLine(fmt.Sprintf("CREATE OPERATOR FAMILY %s;", c.family()))}
+		if len(members) > 0 {
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER OPERATOR FAMILY %s ADD %s;", c.family(),
+				strings.Join(members, ", "))))
+		}
+		return strs
+	}
+	def := "CREATE OPERATOR CLASS " + c.schema() + "." + c.get("class_name")
+	if c.get("is_default") == "YES" {
+		def += " DEFAULT"
+	}
+	def += fmt.Sprintf(" FOR TYPE %s USING %s FAMILY %s AS %s", c.get("type"), c.get("access_method"), c.familyName(),
+		strings.Join(members, ", "))
+	if c.get("storage") != "null" {
+		def += ", STORAGE " + c.get("storage")
+	}
+	return []Stringer{NewLine(def + ";")}
+}
+
+// Drop returns SQL to drop the family, along with its classes, or to drop the class. A class is only dropped if it
+// still exists, as it goes with its family if that is dropped too.
+func (c OperatorClassSchema) Drop() []Stringer {
+	if c.isFamily() {
+		return []Stringer{NewLine(fmt.Sprintf("DROP OPERATOR FAMILY %s.%s USING %s;", c.get("schema_name"),
+			c.get("family_name"), c.get("access_method")))}
+	}
+	return []Stringer{NewLine(fmt.Sprintf("DROP OPERATOR CLASS IF EXISTS %s;", c.class(c.get("schema_name"))))}
+}
+
+// Change handles the case where the families or classes match, but their members do not. The members of a family are
+// dropped and added. A class cannot be altered, so it is dropped and created again.
+func (c *OperatorClassSchema) Change() []Stringer {
+	members1, err := c.members()
+	if err != nil {
+		return []Stringer{err}
+	}
+	members2, err := c.other.members()
+	if err != nil {
+		return []Stringer{err}
+	}
+	if c.isFamily() {
+		return c.changeFamily(members1, members2)
+	}
+	if strings.Join(members1, "\n") == strings.Join(members2, "\n") && c.get("is_default") == c.other.get("is_default") &&
+		c.get("type") == c.other.get("type") && c.get("storage") == c.other.get("storage") &&
+		c.get("family_schema")+"."+c.get("family_name") == c.other.get("family_schema")+"."+c.other.get("family_name") {
+		return nil
+	}
+	strs := []Stringer{
+		NewWarning(fmt.Sprintf("-- WARNING: operator class %s cannot be altered, so it is dropped and created again, which fails while indexes use it.",
+			c.class(c.schema()))),
+		NewLine(fmt.Sprintf("DROP OPERATOR CLASS %s;", c.class(c.schema()))),
+	}
+	return append(strs, c.Add()...)
+}
+
+// changeFamily returns SQL to bring the members of the family that belong to no class in line with db1's
+func (c *OperatorClassSchema) changeFamily(members1, members2 []string) []Stringer {
+	var drops, adds []string
+	for _, member := range members2 {
+		if !contains(members1, member) {
+			drops = append(drops, memberKey(member))
+		}
+	}
+	for _, member := range members1 {
+		if !contains(members2, member) {
+			adds = append(adds, member)
+		}
+	}
+	var strs []Stringer
+	if drops != nil {
+		strs = append(strs, NewLine(fmt.Sprintf("ALTER OPERATOR FAMILY %s DROP %s;", c.family(), strings.Join(drops, ", "))))
+	}
+	if adds != nil {
+		strs = append(strs, NewLine(fmt.Sprintf("ALTER OPERATOR FAMILY %s ADD %s;", c.family(), strings.Join(adds, ", "))))
+	}
+	return strs
+}
+
+// memberKey returns the strategy or support number and argument types that identify member, as ALTER OPERATOR FAMILY
+// DROP takes them, eg. OPERATOR 1 (box,box) for OPERATOR 1 <<(box,box)
+func memberKey(member string) string {
+	parts := strings.SplitN(member, " ", 3)
+	if len(parts) < 3 {
+		return member
+	}
+	types := parts[2]
+	if i := strings.Index(types, "("); i >= 0 {
+		types = types[i:]
+	}
+	if j := strings.Index(types, ")"); j >= 0 {
+		types = types[:j+1]
+	}
+	return parts[0] + " " + parts[1] + " " + types
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func opFamilyRow(members string) map[string]string {
+	return map[string]string{"compare_name": "s.box_ops USING btree", "schema_name": "s", "family_schema": "s",
+		"family_name": "box_ops", "access_method": "btree", "class_name": "null", "is_default": "null",
+		"type": "null", "storage": "null", "members": members}
+}
+
+func opClassRow(isDefault, members string) map[string]string {
+	return map[string]string{"compare_name": "s.box_ops USING btree CLASS s.box_ops", "schema_name": "s",
+		"family_schema": "s", "family_name": "box_ops", "access_method": "btree", "class_name": "box_ops",
+		"is_default": isDefault, "type": "box", "storage": "null", "members": members}
+}
+
+func TestOperatorClass(t *testing.T) {
+	cmp := `["OPERATOR 1 <<<(box,box)","FUNCTION 1 (box, box) box_cmp(box,box)"]`
+	assert.Equal(t, []Stringer{
+		NewLine("CREATE OPERATOR FAMILY s.box_ops USING btree;"),
+		NewLine("ALTER OPERATOR FAMILY s.box_ops USING btree ADD OPERATOR 1 <(box,point);"),
+		NewLine("CREATE OPERATOR CLASS s.box_ops DEFAULT FOR TYPE box USING btree FAMILY s.box_ops AS OPERATOR 1 <<<(box,box), FUNCTION 1 (box, box) box_cmp(box,box);"),
+	}, diffOutput(NewOperatorClassSchema(OperatorClassRows{
+		opFamilyRow(`["OPERATOR 1 <(box,point)"]`),
+		opClassRow("YES", cmp),
+	}, "*"), NewOperatorClassSchema(nil, "*")))
+	assert.Equal(t, []Stringer{
+		NewLine("DROP OPERATOR FAMILY s.box_ops USING btree;"),
+		NewLine("DROP OPERATOR CLASS IF EXISTS s.box_ops USING btree;"),
+	}, diffOutput(NewOperatorClassSchema(nil, "*"),
+		NewOperatorClassSchema(OperatorClassRows{opFamilyRow("[]"), opClassRow("YES", cmp)}, "*")))
+
+	same := OperatorClassRows{opFamilyRow("[]"), opClassRow("YES", cmp)}
+	assert.Empty(t, diffOutput(NewOperatorClassSchema(same, "*"), NewOperatorClassSchema(same, "*")))
+	assert.Equal(t, []Stringer{
+		NewLine("ALTER OPERATOR FAMILY s.box_ops USING btree DROP OPERATOR 1 (box,point);"),
+		NewLine("ALTER OPERATOR FAMILY s.box_ops USING btree ADD OPERATOR 2 <=(box,point);"),
+	}, diffOutput(NewOperatorClassSchema(OperatorClassRows{opFamilyRow(`["OPERATOR 2 <=(box,point)"]`)}, "*"),
+		NewOperatorClassSchema(OperatorClassRows{opFamilyRow(`["OPERATOR 1 <(box,point)"]`)}, "*")))
+
+	assert.Equal(t, []Stringer{
+		NewWarning("-- WARNING: operator class s.box_ops USING btree cannot be altered, so it is dropped and created again, which fails while indexes use it."),
+		NewLine("DROP OPERATOR CLASS s.box_ops USING btree;"),
+		NewLine("CREATE OPERATOR CLASS s.box_ops FOR TYPE box USING btree FAMILY s.box_ops AS OPERATOR 1 <<<(box,box), FUNCTION 1 (box, box) box_cmp(box,box);"),
+	}, diffOutput(NewOperatorClassSchema(OperatorClassRows{opClassRow("NO", cmp)}, "*"),
+		NewOperatorClassSchema(OperatorClassRows{opClassRow("YES", cmp)}, "*")))
+}
+
+func TestMemberKey(t *testing.T) {
+	assert.Equal(t, "OPERATOR 1 (box,box)", memberKey("OPERATOR 1 <<(box,box)"))
+	assert.Equal(t, "OPERATOR 3 (box,point)", memberKey("OPERATOR 3 s.<<(box,point) FOR ORDER BY float_ops"))
+	assert.Equal(t, "FUNCTION 1 (box, box)", memberKey("FUNCTION 1 (box, box) box_cmp(box,box)"))
+}
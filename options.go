@@ -0,0 +1,86 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// options are the options of a foreign data wrapper, server, user mapping, foreign table or foreign table column, held
+// in rows as a JSON array of the "name=value" strings PostgreSQL keeps them as.
+type options struct {
+	names  []string
+	values map[string]string
+}
+
+// parseOptions reads options from the JSON array raw
+func parseOptions(raw string) (*options, error) {
+	var strs []string
+	err := json.Unmarshal([]byte(raw), &strs)
+	if err != nil {
+		return nil, fmt.Errorf("reading options %s: %s", raw, err)
+	}
+	opts := &options{values: map[string]string{}}
+	for _, str := range strs {
+		kv := strings.SplitN(str, "=", 2)
+		if len(kv) < 2 {
+			kv = append(kv, "")
+		}
+		opts.names = append(opts.names, kv[0])
+		opts.values[kv[0]] = kv[1]
+	}
+	return opts, nil
+}
+
+// value returns the option as it should be written in SQL
+func (o *options) value(name string) string {
+	return quoteLiteral(o.values[name])
+}
+
+// masked reports whether any option value is MaskedValue
+func (o *options) masked() bool {
+	for _, value := range o.values {
+		if value == MaskedValue {
+			return true
+		}
+	}
+	return false
+}
+
+// create returns an OPTIONS clause led by a space that sets every option, or "" if there are none
+func (o *options) create() string {
+	if len(o.names) == 0 {
+		return ""
+	}
+	strs := make([]string, len(o.names))
+	for i, name := range o.names {
+		strs[i] = quoteIdent(name) + " " + o.value(name)
+	}
+	return " OPTIONS (" + strings.Join(strs, ", ") + ")"
+}
+
+// alter returns an OPTIONS clause led by a space that changes the options o2 to o, or "" if they match
+func (o *options) alter(o2 *options) string {
+	var strs []string
+	for _, name := range o2.names {
+		if _, ok := o.values[name]; !ok {
+			strs = append(strs, "DROP "+quoteIdent(name))
+		}
+	}
+	for _, name := range o.names {
+		value2, ok := o2.values[name]
+		switch {
+		case !ok:
+			strs = append(strs, "ADD "+quoteIdent(name)+" "+o.value(name))
+		case value2 != o.values[name]:
+			strs = append(strs, "SET "+quoteIdent(name)+" "+o.value(name))
+		}
+	}
+	if len(strs) == 0 {
+		return ""
+	}
+	return " OPTIONS (" + strings.Join(strs, ", ") + ")"
+}
@@ -14,6 +14,11 @@ const (
 	OutputError
 )
 
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
 // outputStrings is an array of structs, instead of a map, to provide a canonical order.
 var outputStrings = [3]struct {
 	OutputSet
@@ -31,12 +36,16 @@ type (
 		String() string
 	}
 
-	Line   string
-	Notice string
-	Error  string
+	Line    string
+	Notice  string
+	Warning string
+	Error   string
 
 	// OutputSet is bitmask determining how to filter Stringer outputs.
 	OutputSet byte
+
+	// Format determines how output is printed, either as plain text or as a single JSON document.
+	Format string
 )
 
 func (s *Line) String() string {
@@ -47,6 +56,10 @@ func (s *Notice) String() string {
 	return string(*s)
 }
 
+func (s *Warning) String() string {
+	return string(*s)
+}
+
 func (s *Error) String() string {
 	return string(*s)
 }
@@ -65,6 +78,11 @@ func NewNotice(str string) *Notice {
 	return &l
 }
 
+func NewWarning(str string) *Warning {
+	l := Warning(str)
+	return &l
+}
+
 func NewError(str string) *Error {
 	l := Error(str)
 	return &l
@@ -101,8 +119,17 @@ scan:
 	return o, nil
 }
 
+// FormatFromString parses a Format.
+func FormatFromString(str string) (Format, error) {
+	switch f := Format(str); f {
+	case FormatText, FormatJSON:
+		return f, nil
+	}
+	return "", NewError("invalid format: " + str)
+}
+
 // PrintStringers prints every Stringer to out filtered by type based on the corresponding bits set in the OutputSet o.
-// Every Error will be printed to err.
+// A Warning is printed as a Notice. Every Error will be printed to err.
 func PrintStringers(strs []Stringer, o OutputSet, out io.Writer, err io.Writer) {
 	l := o&OutputLine == 0
 	n := o&OutputNotice == 0
@@ -113,7 +140,7 @@ func PrintStringers(strs []Stringer, o OutputSet, out io.Writer, err io.Writer)
 			if l {
 				continue
 			}
-		case *Notice:
+		case *Notice, *Warning:
 			if n {
 				continue
 			}
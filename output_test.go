@@ -8,7 +8,9 @@ import flag "github.com/ogier/pflag"
 var (
 	_ Stringer   = NewLine("")
 	_ Stringer   = NewNotice("")
+	_ Stringer   = NewWarning("")
 	_ Stringer   = NewError("")
 	_ error      = NewError("")
 	_ flag.Value = (*OutputSet)(nil)
+	_ flag.Value = (*Format)(nil)
 )
@@ -14,41 +14,75 @@ import (
 )
 
 const (
-	AllSchemaType               = "ALL"
-	SchemataSchemaType          = "SCHEMA"
-	RoleSchemaType              = "ROLE"
-	SequenceSchemaType          = "SEQUENCE"
-	TableSchemaType             = "TABLE"
-	ColumnSchemaType            = "COLUMN"
-	TableColumnSchemaType       = "TABLE_COLUMN"
-	IndexSchemaType             = "INDEX"
-	ViewSchemaType              = "VIEW"
-	MatViewSchemaType           = "MATVIEW"
-	ForeignKeySchemaType        = "FOREIGN_KEY"
-	FunctionSchemaType          = "FUNCTION"
-	TriggerSchemaType           = "TRIGGER"
-	OwnerSchemaType             = "OWNER"
-	GrantRelationshipSchemaType = "GRANT_RELATIONSHIP"
-	GrantAttributeSchemaType    = "GRANT_ATTRIBUTE"
+	AllSchemaType                = "ALL"
+	SchemataSchemaType           = "SCHEMA"
+	ExtensionSchemaType          = "EXTENSION"
+	RoleSchemaType               = "ROLE"
+	ForeignDataWrapperSchemaType = "FOREIGN_DATA_WRAPPER"
+	ServerSchemaType             = "SERVER"
+	UserMappingSchemaType        = "USER_MAPPING"
+	SequenceSchemaType           = "SEQUENCE"
+	EnumSchemaType               = "ENUM"
+	DomainSchemaType             = "DOMAIN"
+	TypeSchemaType               = "TYPE"
+	TableSchemaType              = "TABLE"
+	ForeignTableSchemaType       = "FOREIGN_TABLE"
+	ColumnSchemaType             = "COLUMN"
+	OperatorSchemaType           = "OPERATOR"
+	OperatorClassSchemaType      = "OPERATOR_CLASS"
+	TableColumnSchemaType        = "TABLE_COLUMN"
+	IndexSchemaType              = "INDEX"
+	ViewSchemaType               = "VIEW"
+	MatViewSchemaType            = "MATVIEW"
+	ForeignKeySchemaType         = "FOREIGN_KEY"
+	CheckConstraintSchemaType    = "CHECK_CONSTRAINT"
+	FunctionSchemaType           = "FUNCTION"
+	EventTriggerSchemaType       = "EVENT_TRIGGER"
+	TriggerSchemaType            = "TRIGGER"
+	RuleSchemaType               = "RULE"
+	PolicySchemaType             = "POLICY"
+	PublicationSchemaType        = "PUBLICATION"
+	SubscriptionSchemaType       = "SUBSCRIPTION"
+	OwnerSchemaType              = "OWNER"
+	GrantRelationshipSchemaType  = "GRANT_RELATIONSHIP"
+	GrantAttributeSchemaType     = "GRANT_ATTRIBUTE"
+	CommentSchemaType            = "COMMENT"
 )
 
 var schemaTypes = []string{
 	AllSchemaType,
 	SchemataSchemaType,
+	ExtensionSchemaType,
 	RoleSchemaType,
+	ForeignDataWrapperSchemaType,
+	ServerSchemaType,
+	UserMappingSchemaType,
 	SequenceSchemaType,
+	EnumSchemaType,
+	DomainSchemaType,
+	TypeSchemaType,
 	TableSchemaType,
+	ForeignTableSchemaType,
 	ColumnSchemaType,
 	TableColumnSchemaType,
+	FunctionSchemaType,
+	OperatorSchemaType,
+	OperatorClassSchemaType,
 	IndexSchemaType,
 	ViewSchemaType,
 	MatViewSchemaType,
 	ForeignKeySchemaType,
-	FunctionSchemaType,
+	CheckConstraintSchemaType,
+	EventTriggerSchemaType,
 	TriggerSchemaType,
+	RuleSchemaType,
+	PolicySchemaType,
+	PublicationSchemaType,
+	SubscriptionSchemaType,
 	OwnerSchemaType,
 	GrantRelationshipSchemaType,
 	GrantAttributeSchemaType,
+	CommentSchemaType,
 }
 
 var SchemaTypes = strings.Join(schemaTypes, ", ")
@@ -57,19 +91,36 @@ var SchemaTypes = strings.Join(schemaTypes, ", ")
 // TableColumnSchemaType which is a more restrictive output of ColumnSchemaType.
 var AllSchemaTypes = []string{
 	SchemataSchemaType,
+	ExtensionSchemaType,
 	RoleSchemaType,
+	ForeignDataWrapperSchemaType,
+	ServerSchemaType,
+	UserMappingSchemaType,
 	SequenceSchemaType,
+	EnumSchemaType,
+	DomainSchemaType,
+	TypeSchemaType,
 	TableSchemaType,
+	ForeignTableSchemaType,
 	ColumnSchemaType,
+	FunctionSchemaType,
+	OperatorSchemaType,
+	OperatorClassSchemaType,
 	IndexSchemaType,
 	ViewSchemaType,
 	MatViewSchemaType,
 	ForeignKeySchemaType,
-	FunctionSchemaType,
+	CheckConstraintSchemaType,
+	EventTriggerSchemaType,
 	TriggerSchemaType,
+	RuleSchemaType,
+	PolicySchemaType,
+	PublicationSchemaType,
+	SubscriptionSchemaType,
 	OwnerSchemaType,
 	GrantRelationshipSchemaType,
 	GrantAttributeSchemaType,
+	CommentSchemaType,
 }
 
 type (
@@ -81,44 +132,98 @@ type (
 		Drop() []Stringer
 		Change() []Stringer
 		NextRow() bool
+		// Identity returns the qualified name of the object described by the current row.
+		Identity() string
+		// Row returns a copy of the current row.
+		Row() map[string]string
 	}
 
 	// SchemaFactory instantiates each type of Schema based on a data source.
 	SchemaFactory interface {
 		Schemata() (*SchemataSchema, error)
+		Extension() (*ExtensionSchema, error)
 		Role() (*RoleSchema, error)
+		ForeignDataWrapper() (*ForeignDataWrapperSchema, error)
+		Server() (*ServerSchema, error)
+		UserMapping() (*UserMappingSchema, error)
 		Sequence() (*SequenceSchema, error)
+		Enum() (*EnumSchema, error)
+		Domain() (*DomainSchema, error)
+		Type() (*TypeSchema, error)
 		Table() (*TableSchema, error)
+		ForeignTable() (*ForeignTableSchema, error)
 		Column() (*ColumnSchema, error)
 		TableColumn() (*ColumnSchema, error)
+		Operator() (*OperatorSchema, error)
+		OperatorClass() (*OperatorClassSchema, error)
 		Index() (*IndexSchema, error)
 		View() (*ViewSchema, error)
 		MatView() (*MatViewSchema, error)
 		ForeignKey() (*ForeignKeySchema, error)
+		CheckConstraint() (*CheckConstraintSchema, error)
 		Function() (*FunctionSchema, error)
+		EventTrigger() (*EventTriggerSchema, error)
 		Trigger() (*TriggerSchema, error)
+		Rule() (*RuleSchema, error)
+		Policy() (*PolicySchema, error)
+		Publication() (*PublicationSchema, error)
+		Subscription() (*SubscriptionSchema, error)
 		Owner() (*OwnerSchema, error)
 		GrantRelationship() (*GrantRelationshipSchema, error)
 		GrantAttribute() (*GrantAttributeSchema, error)
+		Comment() (*CommentSchema, error)
 		Identify(num int) *Notice
 	}
 )
 
+// SchemaTypesFromArgs lists the schema types named by args, expanding ALL to AllSchemaTypes.
+func SchemaTypesFromArgs(args []string) []string {
+	var schemaTypes []string
+	for _, arg := range args {
+		if arg == AllSchemaType {
+			schemaTypes = append(schemaTypes, AllSchemaTypes...)
+			continue
+		}
+		schemaTypes = append(schemaTypes, arg)
+	}
+	return schemaTypes
+}
+
 // SchemaByType returns a Schema from factory by schemaType.
 func SchemaByType(factory SchemaFactory, schemaType string) (Schema, error) {
 	switch schemaType {
 	case SchemataSchemaType:
 		return factory.Schemata()
+	case ExtensionSchemaType:
+		return factory.Extension()
 	case RoleSchemaType:
 		return factory.Role()
+	case ForeignDataWrapperSchemaType:
+		return factory.ForeignDataWrapper()
+	case ServerSchemaType:
+		return factory.Server()
+	case UserMappingSchemaType:
+		return factory.UserMapping()
 	case SequenceSchemaType:
 		return factory.Sequence()
+	case EnumSchemaType:
+		return factory.Enum()
+	case DomainSchemaType:
+		return factory.Domain()
+	case TypeSchemaType:
+		return factory.Type()
 	case TableSchemaType:
 		return factory.Table()
+	case ForeignTableSchemaType:
+		return factory.ForeignTable()
 	case ColumnSchemaType:
 		return factory.Column()
 	case TableColumnSchemaType:
 		return factory.TableColumn()
+	case OperatorSchemaType:
+		return factory.Operator()
+	case OperatorClassSchemaType:
+		return factory.OperatorClass()
 	case IndexSchemaType:
 		return factory.Index()
 	case ViewSchemaType:
@@ -127,97 +232,125 @@ func SchemaByType(factory SchemaFactory, schemaType string) (Schema, error) {
 		return factory.MatView()
 	case ForeignKeySchemaType:
 		return factory.ForeignKey()
+	case CheckConstraintSchemaType:
+		return factory.CheckConstraint()
 	case FunctionSchemaType:
 		return factory.Function()
+	case EventTriggerSchemaType:
+		return factory.EventTrigger()
 	case TriggerSchemaType:
 		return factory.Trigger()
+	case RuleSchemaType:
+		return factory.Rule()
+	case PolicySchemaType:
+		return factory.Policy()
+	case PublicationSchemaType:
+		return factory.Publication()
+	case SubscriptionSchemaType:
+		return factory.Subscription()
 	case OwnerSchemaType:
 		return factory.Owner()
 	case GrantRelationshipSchemaType:
 		return factory.GrantRelationship()
 	case GrantAttributeSchemaType:
 		return factory.GrantAttribute()
+	case CommentSchemaType:
+		return factory.Comment()
 	}
 	return nil, NewError(fmt.Sprintf("unsupported schema type: %s", schemaType))
 }
 
 // CompareByFactories runs a single comparison of schemaType between sources represented by fac1 and fac2.
-func CompareByFactories(fac1 SchemaFactory, fac2 SchemaFactory, schemaType string) []Stringer {
-	var strs []Stringer
+func CompareByFactories(fac1 SchemaFactory, fac2 SchemaFactory, schemaType string) *Comparison {
+	comp := &Comparison{SchemaType: schemaType}
+	for i, fac := range []SchemaFactory{fac1, fac2} {
+		if p, ok := fac.(PartialSchemaFactory); ok && !p.Supports(schemaType) {
+			comp.Output = append(comp.Output, NewNotice(fmt.Sprintf("-- Skipping %s: not supported by db%d", schemaType, i+1)))
+		}
+	}
+	if comp.Output != nil {
+		return comp
+	}
 	schema1, err := SchemaByType(fac1, schemaType)
 	if err != nil {
-		strs = append(strs, NewError(err.Error()))
+		comp.Output = append(comp.Output, NewError(err.Error()))
 	}
 	schema2, err := SchemaByType(fac2, schemaType)
 	if err != nil {
-		strs = append(strs, NewError(err.Error()))
+		comp.Output = append(comp.Output, NewError(err.Error()))
+	}
+	if comp.Output != nil {
+		return comp
+	}
+	comp.Changes = Diff(schema1, schema2)
+	for _, change := range comp.Changes {
+		change.SchemaType = schemaType
+	}
+	if schemaType == TableSchemaType {
+		// Diff gives tables in name order, but a parent must be created before the tables that inherit from it or are
+		// its partitions, and dropped after them.
+		comp.Changes = Plan(comp.Changes, tableDependencies(comp.Changes, true), tableDependencies(comp.Changes, false))
 	}
-	diff := Diff(schema1, schema2)
-	strs = append(strs, diff...)
-	return strs
+	return comp
 }
 
 // CompareByFactoriesAndArgs is the main command-line compare function. It runs one comparison between sources
 // represented by fac1 and fac2 for each schema type listed in args.
 func CompareByFactoriesAndArgs(fac1 SchemaFactory, fac2 SchemaFactory, args []string) []Stringer {
-	schemaType := strings.ToUpper(strings.Join(args, " "))
-	strs := []Stringer{
-		NewNotice("-- schemaType: " + schemaType),
-		fac1.Identify(1),
-		fac2.Identify(2),
-		NewNotice("-- Run the following SQL against db2:"),
-	}
-	for _, arg := range args {
-		if arg == AllSchemaType {
-			for _, st := range AllSchemaTypes {
-				strs = append(strs, CompareByFactories(fac1, fac2, st)...)
-			}
-			continue
-		}
-		strs = append(strs, CompareByFactories(fac1, fac2, arg)...)
-	}
-	return strs
+	return ReportByFactoriesAndArgs(fac1, fac2, args).Stringers()
 }
 
 // Diff is a generic diff function that compares tables, columns, indexes, roles, grants, etc.
-// Different behaviors are specified by Schema implementations.
-func Diff(db1 Schema, db2 Schema) []Stringer {
-	var strs []Stringer
-	var s []Stringer
+// Different behaviors are specified by Schema implementations. A Change is returned for every object that generates
+// output. The SchemaType of each Change is left for the caller to fill in.
+func Diff(db1 Schema, db2 Schema) []*Change {
+	var changes []*Change
+	var change *Change
 	more1 := db1.NextRow()
 	more2 := db2.NextRow()
 	for more1 || more2 {
 		compareVal, err := db1.Compare(db2)
-		if err != nil {
-			strs = append(strs, err)
-		}
 		if compareVal == 0 {
 			// table and column match, look for non-identifying changes
-			s = db1.Change()
+			change = &Change{Identity: db2.Identity(), Action: ActionAlter, Before: db2.Row(), After: db1.Row(),
+				Output: db1.Change()}
 			more1 = db1.NextRow()
 			more2 = db2.NextRow()
 		} else if compareVal < 0 {
 			// db2 is missing a value that db1 has
 			if more1 {
-				s = db1.Add()
+				change = added(db1)
 				more1 = db1.NextRow()
 			} else {
 				// db1 is at the end
-				s = db2.Drop()
+				change = dropped(db2)
 				more2 = db2.NextRow()
 			}
 		} else if compareVal > 0 {
 			// db2 has an extra column that we don't want
 			if more2 {
-				s = db2.Drop()
+				change = dropped(db2)
 				more2 = db2.NextRow()
 			} else {
 				// db2 is at the end
-				s = db1.Add()
+				change = added(db1)
 				more1 = db1.NextRow()
 			}
 		}
-		strs = append(strs, s...)
+		if err != nil {
+			change.Output = append([]Stringer{err}, change.Output...)
+		}
+		if len(change.Output) > 0 {
+			changes = append(changes, change)
+		}
 	}
-	return strs
+	return changes
+}
+
+func added(db1 Schema) *Change {
+	return &Change{Identity: db1.Identity(), Action: ActionAdd, After: db1.Row(), Output: db1.Add()}
+}
+
+func dropped(db2 Schema) *Change {
+	return &Change{Identity: db2.Identity(), Action: ActionDrop, Before: db2.Row(), Output: db2.Drop()}
 }
@@ -0,0 +1,138 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"container/heap"
+	"sort"
+)
+
+type (
+	// ObjectRef identifies a database object by SchemaType and Identity, as a Change does.
+	ObjectRef struct {
+		SchemaType string
+		Identity   string
+	}
+
+	// Dependency records that Object cannot exist without Referenced, eg. a view without the table it selects from.
+	Dependency struct {
+		Object     ObjectRef
+		Referenced ObjectRef
+	}
+
+	// DependencySource is implemented by SchemaFactory instances that can report the dependencies between the objects
+	// in their source.
+	DependencySource interface {
+		Dependencies() ([]Dependency, error)
+	}
+
+	// readyQueue is a min-heap of change indexes, so the earliest change that is ready is always taken first.
+	readyQueue []int
+)
+
+// Plan orders changes so that each can be run in turn. Drops come first, in reverse schema type order, with each object
+// dropped before the objects it depends on in db2, as described by deps2. Adds and alters follow, in schema type order,
+// with each object created before the objects that depend on it in db1, as described by deps1. Changes are otherwise
+// kept in their original order. A dependency cycle is broken at its earliest change.
+func Plan(changes []*Change, deps1, deps2 []Dependency) []*Change {
+	rank := map[string]int{}
+	var drops, creates []*Change
+	for _, change := range changes {
+		if _, ok := rank[change.SchemaType]; !ok {
+			rank[change.SchemaType] = len(rank)
+		}
+		if change.Action == ActionDrop {
+			drops = append(drops, change)
+		} else {
+			creates = append(creates, change)
+		}
+	}
+	sort.SliceStable(drops, func(i, j int) bool {
+		return rank[drops[i].SchemaType] > rank[drops[j].SchemaType]
+	})
+	return append(orderByDependencies(drops, deps2, true), orderByDependencies(creates, deps1, false)...)
+}
+
+// DependenciesOf returns the dependencies in the source represented by fac, or nil if it cannot report them.
+func DependenciesOf(fac SchemaFactory) ([]Dependency, error) {
+	ds, ok := fac.(DependencySource)
+	if !ok {
+		return nil, nil
+	}
+	return ds.Dependencies()
+}
+
+// orderByDependencies sorts changes topologically, referenced objects first, or dependent objects first if reverse is
+// set.
+func orderByDependencies(changes []*Change, deps []Dependency, reverse bool) []*Change {
+	pos := map[ObjectRef][]int{}
+	for i, change := range changes {
+		ref := ObjectRef{change.SchemaType, change.Identity}
+		pos[ref] = append(pos[ref], i)
+	}
+	after := make([][]int, len(changes))
+	preds := make([]int, len(changes))
+	for _, dep := range deps {
+		for _, i := range pos[dep.Object] {
+			for _, j := range pos[dep.Referenced] {
+				if i == j {
+					continue
+				}
+				first, second := j, i
+				if reverse {
+					first, second = i, j
+				}
+				after[first] = append(after[first], second)
+				preds[second]++
+			}
+		}
+	}
+
+	ordered := make([]*Change, 0, len(changes))
+	done := make([]bool, len(changes))
+	ready := &readyQueue{}
+	for i := range changes {
+		if preds[i] == 0 {
+			heap.Push(ready, i)
+		}
+	}
+	for len(ordered) < len(changes) {
+		if ready.Len() == 0 {
+			// Every remaining change waits on another, so there is a cycle.
+			for i := range changes {
+				if !done[i] {
+					preds[i] = 0
+					heap.Push(ready, i)
+					break
+				}
+			}
+		}
+		i := heap.Pop(ready).(int)
+		if done[i] {
+			continue
+		}
+		done[i] = true
+		ordered = append(ordered, changes[i])
+		for _, j := range after[i] {
+			preds[j]--
+			if preds[j] == 0 && !done[j] {
+				heap.Push(ready, j)
+			}
+		}
+	}
+	return ordered
+}
+
+func (q readyQueue) Len() int            { return len(q) }
+func (q readyQueue) Less(i, j int) bool  { return q[i] < q[j] }
+func (q readyQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *readyQueue) Push(x interface{}) { *q = append(*q, x.(int)) }
+
+func (q *readyQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	x := old[n-1]
+	*q = old[:n-1]
+	return x
+}
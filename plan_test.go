@@ -0,0 +1,78 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func identities(changes []*Change) []string {
+	var ids []string
+	for _, c := range changes {
+		ids = append(ids, string(c.Action)+" "+c.Identity)
+	}
+	return ids
+}
+
+func dep(schemaType, identity, refSchemaType, refIdentity string) Dependency {
+	return Dependency{ObjectRef{schemaType, identity}, ObjectRef{refSchemaType, refIdentity}}
+}
+
+func TestPlan(t *testing.T) {
+	changes := []*Change{
+		{SchemaType: TableSchemaType, Identity: "public.x", Action: ActionDrop},
+		{SchemaType: ColumnSchemaType, Identity: "public.x.c", Action: ActionDrop},
+		{SchemaType: ColumnSchemaType, Identity: "public.t.c", Action: ActionAdd},
+		{SchemaType: ViewSchemaType, Identity: "public.a", Action: ActionAdd},
+		{SchemaType: ViewSchemaType, Identity: "public.b", Action: ActionAlter},
+		{SchemaType: ViewSchemaType, Identity: "public.y", Action: ActionDrop},
+		{SchemaType: ViewSchemaType, Identity: "public.z", Action: ActionDrop},
+		{SchemaType: FunctionSchemaType, Identity: "public.f", Action: ActionAdd},
+	}
+	deps1 := []Dependency{
+		dep(ViewSchemaType, "public.a", ViewSchemaType, "public.b"),
+		dep(ColumnSchemaType, "public.t.c", FunctionSchemaType, "public.f"),
+	}
+	deps2 := []Dependency{
+		dep(ViewSchemaType, "public.z", ViewSchemaType, "public.y"),
+		dep(ViewSchemaType, "public.y", ColumnSchemaType, "public.x.c"),
+	}
+	assert.Equal(t, []string{
+		"drop public.z",
+		"drop public.y",
+		"drop public.x.c",
+		"drop public.x",
+		"alter public.b",
+		"add public.a",
+		"add public.f",
+		"add public.t.c",
+	}, identities(Plan(changes, deps1, deps2)))
+
+	assert.Equal(t, []string{
+		"drop public.y",
+		"drop public.z",
+		"drop public.x.c",
+		"drop public.x",
+		"add public.t.c",
+		"add public.a",
+		"alter public.b",
+		"add public.f",
+	}, identities(Plan(changes, nil, nil)))
+}
+
+func TestPlanCycle(t *testing.T) {
+	changes := []*Change{
+		{SchemaType: ViewSchemaType, Identity: "public.a", Action: ActionAdd},
+		{SchemaType: ViewSchemaType, Identity: "public.b", Action: ActionAdd},
+		{SchemaType: ViewSchemaType, Identity: "public.c", Action: ActionAdd},
+	}
+	deps := []Dependency{
+		dep(ViewSchemaType, "public.a", ViewSchemaType, "public.b"),
+		dep(ViewSchemaType, "public.b", ViewSchemaType, "public.a"),
+		dep(ViewSchemaType, "public.a", ViewSchemaType, "public.c"),
+	}
+	assert.Equal(t, []string{"add public.c", "add public.a", "add public.b"}, identities(Plan(changes, deps, nil)))
+}
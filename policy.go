@@ -0,0 +1,199 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"fmt"
+
+	"github.com/joncrlsn/misc"
+)
+
+// ==================================
+// PolicyRows definition
+// ==================================
+
+// PolicyRows is a sortable string map
+type PolicyRows []map[string]string
+
+func (slice PolicyRows) Len() int {
+	return len(slice)
+}
+
+func (slice PolicyRows) Less(i, j int) bool {
+	return slice[i]["compare_name"] < slice[j]["compare_name"]
+}
+
+func (slice PolicyRows) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+// ==================================
+// PolicySchema definition
+// (implements Schema -- defined in pgdiff.go)
+// ==================================
+
+// PolicySchema holds a slice of rows from one of the databases as well as
+// a reference to the current row of data we're viewing.
+//
+// There are two kinds of row. A row with a policy_name of "null" holds the row level security settings of a table
+// that has them switched on, every other row holds a policy. A table's row sorts just before its policies.
+type PolicySchema struct {
+	rows     PolicyRows
+	rowNum   int
+	done     bool
+	dbSchema string
+	other    *PolicySchema
+}
+
+func NewPolicySchema(rows PolicyRows, dbSchema string) *PolicySchema {
+	return &PolicySchema{rows: rows, rowNum: -1, dbSchema: dbSchema}
+}
+
+// get returns the value from the current row for the given key
+func (c *PolicySchema) get(key string) string {
+	if c.rowNum >= len(c.rows) {
+		return ""
+	}
+	return c.rows[c.rowNum][key]
+}
+
+// isTable tells you whether the current row holds a table's row level security settings rather than a policy
+func (c *PolicySchema) isTable() bool {
+	return c.get("policy_name") == "null"
+}
+
+// NextRow increments the rowNum and tells you whether or not there are more
+func (c *PolicySchema) NextRow() bool {
+	if c.rowNum >= len(c.rows)-1 {
+		c.done = true
+	}
+	c.rowNum = c.rowNum + 1
+	return !c.done
+}
+
+// Identity returns the qualified name of the current row's table or policy
+func (c *PolicySchema) Identity() string {
+	if c.isTable() {
+		return c.get("schema_name") + "." + c.get("table_name")
+	}
+	return c.get("schema_name") + "." + c.get("table_name") + "." + c.get("policy_name")
+}
+
+// Row returns a copy of the current row
+func (c *PolicySchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
+// Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
+func (c *PolicySchema) Compare(obj Schema) (int, *Error) {
+	c2, ok := obj.(*PolicySchema)
+	if !ok {
+		return +999, NewError(fmt.Sprint("compare(obj) needs a PolicySchema instance", c2))
+	}
+	c.other = c2
+
+	val := misc.CompareStrings(c.get("compare_name"), c.other.get("compare_name"))
+	return val, nil
+}
+
+// table returns the qualified name of the policy's table in db2
+func (c *PolicySchema) table() string {
+	schema := c.other.dbSchema
+	if schema == "*" {
+		schema = c.get("schema_name")
+	}
+	return schema + "." + c.get("table_name")
+}
+
+// Add returns SQL to switch on the table's row level security or to create the policy
+func (c *PolicySchema) Add() []Stringer {
+	if c.isTable() {
+		var strs []Stringer
+		if c.get("row_security") == "YES" {
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER TABLE %s ENABLE ROW LEVEL SECURITY;", c.table())))
+		}
+		if c.get("force_row_security") == "YES" {
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER TABLE %s FORCE ROW LEVEL SECURITY;", c.table())))
+		}
+		return strs
+	}
+	def := fmt.Sprintf("CREATE POLICY %s ON %s AS %s FOR %s TO %s", quoteIdent(c.get("policy_name")), c.table(),
+		c.get("permissive"), c.get("command"), c.get("roles"))
+	if c.get("using_expr") != "null" {
+		def += fmt.Sprintf(" USING (%s)", c.get("using_expr"))
+	}
+	if c.get("check_expr") != "null" {
+		def += fmt.Sprintf(" WITH CHECK (%s)", c.get("check_expr"))
+	}
+	return []Stringer{NewLine(def + ";")}
+}
+
+// Drop returns SQL to switch off the table's row level security or to drop the policy
+func (c PolicySchema) Drop() []Stringer {
+	table := c.get("schema_name") + "." + c.get("table_name")
+	if c.isTable() {
+		var strs []Stringer
+		if c.get("force_row_security") == "YES" {
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER TABLE %s NO FORCE ROW LEVEL SECURITY;", table)))
+		}
+		if c.get("row_security") == "YES" {
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER TABLE %s DISABLE ROW LEVEL SECURITY;", table)))
+		}
+		return strs
+	}
+	return []Stringer{NewLine(fmt.Sprintf("DROP POLICY %s ON %s;", quoteIdent(c.get("policy_name")), table))}
+}
+
+// Change handles the case where the table or policy names match, but the settings do not. The command and kind of a
+// policy cannot be altered and neither can an expression be removed, so in those cases the policy is dropped and
+// created again.
+func (c *PolicySchema) Change() []Stringer {
+	if c.isTable() {
+		return c.changeTable()
+	}
+	name := quoteIdent(c.get("policy_name"))
+	if c.get("command") != c.other.get("command") || c.get("permissive") != c.other.get("permissive") ||
+		(c.get("using_expr") == "null" && c.other.get("using_expr") != "null") ||
+		(c.get("check_expr") == "null" && c.other.get("check_expr") != "null") {
+		strs := []Stringer{NewLine(fmt.Sprintf("DROP POLICY %s ON %s;", name, c.table()))}
+		return append(strs, c.Add()...)
+	}
+	alter := ""
+	if c.get("roles") != c.other.get("roles") {
+		alter += " TO " + c.get("roles")
+	}
+	if c.get("using_expr") != c.other.get("using_expr") {
+		alter += fmt.Sprintf(" USING (%s)", c.get("using_expr"))
+	}
+	if c.get("check_expr") != c.other.get("check_expr") {
+		alter += fmt.Sprintf(" WITH CHECK (%s)", c.get("check_expr"))
+	}
+	if alter == "" {
+		return nil
+	}
+	return []Stringer{NewLine(fmt.Sprintf("ALTER POLICY %s ON %s%s;", name, c.table(), alter))}
+}
+
+// changeTable returns SQL to bring the table's row level security settings in line with db1's
+func (c *PolicySchema) changeTable() []Stringer {
+	var strs []Stringer
+	if c.get("row_security") != c.other.get("row_security") {
+		action := "DISABLE"
+		if c.get("row_security") == "YES" {
+			action = "ENABLE"
+		}
+		strs = append(strs, NewLine(fmt.Sprintf("ALTER TABLE %s %s ROW LEVEL SECURITY;", c.table(), action)))
+	}
+	if c.get("force_row_security") != c.other.get("force_row_security") {
+		action := "NO FORCE"
+		if c.get("force_row_security") == "YES" {
+			action = "FORCE"
+		}
+		strs = append(strs, NewLine(fmt.Sprintf("ALTER TABLE %s %s ROW LEVEL SECURITY;", c.table(), action)))
+	}
+	return strs
+}
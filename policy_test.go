@@ -0,0 +1,58 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func policyRow(command, roles, using, check string) map[string]string {
+	return map[string]string{"compare_name": "s.t.own rows", "schema_name": "s", "table_name": "t", "policy_name": "own rows",
+		"row_security": "null", "force_row_security": "null", "command": command, "permissive": "PERMISSIVE",
+		"roles": roles, "using_expr": using, "check_expr": check}
+}
+
+func rowSecurityRow(enabled, forced string) map[string]string {
+	return map[string]string{"compare_name": "s.t", "schema_name": "s", "table_name": "t", "policy_name": "null",
+		"row_security": enabled, "force_row_security": forced}
+}
+
+func TestPolicy(t *testing.T) {
+	assert.Equal(t, []Stringer{
+		NewLine("ALTER TABLE s.t ENABLE ROW LEVEL SECURITY;"),
+		NewLine(`CREATE POLICY "own rows" ON s.t AS PERMISSIVE FOR SELECT TO PUBLIC USING ((owner = CURRENT_USER));`),
+	}, diffOutput(NewPolicySchema(PolicyRows{
+		rowSecurityRow("YES", "NO"),
+		policyRow("SELECT", "PUBLIC", "(owner = CURRENT_USER)", "null"),
+	}, "*"), NewPolicySchema(nil, "*")))
+	assert.Equal(t, []Stringer{
+		NewLine("ALTER TABLE s.t NO FORCE ROW LEVEL SECURITY;"),
+		NewLine("ALTER TABLE s.t DISABLE ROW LEVEL SECURITY;"),
+		NewLine(`DROP POLICY "own rows" ON s.t;`),
+	}, diffOutput(NewPolicySchema(nil, "*"),
+		NewPolicySchema(PolicyRows{rowSecurityRow("YES", "YES"), policyRow("ALL", "PUBLIC", "true", "null")}, "*")))
+
+	assert.Equal(t, []Stringer{NewLine("ALTER TABLE s.t FORCE ROW LEVEL SECURITY;")},
+		diffOutput(NewPolicySchema(PolicyRows{rowSecurityRow("YES", "YES")}, "*"),
+			NewPolicySchema(PolicyRows{rowSecurityRow("YES", "NO")}, "*")))
+	assert.Empty(t, diffOutput(NewPolicySchema(PolicyRows{policyRow("ALL", "u1", "true", "null")}, "*"),
+		NewPolicySchema(PolicyRows{policyRow("ALL", "u1", "true", "null")}, "*")))
+
+	assert.Equal(t, []Stringer{NewLine(`ALTER POLICY "own rows" ON s.t TO u1, u2 WITH CHECK ((id > 0));`)},
+		diffOutput(NewPolicySchema(PolicyRows{policyRow("ALL", "u1, u2", "true", "(id > 0)")}, "*"),
+			NewPolicySchema(PolicyRows{policyRow("ALL", "u1", "true", "null")}, "*")))
+
+	assert.Equal(t, []Stringer{
+		NewLine(`DROP POLICY "own rows" ON s.t;`),
+		NewLine(`CREATE POLICY "own rows" ON s.t AS PERMISSIVE FOR INSERT TO u1 WITH CHECK (true);`),
+	}, diffOutput(NewPolicySchema(PolicyRows{policyRow("INSERT", "u1", "null", "true")}, "*"),
+		NewPolicySchema(PolicyRows{policyRow("ALL", "u1", "true", "true")}, "*")))
+	assert.Equal(t, []Stringer{
+		NewLine(`DROP POLICY "own rows" ON s.t;`),
+		NewLine(`CREATE POLICY "own rows" ON s.t AS PERMISSIVE FOR ALL TO u1 USING (true);`),
+	}, diffOutput(NewPolicySchema(PolicyRows{policyRow("ALL", "u1", "true", "null")}, "*"),
+		NewPolicySchema(PolicyRows{policyRow("ALL", "u1", "true", "true")}, "*")))
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/joncrlsn/misc"
+)
+
+// ==================================
+// PublicationRows definition
+// ==================================
+
+// PublicationRows is a sortable string map
+type PublicationRows []map[string]string
+
+func (slice PublicationRows) Len() int {
+	return len(slice)
+}
+
+func (slice PublicationRows) Less(i, j int) bool {
+	return slice[i]["compare_name"] < slice[j]["compare_name"]
+}
+
+func (slice PublicationRows) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+// publishedTable is a table of a publication, as held in the JSON array of a row's tables. name is quoted and
+// qualified and where is the row filter, if any.
+type publishedTable struct {
+	Name  string `json:"name"`
+	Where string `json:"where"`
+}
+
+// clause returns the table as ADD TABLE expects it
+func (t publishedTable) clause() string {
+	if t.Where == "" {
+		return t.Name
+	}
+	return t.Name + " WHERE " + t.Where
+}
+
+// parsePublishedTables reads the tables of a publication from the JSON array raw
+func parsePublishedTables(raw string) ([]publishedTable, error) {
+	var tables []publishedTable
+	err := json.Unmarshal([]byte(raw), &tables)
+	if err != nil {
+		return nil, fmt.Errorf("reading publication tables %s: %s", raw, err)
+	}
+	return tables, nil
+}
+
+// ==================================
+// PublicationSchema definition
+// (implements Schema -- defined in pgdiff.go)
+// ==================================
+
+// PublicationSchema holds a slice of rows from one of the databases as well as
+// a reference to the current row of data we're viewing.
+//
+// Each row holds a whole publication: the operations it publishes, its tables in a JSON array and the schemas whose
+// tables it publishes as a comma separated list.
+type PublicationSchema struct {
+	rows     PublicationRows
+	rowNum   int
+	done     bool
+	dbSchema string
+	other    *PublicationSchema
+}
+
+func NewPublicationSchema(rows PublicationRows, dbSchema string) *PublicationSchema {
+	return &PublicationSchema{rows: rows, rowNum: -1, dbSchema: dbSchema}
+}
+
+// get returns the value from the current row for the given key
+func (c *PublicationSchema) get(key string) string {
+	if c.rowNum >= len(c.rows) {
+		return ""
+	}
+	return c.rows[c.rowNum][key]
+}
+
+// NextRow increments the rowNum and tells you whether or not there are more
+func (c *PublicationSchema) NextRow() bool {
+	if c.rowNum >= len(c.rows)-1 {
+		c.done = true
+	}
+	c.rowNum = c.rowNum + 1
+	return !c.done
+}
+
+// Identity returns the name of the current row's publication, which is unique within the database
+func (c *PublicationSchema) Identity() string {
+	return c.get("pub_name")
+}
+
+// Row returns a copy of the current row
+func (c *PublicationSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
+// Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
+func (c *PublicationSchema) Compare(obj Schema) (int, *Error) {
+	c2, ok := obj.(*PublicationSchema)
+	if !ok {
+		return +999, NewError(fmt.Sprint("compare(obj) needs a PublicationSchema instance", c2))
+	}
+	c.other = c2
+
+	val := misc.CompareStrings(c.get("compare_name"), c.other.get("compare_name"))
+	return val, nil
+}
+
+// schemas returns the schemas whose tables the publication publishes
+func (c *PublicationSchema) schemas() []string {
+	if c.get("schemas") == "null" {
+		return nil
+	}
+	return strings.Split(c.get("schemas"), ", ")
+}
+
+// with returns the publication's parameters as WITH and SET expect them
+func (c *PublicationSchema) with() string {
+	return fmt.Sprintf("(publish = %s, publish_via_partition_root = %t)", quoteLiteral(c.get("publish")),
+		c.get("via_root") == "YES")
+}
+
+// Add returns SQL to create the publication
+func (c *PublicationSchema) Add() []Stringer {
+	tables, err := parsePublishedTables(c.get("tables"))
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	var objects []string
+	for _, t := range tables {
+		objects = append(objects, "TABLE "+t.clause())
+	}
+	for _, schema := range c.schemas() {
+		objects = append(objects, "TABLES IN SCHEMA "+schema)
+	}
+	def := "CREATE PUBLICATION " + quoteIdent(c.get("pub_name"))
+	if c.get("all_tables") == "YES" {
+		def += " FOR ALL TABLES"
+	} else if len(objects) > 0 {
+		def += " FOR " + strings.Join(objects, ", ")
+	}
+	return []Stringer{NewLine(def + " WITH " + c.with() + ";")}
+}
+
+// Drop returns SQL to drop the publication
+func (c PublicationSchema) Drop() []Stringer {
+	return []Stringer{NewLine(fmt.Sprintf("DROP PUBLICATION %s;", quoteIdent(c.get("pub_name"))))}
+}
+
+// Change handles the case where the publication names match, but the tables, schemas or parameters do not. A
+// publication cannot be changed to or from FOR ALL TABLES, so a warning is given instead. A table whose row filter
+// differs is dropped and added again.
+func (c *PublicationSchema) Change() []Stringer {
+	tables1, err := parsePublishedTables(c.get("tables"))
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	tables2, err := parsePublishedTables(c.other.get("tables"))
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	var strs []Stringer
+	name := quoteIdent(c.get("pub_name"))
+	if c.get("all_tables") != c.other.get("all_tables") {
+		strs = append(strs, NewWarning(fmt.Sprintf("-- WARNING: publication %s is FOR ALL TABLES in only one of db1 and "+
+			"db2. PostgreSQL cannot change that, so the publication must be dropped and created again.", name)))
+	}
+	if c.get("publish") != c.other.get("publish") || c.get("via_root") != c.other.get("via_root") {
+		strs = append(strs, NewLine(fmt.Sprintf("ALTER PUBLICATION %s SET %s;", name, c.with())))
+	}
+
+	for _, t := range tables2 {
+		if where, ok := whereOf(tables1, t.Name); !ok || where != t.Where {
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER PUBLICATION %s DROP TABLE %s;", name, t.Name)))
+		}
+	}
+	for _, t := range tables1 {
+		if where, ok := whereOf(tables2, t.Name); !ok || where != t.Where {
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER PUBLICATION %s ADD TABLE %s;", name, t.clause())))
+		}
+	}
+
+	schemas2 := c.other.schemas()
+	for _, schema := range schemas2 {
+		if !contains(c.schemas(), schema) {
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER PUBLICATION %s DROP TABLES IN SCHEMA %s;", name, schema)))
+		}
+	}
+	for _, schema := range c.schemas() {
+		if !contains(schemas2, schema) {
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER PUBLICATION %s ADD TABLES IN SCHEMA %s;", name, schema)))
+		}
+	}
+	return strs
+}
+
+// whereOf returns the row filter of the table named name in tables and whether tables has it
+func whereOf(tables []publishedTable, name string) (string, bool) {
+	for _, t := range tables {
+		if t.Name == name {
+			return t.Where, true
+		}
+	}
+	return "", false
+}
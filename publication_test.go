@@ -0,0 +1,49 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func publicationRow(allTables, publish, tables, schemas string) map[string]string {
+	return map[string]string{"compare_name": "pub", "pub_name": "pub", "all_tables": allTables, "publish": publish,
+		"via_root": "NO", "tables": tables, "schemas": schemas}
+}
+
+func TestPublication(t *testing.T) {
+	assert.Equal(t, []Stringer{
+		NewLine("CREATE PUBLICATION pub FOR TABLE s.a WHERE (id > 0), TABLE s.b, TABLES IN SCHEMA s2 " +
+			"WITH (publish = 'insert, update', publish_via_partition_root = false);"),
+	}, diffOutput(NewPublicationSchema(PublicationRows{publicationRow("NO", "insert, update",
+		`[{"name":"s.a","where":"(id > 0)"},{"name":"s.b","where":null}]`, "s2")}, "*"),
+		NewPublicationSchema(nil, "*")))
+	assert.Equal(t, []Stringer{
+		NewLine("CREATE PUBLICATION pub FOR ALL TABLES WITH (publish = 'insert, update, delete, truncate', " +
+			"publish_via_partition_root = false);"),
+	}, diffOutput(NewPublicationSchema(PublicationRows{
+		publicationRow("YES", "insert, update, delete, truncate", "[]", "null"),
+	}, "*"), NewPublicationSchema(nil, "*")))
+	assert.Equal(t, []Stringer{NewLine("DROP PUBLICATION pub;")},
+		diffOutput(NewPublicationSchema(nil, "*"),
+			NewPublicationSchema(PublicationRows{publicationRow("NO", "insert", "[]", "null")}, "*")))
+
+	tables := `[{"name":"s.a","where":"(id > 0)"},{"name":"s.b","where":null}]`
+	assert.Empty(t, diffOutput(NewPublicationSchema(PublicationRows{publicationRow("NO", "insert", tables, "s2")}, "*"),
+		NewPublicationSchema(PublicationRows{publicationRow("NO", "insert", tables, "s2")}, "*")))
+	assert.Equal(t, []Stringer{
+		NewLine("ALTER PUBLICATION pub SET (publish = 'insert', publish_via_partition_root = false);"),
+		NewLine("ALTER PUBLICATION pub DROP TABLE s.a;"),
+		NewLine("ALTER PUBLICATION pub DROP TABLE s.c;"),
+		NewLine("ALTER PUBLICATION pub ADD TABLE s.a WHERE (id > 1);"),
+		NewLine("ALTER PUBLICATION pub ADD TABLE s.b;"),
+		NewLine("ALTER PUBLICATION pub DROP TABLES IN SCHEMA s2;"),
+		NewLine("ALTER PUBLICATION pub ADD TABLES IN SCHEMA s3;"),
+	}, diffOutput(NewPublicationSchema(PublicationRows{publicationRow("NO", "insert",
+		`[{"name":"s.a","where":"(id > 1)"},{"name":"s.b","where":null}]`, "s3")}, "*"),
+		NewPublicationSchema(PublicationRows{publicationRow("NO", "insert, update",
+			`[{"name":"s.a","where":"(id > 0)"},{"name":"s.c","where":null}]`, "s2")}, "*")))
+}
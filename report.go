@@ -0,0 +1,211 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type (
+	// Report is the result of every comparison run by the main command-line compare function.
+	Report struct {
+		// SchemaType is the schema type argument, or arguments, the comparisons were requested with.
+		SchemaType string
+		// Sources identifies each source, see SchemaFactory.Identify.
+		Sources []*Notice
+		// Output is any Error reported while loading a source, see LoadErrorReporter, or its dependencies, see
+		// DependencySource.
+		Output      []Stringer
+		Comparisons []*Comparison
+		// Plan is every Change of every Comparison in the order they should be run, see Plan.
+		Plan []*Change
+	}
+
+	jsonReport struct {
+		SchemaType  string       `json:"schemaType"`
+		Sources     []string     `json:"sources"`
+		SchemaTypes []string     `json:"schemaTypes"`
+		Output      []jsonOutput `json:"output"`
+	}
+
+	jsonOutput struct {
+		Type       string `json:"type"`
+		SchemaType string `json:"schemaType,omitempty"`
+		Identity   string `json:"identity,omitempty"`
+		Action     Action `json:"action,omitempty"`
+		Text       string `json:"text"`
+	}
+)
+
+// ReportByFactoriesAndArgs runs one comparison between sources represented by fac1 and fac2 for each schema type
+// listed in args.
+func ReportByFactoriesAndArgs(fac1 SchemaFactory, fac2 SchemaFactory, args []string) *Report {
+	r := &Report{
+		SchemaType: strings.ToUpper(strings.Join(args, " ")),
+		Sources:    []*Notice{fac1.Identify(1), fac2.Identify(2)},
+	}
+	for _, fac := range []SchemaFactory{fac1, fac2} {
+		if lr, ok := fac.(LoadErrorReporter); ok {
+			for _, err := range lr.LoadErrors() {
+				r.Output = append(r.Output, err)
+			}
+		}
+	}
+	var changes []*Change
+	for _, schemaType := range SchemaTypesFromArgs(args) {
+		comp := CompareByFactories(fac1, fac2, schemaType)
+		r.Comparisons = append(r.Comparisons, comp)
+		changes = append(changes, comp.Changes...)
+	}
+	if len(changes) > 0 {
+		deps1 := r.dependencies(fac1, 1)
+		deps2 := r.dependencies(fac2, 2)
+		r.Plan = Plan(changes, deps1, deps2)
+	}
+	return r
+}
+
+// dependencies returns the dependencies in the source represented by fac, if it can report them.
+func (r *Report) dependencies(fac SchemaFactory, num int) []Dependency {
+	deps, err := DependenciesOf(fac)
+	if err != nil {
+		r.Output = append(r.Output, NewError(fmt.Sprintf("reading dependencies of db%d: %s", num, err)))
+	}
+	return deps
+}
+
+// Stringers returns the plain text output of the report: the output of each Comparison that does not belong to a
+// single Change, followed by the output of each Change in Plan order.
+func (r *Report) Stringers() []Stringer {
+	strs := r.header()
+	strs = append(strs, NewNotice("-- Run the following SQL against db2:"))
+	for _, comp := range r.Comparisons {
+		strs = append(strs, comp.Output...)
+	}
+	for _, change := range r.Plan {
+		strs = append(strs, change.Output...)
+	}
+	return strs
+}
+
+// header identifies the report and its sources, followed by any errors loading the sources.
+func (r *Report) header() []Stringer {
+	strs := []Stringer{NewNotice("-- schemaType: " + r.SchemaType)}
+	for _, s := range r.Sources {
+		strs = append(strs, s)
+	}
+	return append(strs, r.Output...)
+}
+
+// Summary returns the plain text output of the report with the output of each Change replaced by its action and
+// identity, under a count of the objects added, dropped and altered for each schema type. Errors are kept.
+func (r *Report) Summary() []Stringer {
+	strs := r.header()
+	for _, comp := range r.Comparisons {
+		if comp.Output != nil {
+			strs = append(strs, comp.Output...)
+			continue
+		}
+		strs = append(strs, NewNotice(fmt.Sprintf("-- %s: %d added, %d dropped, %d altered", comp.SchemaType,
+			comp.Count(ActionAdd), comp.Count(ActionDrop), comp.Count(ActionAlter))))
+		for _, change := range comp.Changes {
+			strs = append(strs, NewNotice(fmt.Sprintf("--   %s %s", change.Action, change.Identity)))
+			for _, s := range change.Output {
+				if e, ok := s.(*Error); ok {
+					strs = append(strs, e)
+				}
+			}
+		}
+	}
+	return strs
+}
+
+// Drifted reports whether any object differs between the sources.
+func (r *Report) Drifted() bool {
+	for _, comp := range r.Comparisons {
+		if len(comp.Changes) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Failed reports whether any Error was encountered, in which case the report may be incomplete.
+func (r *Report) Failed() bool {
+	if len(r.Output) > 0 {
+		return true
+	}
+	for _, comp := range r.Comparisons {
+		for _, s := range comp.Stringers() {
+			if _, ok := s.(*Error); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PrintJSON prints the report to out as a single JSON document, filtering statements, notices and warnings based on the
+// corresponding bits set in the OutputSet o. Every Error is included in the document and also printed to err.
+func (r *Report) PrintJSON(o OutputSet, out io.Writer, err io.Writer) error {
+	doc := jsonReport{
+		SchemaType:  r.SchemaType,
+		Sources:     make([]string, 0, len(r.Sources)),
+		SchemaTypes: make([]string, 0, len(r.Comparisons)),
+		Output:      []jsonOutput{},
+	}
+	for _, s := range r.Sources {
+		doc.Sources = append(doc.Sources, strings.TrimPrefix(s.String(), "-- "))
+	}
+	add := func(s Stringer, schemaType string, change *Change) {
+		jo := jsonOutput{SchemaType: schemaType, Text: s.String()}
+		switch s.(type) {
+		case *Line:
+			if o&OutputLine == 0 {
+				return
+			}
+			jo.Type = "line"
+		case *Notice, *Warning:
+			if o&OutputNotice == 0 {
+				return
+			}
+			jo.Type = "notice"
+			if _, ok := s.(*Warning); ok {
+				jo.Type = "warning"
+			}
+		case *Error:
+			fmt.Fprintln(err, s.String())
+			jo.Type = "error"
+		}
+		if change != nil {
+			jo.Identity = change.Identity
+			jo.Action = change.Action
+		}
+		doc.Output = append(doc.Output, jo)
+	}
+	for _, s := range r.Output {
+		add(s, "", nil)
+	}
+	for _, comp := range r.Comparisons {
+		doc.SchemaTypes = append(doc.SchemaTypes, comp.SchemaType)
+		for _, s := range comp.Output {
+			add(s, comp.SchemaType, nil)
+		}
+	}
+	for _, change := range r.Plan {
+		for _, s := range change.Output {
+			add(s, change.SchemaType, change)
+		}
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	e := enc.Encode(doc)
+	if e != nil {
+		return NewError("encoding JSON: " + e.Error())
+	}
+	return nil
+}
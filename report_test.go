@@ -0,0 +1,80 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testReport() *Report {
+	change := &Change{
+		SchemaType: TableSchemaType,
+		Identity:   "public.t",
+		Action:     ActionAdd,
+		Output:     []Stringer{NewLine("CREATE TABLE public.t();")},
+	}
+	return &Report{
+		SchemaType: "TABLE COLUMN",
+		Sources:    []*Notice{NewNotice("-- dump1: a.sql"), NewNotice("-- dump2: b.sql")},
+		Output:     []Stringer{NewError("loading x.sql: oops")},
+		Comparisons: []*Comparison{
+			{SchemaType: TableSchemaType, Changes: []*Change{change}},
+			{SchemaType: ColumnSchemaType, Output: []Stringer{NewNotice("-- Skipping COLUMN: not supported by db2")}},
+		},
+		Plan: []*Change{change},
+	}
+}
+
+func TestReportStringers(t *testing.T) {
+	assert.Equal(t, []Stringer{
+		NewNotice("-- schemaType: TABLE COLUMN"),
+		NewNotice("-- dump1: a.sql"),
+		NewNotice("-- dump2: b.sql"),
+		NewError("loading x.sql: oops"),
+		NewNotice("-- Run the following SQL against db2:"),
+		NewNotice("-- Skipping COLUMN: not supported by db2"),
+		NewLine("CREATE TABLE public.t();"),
+	}, testReport().Stringers())
+}
+
+func TestReportPrintJSON(t *testing.T) {
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	err := testReport().PrintJSON(OutputLine, out, errOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.JSONEq(t, `{
+  "schemaType": "TABLE COLUMN",
+  "sources": ["dump1: a.sql", "dump2: b.sql"],
+  "schemaTypes": ["TABLE", "COLUMN"],
+  "output": [
+    {"type": "error", "text": "loading x.sql: oops"},
+    {"type": "line", "schemaType": "TABLE", "identity": "public.t", "action": "add", "text": "CREATE TABLE public.t();"}
+  ]
+}`, out.String())
+	assert.Equal(t, "loading x.sql: oops\n", errOut.String())
+}
+
+func TestReportSummary(t *testing.T) {
+	r := testReport()
+	assert.Equal(t, []Stringer{
+		NewNotice("-- schemaType: TABLE COLUMN"),
+		NewNotice("-- dump1: a.sql"),
+		NewNotice("-- dump2: b.sql"),
+		NewError("loading x.sql: oops"),
+		NewNotice("-- TABLE: 1 added, 0 dropped, 0 altered"),
+		NewNotice("--   add public.t"),
+		NewNotice("-- Skipping COLUMN: not supported by db2"),
+	}, r.Summary())
+	assert.True(t, r.Drifted())
+	assert.True(t, r.Failed())
+
+	r.Output = nil
+	assert.False(t, r.Failed())
+	r.Comparisons = r.Comparisons[1:]
+	assert.False(t, r.Drifted())
+}
@@ -76,6 +76,19 @@ func (c *RoleSchema) NextRow() bool {
 	return !c.done
 }
 
+// Identity returns the qualified name of the current row's object
+func (c *RoleSchema) Identity() string {
+	return c.get("rolname")
+}
+
+// Row returns a copy of the current row
+func (c *RoleSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
 // Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
 func (c *RoleSchema) Compare(obj Schema) (int, *Error) {
 	c2, ok := obj.(*RoleSchema)
@@ -0,0 +1,134 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/joncrlsn/misc"
+)
+
+// ruleTable matches a rule definition up to the table the rule is on, which pg_get_ruledef qualifies only when its
+// schema is not on the search path, capturing everything before the table name.
+var ruleTable = regexp.MustCompile(`^(?s)(CREATE RULE\s+(?:"(?:[^"]|"")*"|\S+)\s+AS\s+ON\s+\w+\s+TO\s+)` +
+	`(?:(?:"(?:[^"]|"")*"|[^\s."]+)\.)?(?:"(?:[^"]|"")*"|[^\s."]+)`)
+
+// ==================================
+// RuleRows definition
+// ==================================
+
+// RuleRows is a sortable string map
+type RuleRows []map[string]string
+
+func (slice RuleRows) Len() int {
+	return len(slice)
+}
+
+func (slice RuleRows) Less(i, j int) bool {
+	return slice[i]["compare_name"] < slice[j]["compare_name"]
+}
+
+func (slice RuleRows) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+// ==================================
+// RuleSchema definition
+// (implements Schema -- defined in pgdiff.go)
+// ==================================
+
+// RuleSchema holds a slice of rows from one of the databases as well as
+// a reference to the current row of data we're viewing.
+//
+// Each row holds a rewrite rule other than the _RETURN rule of a view, with rule_def as pg_get_ruledef renders it,
+// less the final semicolon.
+type RuleSchema struct {
+	rows     RuleRows
+	rowNum   int
+	done     bool
+	dbSchema string
+	other    *RuleSchema
+}
+
+func NewRuleSchema(rows RuleRows, dbSchema string) *RuleSchema {
+	return &RuleSchema{rows: rows, rowNum: -1, dbSchema: dbSchema}
+}
+
+// get returns the value from the current row for the given key
+func (c *RuleSchema) get(key string) string {
+	if c.rowNum >= len(c.rows) {
+		return ""
+	}
+	return c.rows[c.rowNum][key]
+}
+
+// NextRow increments the rowNum and tells you whether or not there are more
+func (c *RuleSchema) NextRow() bool {
+	if c.rowNum >= len(c.rows)-1 {
+		c.done = true
+	}
+	c.rowNum = c.rowNum + 1
+	return !c.done
+}
+
+// Identity returns the qualified name of the current row's rule
+func (c *RuleSchema) Identity() string {
+	return c.get("schema_name") + "." + c.get("table_name") + "." + c.get("rule_name")
+}
+
+// Row returns a copy of the current row
+func (c *RuleSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
+// Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
+func (c *RuleSchema) Compare(obj Schema) (int, *Error) {
+	c2, ok := obj.(*RuleSchema)
+	if !ok {
+		return +999, NewError(fmt.Sprint("compare(obj) needs a RuleSchema instance", c2))
+	}
+	c.other = c2
+
+	val := misc.CompareStrings(c.get("compare_name"), c.other.get("compare_name"))
+	return val, nil
+}
+
+// table returns the qualified name of the rule's table in db2
+func (c *RuleSchema) table() string {
+	schema := c.other.dbSchema
+	if schema == "*" {
+		schema = c.get("schema_name")
+	}
+	return quoteIdent(schema) + "." + quoteIdent(c.get("table_name"))
+}
+
+// Add returns SQL to create the rule on its table in db2's schema. The table named in the definition is replaced, as
+// it may be unqualified or in the schema of db1.
+func (c *RuleSchema) Add() []Stringer {
+	def := c.get("rule_def")
+	if m := ruleTable.FindStringSubmatchIndex(def); m != nil {
+		def = def[:m[3]] + c.table() + def[m[1]:]
+	}
+	return []Stringer{NewLine("CREATE OR REPLACE RULE" + strings.TrimPrefix(def, "CREATE RULE") + ";")}
+}
+
+// Drop returns SQL to drop the rule
+func (c RuleSchema) Drop() []Stringer {
+	return []Stringer{NewLine(fmt.Sprintf("DROP RULE %s ON %s.%s;", quoteIdent(c.get("rule_name")), c.get("schema_name"),
+		c.get("table_name")))}
+}
+
+// Change handles the case where the table and rule names match, but the definition does not. CREATE OR REPLACE RULE
+// replaces the whole rule, so nothing needs to be dropped.
+func (c *RuleSchema) Change() []Stringer {
+	if c.get("rule_def") == c.other.get("rule_def") {
+		return nil
+	}
+	return c.Add()
+}
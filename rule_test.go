@@ -0,0 +1,45 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ruleRow(schema, def string) map[string]string {
+	return map[string]string{"compare_name": "log.no_insert", "schema_name": schema, "table_name": "log",
+		"rule_name": "no_insert", "rule_def": def}
+}
+
+func TestRule(t *testing.T) {
+	def := "CREATE RULE no_insert AS ON INSERT TO s1.log DO INSTEAD NOTHING"
+	assert.Equal(t, []Stringer{NewLine("CREATE OR REPLACE RULE no_insert AS ON INSERT TO s1.log DO INSTEAD NOTHING;")},
+		diffOutput(NewRuleSchema(RuleRows{ruleRow("s1", def)}, "s1"), NewRuleSchema(nil, "s1")))
+	assert.Equal(t, []Stringer{NewLine("CREATE OR REPLACE RULE no_insert AS ON INSERT TO s2.log DO INSTEAD NOTHING;")},
+		diffOutput(NewRuleSchema(RuleRows{ruleRow("s1", def)}, "s1"), NewRuleSchema(nil, "s2")))
+	assert.Equal(t, []Stringer{NewLine("DROP RULE no_insert ON s1.log;")},
+		diffOutput(NewRuleSchema(nil, "s1"), NewRuleSchema(RuleRows{ruleRow("s1", def)}, "s1")))
+
+	assert.Empty(t, diffOutput(NewRuleSchema(RuleRows{ruleRow("s1", def)}, "s1"),
+		NewRuleSchema(RuleRows{ruleRow("s1", def)}, "s1")))
+	assert.Equal(t, []Stringer{NewLine("CREATE OR REPLACE RULE no_insert AS ON INSERT TO s1.log DO INSTEAD NOTHING;")},
+		diffOutput(NewRuleSchema(RuleRows{ruleRow("s1", def)}, "s1"),
+			NewRuleSchema(RuleRows{ruleRow("s1", "CREATE RULE no_insert AS ON INSERT TO s1.log DO NOTHING")}, "s1")))
+
+	// pg_get_ruledef leaves the table unqualified when its schema is on the search path.
+	def = "CREATE RULE no_insert AS\n    ON INSERT TO log DO INSTEAD NOTHING"
+	assert.Equal(t, []Stringer{NewLine("CREATE OR REPLACE RULE no_insert AS\n    ON INSERT TO s1.log DO INSTEAD NOTHING;")},
+		diffOutput(NewRuleSchema(RuleRows{ruleRow("s1", def)}, "*"), NewRuleSchema(nil, "*")))
+	assert.Equal(t, []Stringer{NewLine("CREATE OR REPLACE RULE no_insert AS\n    ON INSERT TO s2.log DO INSTEAD NOTHING;")},
+		diffOutput(NewRuleSchema(RuleRows{ruleRow("s1", def)}, "s1"), NewRuleSchema(nil, "s2")))
+
+	// The table name is replaced even where its text appears elsewhere in the definition.
+	row := ruleRow("s1", `CREATE RULE "to log" AS ON UPDATE TO s1."Log" WHERE (old.note = ' TO s1.Log ') DO INSTEAD NOTHING`)
+	row["table_name"] = "Log"
+	assert.Equal(t, []Stringer{NewLine(`CREATE OR REPLACE RULE "to log" AS ON UPDATE TO s2."Log" ` +
+		`WHERE (old.note = ' TO s1.Log ') DO INSTEAD NOTHING;`)}, diffOutput(NewRuleSchema(RuleRows{row}, "s1"),
+		NewRuleSchema(nil, "s2")))
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package schemadir
+
+import (
+	"testing"
+
+	"github.com/facefunk/pgdiff"
+	"github.com/facefunk/pgdiff/db"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	_ pgdiff.Module            = (*Module)(nil)
+	_ pgdiff.Config            = (*Config)(nil)
+	_ pgdiff.LoadErrorReporter = (*factory)(nil)
+)
+
+func TestYAML(t *testing.T) {
+	in := `
+schemadir2:
+  dir: schema
+db2:
+  host: testy.com
+`
+	dbModule := &db.Module{}
+	err := yaml.Unmarshal([]byte(in), dbModule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := NewModule(dbModule)
+	err = yaml.Unmarshal([]byte(in), out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := out.Config(2).(*Config)
+	assert.Equal(t, "schema", conf.Dir)
+	assert.Equal(t, "testy.com", conf.server.DbHost)
+	assert.Equal(t, "", out.Config(1).(*Config).Dir)
+}
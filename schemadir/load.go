@@ -0,0 +1,79 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package schemadir
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/facefunk/pgdiff"
+)
+
+type (
+	// executor runs a file's SQL so that it either entirely succeeds or leaves nothing behind.
+	executor interface {
+		ExecTx(query string) error
+	}
+
+	// file is a single object definition file.
+	file struct {
+		path string
+		sql  string
+	}
+)
+
+// readDir reads every .sql file under dir in lexical order.
+func readDir(dir string) ([]file, error) {
+	var files []file
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".sql") {
+			return nil
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files = append(files, file{path, string(b)})
+		return nil
+	})
+	if err != nil {
+		return nil, pgdiff.NewError("reading schema directory: " + err.Error())
+	}
+	return files, nil
+}
+
+// load runs every file, then runs the files that failed again for as long as each pass lets at least one more file
+// succeed. Files usually fail because they depend on an object defined in a file that has not run yet, such as a view
+// selecting from another view, so the files that remain once a pass makes no progress are broken or circular. An error
+// is returned for each of them.
+func load(exec executor, files []file) []*pgdiff.Error {
+	pending := files
+	var errs []error
+	for len(pending) > 0 {
+		var failed []file
+		errs = nil
+		for _, f := range pending {
+			err := exec.ExecTx(f.sql)
+			if err != nil {
+				failed = append(failed, f)
+				errs = append(errs, err)
+			}
+		}
+		if len(failed) == len(pending) {
+			break
+		}
+		pending = failed
+	}
+	var loadErrs []*pgdiff.Error
+	for i, f := range pending {
+		loadErrs = append(loadErrs, pgdiff.NewError(fmt.Sprintf("loading %s: %s", f.path, errs[i])))
+	}
+	return loadErrs
+}
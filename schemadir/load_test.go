@@ -0,0 +1,41 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package schemadir
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testExecutor runs "CREATE name" and "CREATE name USING dep" statements, failing those whose dependency is missing.
+type testExecutor struct {
+	created map[string]bool
+	order   []string
+}
+
+func (e *testExecutor) ExecTx(query string) error {
+	fields := strings.Fields(query)
+	if len(fields) == 4 && !e.created[fields[3]] {
+		return errors.New(fields[3] + " does not exist")
+	}
+	e.created[fields[1]] = true
+	e.order = append(e.order, fields[1])
+	return nil
+}
+
+func TestLoad(t *testing.T) {
+	exec := &testExecutor{created: map[string]bool{}}
+	errs := load(exec, []file{
+		{"views/a.sql", "CREATE a USING b"},
+		{"views/b.sql", "CREATE b USING c"},
+		{"tables/c.sql", "CREATE c"},
+		{"views/d.sql", "CREATE d USING e"},
+	})
+	assert.Equal(t, []string{"c", "b", "a"}, exec.order)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "loading views/d.sql: e does not exist", errs[0].Error())
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaskedValue is written in place of secrets, such as the passwords of user mappings, so they are never compared,
+// printed or written to snapshots. A secret that changes therefore goes unnoticed.
+const MaskedValue = "********"
+
+// secretOptionName matches the names of options whose values are secrets.
+var secretOptionName = regexp.MustCompile(`(?i)pass|secret|key|token`)
+
+// conninfoPasswords match the passwords in a connection string, written as key/value pairs or as a URI, leaving the
+// text before each password in the first group and the text after it in the second.
+var conninfoPasswords = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(\b\w*password\s*=\s*)(?:'(?:[^'\\]|\\.)*'|[^\s&']+)()`),
+	regexp.MustCompile(`(?i)^(postgres(?:ql)?://[^:@/]*:)[^@/]*(@)`),
+}
+
+// MaskConninfo returns the connection string conninfo, such as that of a subscription, with its passwords replaced by
+// MaskedValue.
+func MaskConninfo(conninfo string) string {
+	for _, re := range conninfoPasswords {
+		conninfo = re.ReplaceAllString(conninfo, "${1}"+MaskedValue+"${2}")
+	}
+	return conninfo
+}
+
+// MaskOptions returns opts, "name=value" strings as PostgreSQL keeps options, with the values of secret options, such
+// as password, replaced by MaskedValue.
+func MaskOptions(opts []string) []string {
+	masked := make([]string, len(opts))
+	for i, opt := range opts {
+		masked[i] = opt
+		kv := strings.SplitN(opt, "=", 2)
+		if secretOptionName.MatchString(kv[0]) {
+			masked[i] = kv[0] + "=" + MaskedValue
+		}
+	}
+	return masked
+}
+
+// MaskSecrets replaces the secrets in rows of schemaType, as read from a database, with MaskedValue. Sources must mask
+// their rows before returning them.
+func MaskSecrets(schemaType string, rows []map[string]string) error {
+	if schemaType == SubscriptionSchemaType {
+		for _, row := range rows {
+			row["conninfo"] = MaskConninfo(row["conninfo"])
+		}
+		return nil
+	}
+	if schemaType != UserMappingSchemaType {
+		return nil
+	}
+	for _, row := range rows {
+		var opts []string
+		err := json.Unmarshal([]byte(row["options"]), &opts)
+		if err != nil {
+			return NewError(fmt.Sprintf("reading options %s: %s", row["options"], err))
+		}
+		row["options"] = optionsJSON(MaskOptions(opts))
+	}
+	return nil
+}
+
+// optionsJSON renders opts as array_to_json does.
+func optionsJSON(opts []string) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(opts)
+	return strings.TrimSuffix(b.String(), "\n")
+}
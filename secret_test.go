@@ -0,0 +1,40 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskOptions(t *testing.T) {
+	assert.Equal(t, []string{"user=bob", "password=********", "sslpassword=********", "api_key=********", "flag"},
+		MaskOptions([]string{"user=bob", "password=s3cret", "sslpassword=a=b", "api_key=k", "flag"}))
+}
+
+func TestMaskSecrets(t *testing.T) {
+	rows := []map[string]string{{"options": `["user=bob","password=s3cret"]`}, {"options": "[]"}}
+	assert.NoError(t, MaskSecrets(UserMappingSchemaType, rows))
+	assert.Equal(t, []map[string]string{{"options": `["user=bob","password=********"]`}, {"options": "[]"}}, rows)
+
+	rows = []map[string]string{{"options": `["password=s3cret"]`}}
+	assert.NoError(t, MaskSecrets(ServerSchemaType, rows))
+	assert.Equal(t, `["password=s3cret"]`, rows[0]["options"])
+
+	rows = []map[string]string{{"conninfo": "host=h password=s3cret"}}
+	assert.NoError(t, MaskSecrets(SubscriptionSchemaType, rows))
+	assert.Equal(t, "host=h password=********", rows[0]["conninfo"])
+
+	assert.Error(t, MaskSecrets(UserMappingSchemaType, []map[string]string{{"options": "["}}))
+}
+
+func TestMaskConninfo(t *testing.T) {
+	assert.Equal(t, "host=a password=******** user=b", MaskConninfo("host=a password=s3cret user=b"))
+	assert.Equal(t, "host=a password = ******** sslpassword=********", MaskConninfo(
+		`host=a password = 'it\'s secret' sslpassword=k`))
+	assert.Equal(t, "postgresql://u:********@h/db?password=********&sslmode=require",
+		MaskConninfo("postgresql://u:p@h/db?password=p&sslmode=require"))
+	assert.Equal(t, "host=a user=b", MaskConninfo("host=a user=b"))
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"fmt"
+
+	"github.com/joncrlsn/misc"
+)
+
+// ==================================
+// ServerRows definition
+// ==================================
+
+// ServerRows is a sortable string map
+type ServerRows []map[string]string
+
+func (slice ServerRows) Len() int {
+	return len(slice)
+}
+
+func (slice ServerRows) Less(i, j int) bool {
+	return slice[i]["compare_name"] < slice[j]["compare_name"]
+}
+
+func (slice ServerRows) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+// ==================================
+// ServerSchema definition
+// (implements Schema -- defined in pgdiff.go)
+// ==================================
+
+// ServerSchema holds a slice of rows from one of the databases as well as
+// a reference to the current row of data we're viewing.
+type ServerSchema struct {
+	rows     ServerRows
+	rowNum   int
+	done     bool
+	dbSchema string
+	other    *ServerSchema
+}
+
+func NewServerSchema(rows ServerRows, dbSchema string) *ServerSchema {
+	return &ServerSchema{rows: rows, rowNum: -1, dbSchema: dbSchema}
+}
+
+// get returns the value from the current row for the given key
+func (c *ServerSchema) get(key string) string {
+	if c.rowNum >= len(c.rows) {
+		return ""
+	}
+	return c.rows[c.rowNum][key]
+}
+
+// NextRow increments the rowNum and tells you whether or not there are more
+func (c *ServerSchema) NextRow() bool {
+	if c.rowNum >= len(c.rows)-1 {
+		c.done = true
+	}
+	c.rowNum = c.rowNum + 1
+	return !c.done
+}
+
+// Identity returns the name of the current row's foreign server, which is unique within the database
+func (c *ServerSchema) Identity() string {
+	return c.get("server_name")
+}
+
+// Row returns a copy of the current row
+func (c *ServerSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
+// Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
+func (c *ServerSchema) Compare(obj Schema) (int, *Error) {
+	c2, ok := obj.(*ServerSchema)
+	if !ok {
+		return +999, NewError(fmt.Sprint("compare(obj) needs a ServerSchema instance", c2))
+	}
+	c.other = c2
+
+	val := misc.CompareStrings(c.get("compare_name"), c.other.get("compare_name"))
+	return val, nil
+}
+
+// Add returns SQL to create the foreign server
+func (c *ServerSchema) Add() []Stringer {
+	opts, err := parseOptions(c.get("options"))
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	def := "CREATE SERVER " + quoteIdent(c.get("server_name"))
+	if c.get("server_type") != "null" {
+		def += " TYPE " + quoteLiteral(c.get("server_type"))
+	}
+	if c.get("server_version") != "null" {
+		def += " VERSION " + quoteLiteral(c.get("server_version"))
+	}
+	def += " FOREIGN DATA WRAPPER " + quoteIdent(c.get("fdw_name"))
+	return []Stringer{NewLine(def + opts.create() + ";")}
+}
+
+// Drop returns SQL to drop the foreign server
+func (c ServerSchema) Drop() []Stringer {
+	return []Stringer{NewLine(fmt.Sprintf("DROP SERVER %s;", quoteIdent(c.get("server_name"))))}
+}
+
+// Change handles the case where the server names match, but the version or options do not. The wrapper and type of a
+// server cannot be altered, so a warning is given instead.
+func (c *ServerSchema) Change() []Stringer {
+	opts1, err := parseOptions(c.get("options"))
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	opts2, err := parseOptions(c.other.get("options"))
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	var strs []Stringer
+	name := quoteIdent(c.get("server_name"))
+	if c.get("fdw_name") != c.other.get("fdw_name") || c.get("server_type") != c.other.get("server_type") {
+		strs = append(strs, NewWarning(fmt.Sprintf("-- WARNING: server %s uses wrapper %s and type %s in db1 but %s and %s "+
+			"in db2. PostgreSQL cannot change them, so the server must be dropped and created again.", name,
+			c.get("fdw_name"), c.get("server_type"), c.other.get("fdw_name"), c.other.get("server_type"))))
+	}
+	alter := ""
+	if c.get("server_version") != c.other.get("server_version") && c.get("server_version") != "null" {
+		alter += " VERSION " + quoteLiteral(c.get("server_version"))
+	}
+	alter += opts1.alter(opts2)
+	if alter != "" {
+		strs = append(strs, NewLine(fmt.Sprintf("ALTER SERVER %s%s;", name, alter)))
+	}
+	return strs
+}
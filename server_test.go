@@ -0,0 +1,57 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func serverRow(fdw, version, options string) map[string]string {
+	return map[string]string{"compare_name": "loopback", "server_name": "loopback", "fdw_name": fdw,
+		"server_type": "null", "server_version": version, "options": options}
+}
+
+func TestServer(t *testing.T) {
+	assert.Equal(t, []Stringer{
+		NewLine(`CREATE SERVER loopback VERSION '2' FOREIGN DATA WRAPPER postgres_fdw OPTIONS (host 'localhost', dbname 'it''s');`),
+	}, diffOutput(NewServerSchema(ServerRows{
+		serverRow("postgres_fdw", "2", `["host=localhost","dbname=it's"]`),
+	}, "*"), NewServerSchema(nil, "*")))
+	assert.Equal(t, []Stringer{NewLine("DROP SERVER loopback;")},
+		diffOutput(NewServerSchema(nil, "*"),
+			NewServerSchema(ServerRows{serverRow("postgres_fdw", "null", "[]")}, "*")))
+
+	assert.Empty(t, diffOutput(NewServerSchema(ServerRows{serverRow("postgres_fdw", "null", `["host=a"]`)}, "*"),
+		NewServerSchema(ServerRows{serverRow("postgres_fdw", "null", `["host=a"]`)}, "*")))
+	assert.Equal(t, []Stringer{
+		NewLine(`ALTER SERVER loopback VERSION '3' OPTIONS (DROP port, SET host 'b', ADD dbname 'db');`),
+	}, diffOutput(NewServerSchema(ServerRows{serverRow("postgres_fdw", "3", `["host=b","dbname=db"]`)}, "*"),
+		NewServerSchema(ServerRows{serverRow("postgres_fdw", "2", `["host=a","port=5432"]`)}, "*")))
+
+	assert.Equal(t, []Stringer{NewWarning("-- WARNING: server loopback uses wrapper postgres_fdw and type null in db1 but " +
+		"dummy and null in db2. PostgreSQL cannot change them, so the server must be dropped and created again.")},
+		diffOutput(NewServerSchema(ServerRows{serverRow("postgres_fdw", "null", "[]")}, "*"),
+			NewServerSchema(ServerRows{serverRow("dummy", "null", "[]")}, "*")))
+}
+
+func TestForeignDataWrapper(t *testing.T) {
+	row := func(handler, validator, options string) map[string]string {
+		return map[string]string{"compare_name": "dummy", "fdw_name": "dummy", "handler": handler, "validator": validator,
+			"options": options}
+	}
+	assert.Equal(t, []Stringer{NewLine(`CREATE FOREIGN DATA WRAPPER dummy HANDLER dummy_handler OPTIONS (debug 'true');`)},
+		diffOutput(NewForeignDataWrapperSchema(ForeignDataWrapperRows{
+			row("dummy_handler", "null", `["debug=true"]`),
+		}, "*"), NewForeignDataWrapperSchema(nil, "*")))
+	assert.Equal(t, []Stringer{NewLine("DROP FOREIGN DATA WRAPPER dummy;")},
+		diffOutput(NewForeignDataWrapperSchema(nil, "*"),
+			NewForeignDataWrapperSchema(ForeignDataWrapperRows{row("null", "null", "[]")}, "*")))
+	assert.Equal(t, []Stringer{NewLine(`ALTER FOREIGN DATA WRAPPER dummy NO HANDLER VALIDATOR dummy_validator OPTIONS (DROP debug);`)},
+		diffOutput(NewForeignDataWrapperSchema(ForeignDataWrapperRows{row("null", "dummy_validator", "[]")}, "*"),
+			NewForeignDataWrapperSchema(ForeignDataWrapperRows{
+				row("dummy_handler", "null", `["debug=true"]`),
+			}, "*")))
+}
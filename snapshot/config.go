@@ -0,0 +1,99 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package snapshot
+
+import (
+	"fmt"
+
+	"github.com/facefunk/pgdiff"
+	flag "github.com/ogier/pflag"
+)
+
+type (
+	// Module is a pgdiff.Module that produces SchemaFactory instances from snapshot files.
+	Module struct {
+		vals1 configVals
+		vals2 configVals
+		conf1 Config
+		conf2 Config
+	}
+	Config struct {
+		File     string
+		DbSchema string
+	}
+	configVals struct {
+		File string
+	}
+)
+
+func (m *Module) Name() string {
+	return "Snapshot source"
+}
+
+func (m *Module) RegisterFlags(flagSet *flag.FlagSet) {
+	flagSet.StringVar(&m.vals1.File, "snapshot1", "", "first snapshot file")
+	flagSet.StringVar(&m.vals2.File, "snapshot2", "", "second snapshot file")
+}
+
+func (m *Module) ConfigureFromFlags() {
+	setConf(&m.conf1, &m.vals1)
+	setConf(&m.conf2, &m.vals2)
+}
+
+func setConf(conf *Config, vals *configVals) {
+	conf.File = vals.File
+}
+
+func (m *Module) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	conf := struct {
+		Snapshot1 *configVals
+		Snapshot2 *configVals
+	}{
+		&configVals{},
+		&configVals{},
+	}
+	err := unmarshal(&conf)
+	if err != nil {
+		return err
+	}
+	setConf(&m.conf1, conf.Snapshot1)
+	setConf(&m.conf2, conf.Snapshot2)
+	return nil
+}
+
+func (m *Module) Config(i int) pgdiff.Config {
+	switch i {
+	case 1:
+		return &m.conf1
+	case 2:
+		return &m.conf2
+	default:
+		panic("there are only 2 possible configs.")
+	}
+}
+
+func (m *Module) Factory(conf pgdiff.Config) (pgdiff.SchemaFactory, error) {
+	c, ok := conf.(*Config)
+	if !ok {
+		return nil, pgdiff.NewError("Factory requires snapshot.Config instance")
+	}
+	snap, err := Open(c.File)
+	if err != nil {
+		return nil, err
+	}
+	// Row sets carry compare_name values built for the schema the snapshot was taken with.
+	if snap.DbSchema != c.DbSchema {
+		return nil, pgdiff.NewError(fmt.Sprintf("snapshot %s was taken with schema %s, not %s", c.File, snap.DbSchema,
+			c.DbSchema))
+	}
+	return pgdiff.NewRowSchemaFactory(snap, snap.DbSchema), nil
+}
+
+func (c *Config) SetSourceConfig(conf *pgdiff.SourceConfig) {
+	c.DbSchema = conf.Schema
+}
+
+func (c *Config) Valid() bool {
+	return c.File != "" && c.DbSchema != ""
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/facefunk/pgdiff"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	_ pgdiff.Module = (*Module)(nil)
+	_ pgdiff.Config = (*Config)(nil)
+)
+
+func TestYAML(t *testing.T) {
+	in := `
+dump1:
+  file: ignored.sql
+snapshot2:
+  file: prod.json
+`
+	out := Module{}
+	err := yaml.Unmarshal([]byte(in), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "", out.conf1.File)
+	assert.Equal(t, "prod.json", out.conf2.File)
+}
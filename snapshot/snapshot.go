@@ -0,0 +1,222 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/facefunk/pgdiff"
+)
+
+// FormatVersion is the version of the snapshot file format written by this package. Snapshots written by a later
+// version are refused rather than misread, those written by an earlier version are migrated as they are read.
+//
+// Version 2 records the partitioning and inheritance of tables and columns. Version 3 keys functions on their
+// argument types and records their kind and result.
+const FormatVersion = 3
+
+type (
+	// Snapshot is every row set a RowSource produced at a point in time. It is itself a pgdiff.RowSource, so a
+	// snapshot can be compared exactly as the source it was taken from.
+	Snapshot struct {
+		Version  int                            `json:"version"`
+		Taken    time.Time                      `json:"taken"`
+		DbSchema string                         `json:"dbSchema"`
+		Server   map[string]string              `json:"server,omitempty"`
+		RowSets  map[string][]map[string]string `json:"rows"`
+		name     string
+	}
+
+	// serverSource is implemented by RowSource instances that can describe the server their rows are read from.
+	serverSource interface {
+		ServerInfo() (map[string]string, error)
+	}
+)
+
+// Take reads the rows of every schema type that source supports. dbSchema is the schema source was configured with.
+func Take(source pgdiff.RowSource, dbSchema string) (*Snapshot, error) {
+	snap := &Snapshot{
+		Version:  FormatVersion,
+		Taken:    time.Now().UTC().Truncate(time.Second),
+		DbSchema: dbSchema,
+		RowSets:  map[string][]map[string]string{},
+	}
+	if s, ok := source.(serverSource); ok {
+		info, err := s.ServerInfo()
+		if err != nil {
+			return nil, err
+		}
+		snap.Server = info
+	}
+	partial, _ := source.(pgdiff.PartialSchemaFactory)
+	schemaTypes := append([]string{pgdiff.TableColumnSchemaType}, pgdiff.AllSchemaTypes...)
+	for _, schemaType := range schemaTypes {
+		if partial != nil && !partial.Supports(schemaType) {
+			continue
+		}
+		rows, err := source.Rows(schemaType)
+		if err != nil {
+			return nil, err
+		}
+		if rows == nil {
+			rows = []map[string]string{}
+		}
+		sortRows(rows)
+		snap.RowSets[schemaType] = rows
+	}
+	return snap, nil
+}
+
+// sortRows puts rows in a canonical order so that snapshots of an unchanged schema are identical.
+func sortRows(rows []map[string]string) {
+	keys := make([]string, len(rows))
+	for i, row := range rows {
+		b, _ := json.Marshal(row)
+		keys[i] = string(b)
+	}
+	sort.Sort(byKey{rows, keys})
+}
+
+type byKey struct {
+	rows []map[string]string
+	keys []string
+}
+
+func (s byKey) Len() int           { return len(s.rows) }
+func (s byKey) Less(i, j int) bool { return s.keys[i] < s.keys[j] }
+func (s byKey) Swap(i, j int) {
+	s.rows[i], s.rows[j] = s.rows[j], s.rows[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
+
+// Write encodes the snapshot as indented JSON.
+func (s *Snapshot) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	err := enc.Encode(s)
+	if err != nil {
+		return pgdiff.NewError("encoding snapshot: " + err.Error())
+	}
+	return nil
+}
+
+// WriteFile writes the snapshot to file, or to standard output if file is "-".
+func (s *Snapshot) WriteFile(file string) error {
+	if file == "-" {
+		return s.Write(os.Stdout)
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return pgdiff.NewError("creating snapshot: " + err.Error())
+	}
+	err = s.Write(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	err = f.Close()
+	if err != nil {
+		return pgdiff.NewError("closing snapshot: " + err.Error())
+	}
+	return nil
+}
+
+// Read decodes a snapshot, naming it name in notices.
+func Read(r io.Reader, name string) (*Snapshot, error) {
+	snap := &Snapshot{}
+	err := json.NewDecoder(r).Decode(snap)
+	if err != nil {
+		return nil, pgdiff.NewError(fmt.Sprintf("decoding snapshot %s: %s", name, err))
+	}
+	if snap.Version < 1 || snap.Version > FormatVersion {
+		return nil, pgdiff.NewError(fmt.Sprintf("snapshot %s has unsupported format version %d", name, snap.Version))
+	}
+	snap.migrate()
+	snap.name = name
+	return snap, nil
+}
+
+// migrate brings the rows of a snapshot written by an earlier format version up to FormatVersion.
+func (s *Snapshot) migrate() {
+	if s.Version < 2 {
+		for _, row := range s.RowSets[pgdiff.TableSchemaType] {
+			migrateTable(row)
+		}
+		for _, schemaType := range []string{pgdiff.ColumnSchemaType, pgdiff.TableColumnSchemaType} {
+			for _, row := range s.RowSets[schemaType] {
+				row["is_inherited"] = "NO"
+				row["is_partition_key"] = "NO"
+			}
+		}
+	}
+	if s.Version < 3 {
+		for _, row := range s.RowSets[pgdiff.FunctionSchemaType] {
+			migrateFunction(row)
+		}
+	}
+	s.Version = FormatVersion
+}
+
+// migrateTable fills in the columns a version 1 table row lacks. Version 1 did not record partitioning or inheritance,
+// so the table reads as neither partitioned nor inherited.
+func migrateTable(row map[string]string) {
+	for _, key := range []string{"inherits", "parent_schema", "parent_table", "partition_bound", "partition_columns",
+		"partition_key"} {
+		row[key] = "null"
+	}
+}
+
+// migrateFunction derives the columns a version 1 or 2 function row lacks from fancy, its regprocedure, and its
+// definition. Versions 1 and 2 only held functions and procedures, whose results are left unknown.
+func migrateFunction(row map[string]string) {
+	fancy := row["fancy"]
+	var argTypes string
+	if i := strings.LastIndex(fancy, "("); i >= 0 && strings.HasSuffix(fancy, ")") {
+		argTypes = strings.Join(strings.Split(fancy[i+1:len(fancy)-1], ","), ", ")
+	}
+	row["compare_name"] += "(" + argTypes + ")"
+	row["arg_types"] = argTypes
+	row["kind"] = "FUNCTION"
+	if strings.HasPrefix(row["definition"], "CREATE OR REPLACE PROCEDURE") {
+		row["kind"] = "PROCEDURE"
+	}
+	row["result"] = "null"
+	row["identity_args"] = "null"
+}
+
+// Open reads the snapshot in file.
+func Open(file string) (*Snapshot, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, pgdiff.NewError("opening snapshot: " + err.Error())
+	}
+	defer f.Close()
+	return Read(f, file)
+}
+
+// Supports reports whether the snapshot holds rows for schemaType.
+func (s *Snapshot) Supports(schemaType string) bool {
+	_, ok := s.RowSets[schemaType]
+	return ok
+}
+
+func (s *Snapshot) Rows(schemaType string) ([]map[string]string, error) {
+	rows, ok := s.RowSets[schemaType]
+	if !ok {
+		return nil, pgdiff.NewError(fmt.Sprintf("snapshot %s holds no %s rows", s.name, schemaType))
+	}
+	// Copy, as schemas are free to sort their rows.
+	return append([]map[string]string(nil), rows...), nil
+}
+
+func (s *Snapshot) Identify(num int) *pgdiff.Notice {
+	return pgdiff.NewNotice(fmt.Sprintf("-- snapshot%d: %s (taken %s, database %s, server %s)", num, s.name,
+		s.Taken.Format(time.RFC3339), s.Server["database"], s.Server["version"]))
+}
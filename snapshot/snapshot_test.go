@@ -0,0 +1,144 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package snapshot
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/facefunk/pgdiff"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	_ pgdiff.RowSource            = (*Snapshot)(nil)
+	_ pgdiff.PartialSchemaFactory = (*Snapshot)(nil)
+)
+
+type testSource struct{}
+
+func (s *testSource) Rows(schemaType string) ([]map[string]string, error) {
+	if schemaType != pgdiff.TableSchemaType {
+		return nil, nil
+	}
+	return []map[string]string{
+		{"compare_name": "t2", "table_name": "t2"},
+		{"compare_name": "t1", "table_name": "t1"},
+	}, nil
+}
+
+func (s *testSource) Supports(schemaType string) bool {
+	return schemaType != pgdiff.RoleSchemaType
+}
+
+func (s *testSource) Identify(num int) *pgdiff.Notice {
+	return pgdiff.NewNotice("-- test")
+}
+
+func (s *testSource) ServerInfo() (map[string]string, error) {
+	return map[string]string{"database": "prod", "version": "14.5"}, nil
+}
+
+func TestRoundTrip(t *testing.T) {
+	snap, err := Take(&testSource{}, "public")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	err = snap.Write(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err := Read(buf, "test.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, FormatVersion, read.Version)
+	assert.Equal(t, "public", read.DbSchema)
+	assert.Equal(t, "prod", read.Server["database"])
+	assert.False(t, read.Supports(pgdiff.RoleSchemaType))
+	assert.True(t, read.Supports(pgdiff.TableColumnSchemaType))
+
+	rows, err := read.Rows(pgdiff.TableSchemaType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []map[string]string{
+		{"compare_name": "t1", "table_name": "t1"},
+		{"compare_name": "t2", "table_name": "t2"},
+	}, rows)
+	rows, err = read.Rows(pgdiff.ViewSchemaType)
+	assert.NoError(t, err)
+	assert.Empty(t, rows)
+	_, err = read.Rows(pgdiff.RoleSchemaType)
+	assert.Error(t, err)
+}
+
+func TestReadVersion(t *testing.T) {
+	_, err := Read(strings.NewReader(`{"version": 4, "rows": {}}`), "future.json")
+	assert.EqualError(t, err, "snapshot future.json has unsupported format version 4")
+}
+
+func TestReadMigratesVersion1(t *testing.T) {
+	read, err := Read(strings.NewReader(`{"version": 1, "dbSchema": "*", "rows": {"FUNCTION": [
+		{"compare_name": "s1.add", "schema_name": "s1", "function_name": "add", "fancy": "s1.add(integer,integer)",
+			"return_type": "int4", "definition": "CREATE OR REPLACE FUNCTION s1.add(a integer, b integer)"},
+		{"compare_name": "public.run", "schema_name": "public", "function_name": "run", "fancy": "run()",
+			"return_type": "void", "definition": "CREATE OR REPLACE PROCEDURE public.run()"}
+	], "TABLE": [
+		{"compare_name": "s1.t", "table_schema": "s1", "table_name": "t", "table_type": "BASE TABLE"}
+	], "COLUMN": [
+		{"compare_name": "s1.t.00001id", "table_schema": "s1", "table_name": "t", "column_name": "id"}
+	]}}`), "old.json")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, FormatVersion, read.Version)
+	rows, err := read.Rows(pgdiff.FunctionSchemaType)
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]string{{
+		"compare_name": "s1.add(integer, integer)", "schema_name": "s1", "function_name": "add",
+		"fancy": "s1.add(integer,integer)", "arg_types": "integer, integer", "return_type": "int4", "result": "null",
+		"kind": "FUNCTION", "identity_args": "null", "definition": "CREATE OR REPLACE FUNCTION s1.add(a integer, b integer)",
+	}, {
+		"compare_name": "public.run()", "schema_name": "public", "function_name": "run", "fancy": "run()",
+		"arg_types": "", "return_type": "void", "result": "null", "kind": "PROCEDURE", "identity_args": "null",
+		"definition": "CREATE OR REPLACE PROCEDURE public.run()",
+	}}, rows)
+	rows, err = read.Rows(pgdiff.TableSchemaType)
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]string{{
+		"compare_name": "s1.t", "table_schema": "s1", "table_name": "t", "table_type": "BASE TABLE", "inherits": "null",
+		"parent_schema": "null", "parent_table": "null", "partition_bound": "null", "partition_columns": "null",
+		"partition_key": "null",
+	}}, rows)
+	rows, err = read.Rows(pgdiff.ColumnSchemaType)
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]string{{
+		"compare_name": "s1.t.00001id", "table_schema": "s1", "table_name": "t", "column_name": "id",
+		"is_inherited": "NO", "is_partition_key": "NO",
+	}}, rows)
+}
+
+func TestReadMigratesVersion2(t *testing.T) {
+	read, err := Read(strings.NewReader(`{"version": 2, "dbSchema": "*", "rows": {"TABLE": [
+		{"compare_name": "s1.t", "table_schema": "s1", "table_name": "t", "table_type": "BASE TABLE", "inherits": "s1.p",
+			"parent_schema": "null", "parent_table": "null", "partition_bound": "null", "partition_columns": "null",
+			"partition_key": "null"}
+	], "FUNCTION": [
+		{"compare_name": "public.run", "schema_name": "public", "function_name": "run", "fancy": "run()",
+			"return_type": "void", "definition": "CREATE OR REPLACE PROCEDURE public.run()"}
+	]}}`), "old.json")
+	if !assert.NoError(t, err) {
+		return
+	}
+	rows, err := read.Rows(pgdiff.TableSchemaType)
+	assert.NoError(t, err)
+	assert.Equal(t, "s1.p", rows[0]["inherits"])
+	rows, err = read.Rows(pgdiff.FunctionSchemaType)
+	assert.NoError(t, err)
+	assert.Equal(t, "public.run()", rows[0]["compare_name"])
+	assert.Equal(t, "PROCEDURE", rows[0]["kind"])
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	statementBegin = "STATEMENT-BEGIN"
+	statementEnd   = "STATEMENT-END"
+)
+
+// SplitSQL splits SQL batches up into separate queries because Postgres clients must send queries to the server one at
+// a time. psql does this splitting but Go client libs don't. Everything between a "-- STATEMENT-BEGIN" line and a
+// "-- STATEMENT-END" line, as printed around function definitions, is a single query regardless of its content.
+// Elsewhere semicolons inside quoted strings, quoted identifiers, dollar quoted strings and comments do not end a
+// query. An error is returned for a quote, comment or STATEMENT-BEGIN block that is never closed and for text following
+// the last semicolon, rather than dropping it.
+func SplitSQL(query string) ([]string, error) {
+	var out []string
+	// li is where the current query starts and ti where its first text outside comments is, or -1 if it has none.
+	li, ti := 0, -1
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		if ti < 0 && c != ' ' && c != '\t' && c != '\n' && c != '\r' && c != ';' &&
+			!strings.HasPrefix(query[i:], "--") && !strings.HasPrefix(query[i:], "/*") {
+			ti = i
+		}
+		switch {
+		case c == ';':
+			out = append(out, query[li:i+1])
+			li, ti = i+1, -1
+		case c == '\'' || c == '"':
+			j := quoteEnd(query, i)
+			if j < 0 {
+				return nil, splitError("unterminated quoted string", query, i)
+			}
+			i = j
+		case c == '$':
+			tag := dollarTag(query, i)
+			if tag == "" {
+				continue
+			}
+			j := strings.Index(query[i+len(tag):], tag)
+			if j < 0 {
+				return nil, splitError("unterminated dollar quoted string", query, i)
+			}
+			i += len(tag) + j + len(tag) - 1
+		case strings.HasPrefix(query[i:], "--"):
+			j := strings.IndexByte(query[i:], '\n')
+			if j < 0 {
+				j = len(query) - i
+			}
+			if strings.TrimSpace(query[i+2:i+j]) != statementBegin {
+				i += j - 1
+				continue
+			}
+			start, end := statementEndLine(query, i+j)
+			if start < 0 {
+				return nil, splitError(statementBegin+" without "+statementEnd, query, i)
+			}
+			if strings.TrimSpace(query[li:start]) != "" {
+				out = append(out, query[li:start])
+			}
+			li, ti = end, -1
+			i = end - 1
+		case strings.HasPrefix(query[i:], "/*"):
+			j := strings.Index(query[i+2:], "*/")
+			if j < 0 {
+				return nil, splitError("unterminated comment", query, i)
+			}
+			i += j + 3
+		}
+	}
+	if ti >= 0 {
+		return nil, splitError("statement without a terminating semicolon", query, ti)
+	}
+	return out, nil
+}
+
+// quoteEnd returns the offset of the quote that closes the string or identifier opened at query[i], or -1 if there is
+// none. Backslashes escape quotes in E'...' strings.
+func quoteEnd(query string, i int) int {
+	escapes := query[i] == '\'' && i > 0 && (query[i-1] == 'E' || query[i-1] == 'e') &&
+		(i == 1 || !isIdentByte(query[i-2]))
+	for j := i + 1; j < len(query); j++ {
+		switch query[j] {
+		case '\\':
+			if escapes {
+				j++
+			}
+		case query[i]:
+			return j
+		}
+	}
+	return -1
+}
+
+// dollarTag returns the $tag$ that opens a dollar quoted string at query[i], or "" if there is none, eg. for the
+// parameter $1 or a $ within an identifier.
+func dollarTag(query string, i int) string {
+	if i > 0 && isIdentByte(query[i-1]) {
+		return ""
+	}
+	j := i + 1
+	for j < len(query) && isIdentByte(query[j]) {
+		if j == i+1 && query[j] >= '0' && query[j] <= '9' {
+			return ""
+		}
+		j++
+	}
+	if j >= len(query) || query[j] != '$' {
+		return ""
+	}
+	return query[i : j+1]
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c >= 0x80
+}
+
+// statementEndLine finds the first "-- STATEMENT-END" line at or after i, returning the offsets of its start and of
+// the end of the line, or -1, -1 if there is none.
+func statementEndLine(query string, i int) (int, int) {
+	for i < len(query) {
+		end := strings.IndexByte(query[i:], '\n')
+		if end < 0 {
+			end = len(query)
+		} else {
+			end += i
+		}
+		line := strings.TrimSpace(query[i:end])
+		if strings.HasPrefix(line, "--") && strings.TrimSpace(line[2:]) == statementEnd {
+			return i + strings.Index(query[i:end], "--"), end
+		}
+		i = end + 1
+	}
+	return -1, -1
+}
+
+// splitError reports a problem splitting query, quoting the start of the line it begins on.
+func splitError(problem, query string, i int) *Error {
+	line := strings.Count(query[:i], "\n") + 1
+	text := strings.TrimSpace(query[i:])
+	if j := strings.IndexByte(text, '\n'); j >= 0 {
+		text = text[:j]
+	}
+	return NewError(fmt.Sprintf("splitting SQL: %s at line %d: %s", problem, line, text))
+}
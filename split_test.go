@@ -0,0 +1,71 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitSQL(t *testing.T) {
+	query := `-- Add
+CREATE TABLE t (s text DEFAULT 'a;b');
+-- STATEMENT-BEGIN
+CREATE OR REPLACE FUNCTION public.f()
+ RETURNS integer
+ LANGUAGE plpgsql
+AS $function$
+BEGIN
+    RETURN 1;
+END;
+$function$;
+-- STATEMENT-END
+DROP FUNCTION public.g CASCADE; -- Drop
+`
+	assert.Equal(t, []string{
+		"-- Add\nCREATE TABLE t (s text DEFAULT 'a;b');",
+		"\n-- STATEMENT-BEGIN\nCREATE OR REPLACE FUNCTION public.f()\n RETURNS integer\n LANGUAGE plpgsql\nAS $function$\nBEGIN\n    RETURN 1;\nEND;\n$function$;\n",
+		"\nDROP FUNCTION public.g CASCADE;",
+	}, split(t, query))
+
+	assert.Equal(t, []string{"-- STATEMENT-BEGIN\nSELECT 1;\n"}, split(t, "-- STATEMENT-BEGIN\nSELECT 1;\n-- STATEMENT-END"))
+
+	// An odd apostrophe in a function body is opaque between the markers.
+	assert.Equal(t, []string{
+		"-- STATEMENT-BEGIN\nCREATE FUNCTION f() RETURNS void LANGUAGE plpython3u AS $$\n# don't\n$$;\n",
+		"\nSELECT 1;",
+	}, split(t, "-- STATEMENT-BEGIN\nCREATE FUNCTION f() RETURNS void LANGUAGE plpython3u AS $$\n# don't\n$$;\n-- STATEMENT-END\nSELECT 1;"))
+}
+
+func TestSplitSQLQuotes(t *testing.T) {
+	assert.Equal(t, []string{
+		"CREATE FUNCTION f() RETURNS text LANGUAGE sql AS $body$ SELECT 'a;b' -- don't\n$body$;",
+		" SELECT $1, $$;$$, a$b;",
+		` SELECT E'it\'s;', 'it''s;', "a;b";`,
+		" /* ; */ SELECT 1;",
+	}, split(t, "CREATE FUNCTION f() RETURNS text LANGUAGE sql AS $body$ SELECT 'a;b' -- don't\n$body$;"+
+		" SELECT $1, $$;$$, a$b;"+` SELECT E'it\'s;', 'it''s;', "a;b";`+" /* ; */ SELECT 1;"))
+}
+
+func TestSplitSQLErrors(t *testing.T) {
+	for query, msg := range map[string]string{
+		"SELECT 1;\nSELECT 'a;":                  "unterminated quoted string at line 2: 'a;",
+		"SELECT $tag$ a; $$;":                    "unterminated dollar quoted string at line 1: $tag$ a; $$;",
+		"SELECT 1; /* ;":                         "unterminated comment at line 1: /* ;",
+		"-- STATEMENT-BEGIN\nSELECT 1;":          "STATEMENT-BEGIN without STATEMENT-END at line 1: -- STATEMENT-BEGIN",
+		"SELECT 1; -- one\nSELECT 2 -- no end\n": "statement without a terminating semicolon at line 2: SELECT 2 -- no end",
+	} {
+		_, err := SplitSQL(query)
+		assert.EqualError(t, err, "splitting SQL: "+msg, query)
+	}
+}
+
+func split(t *testing.T, query string) []string {
+	stmts, err := SplitSQL(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stmts
+}
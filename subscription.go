@@ -0,0 +1,199 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/joncrlsn/misc"
+)
+
+// subscriptionParams are the parameters of a subscription that ALTER SUBSCRIPTION ... SET can change, in the order they
+// are written.
+var subscriptionParams = []string{"slot_name", "binary", "streaming", "synchronous_commit"}
+
+// ==================================
+// SubscriptionRows definition
+// ==================================
+
+// SubscriptionRows is a sortable string map
+type SubscriptionRows []map[string]string
+
+func (slice SubscriptionRows) Len() int {
+	return len(slice)
+}
+
+func (slice SubscriptionRows) Less(i, j int) bool {
+	return slice[i]["compare_name"] < slice[j]["compare_name"]
+}
+
+func (slice SubscriptionRows) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+// ==================================
+// SubscriptionSchema definition
+// (implements Schema -- defined in pgdiff.go)
+// ==================================
+
+// SubscriptionSchema holds a slice of rows from one of the databases as well as
+// a reference to the current row of data we're viewing.
+//
+// The passwords in connection strings are MaskedValue in the rows, see MaskConninfo, so they are never compared, and
+// SQL giving a masked connection string is commented out, as it must be completed by hand. A connection string is null if the user could not
+// read it, in which case it is not compared either.
+type SubscriptionSchema struct {
+	rows     SubscriptionRows
+	rowNum   int
+	done     bool
+	dbSchema string
+	other    *SubscriptionSchema
+}
+
+func NewSubscriptionSchema(rows SubscriptionRows, dbSchema string) *SubscriptionSchema {
+	return &SubscriptionSchema{rows: rows, rowNum: -1, dbSchema: dbSchema}
+}
+
+// get returns the value from the current row for the given key
+func (c *SubscriptionSchema) get(key string) string {
+	if c.rowNum >= len(c.rows) {
+		return ""
+	}
+	return c.rows[c.rowNum][key]
+}
+
+// NextRow increments the rowNum and tells you whether or not there are more
+func (c *SubscriptionSchema) NextRow() bool {
+	if c.rowNum >= len(c.rows)-1 {
+		c.done = true
+	}
+	c.rowNum = c.rowNum + 1
+	return !c.done
+}
+
+// Identity returns the name of the current row's subscription, which is unique within the database
+func (c *SubscriptionSchema) Identity() string {
+	return c.get("sub_name")
+}
+
+// Row returns a copy of the current row
+func (c *SubscriptionSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
+// Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
+func (c *SubscriptionSchema) Compare(obj Schema) (int, *Error) {
+	c2, ok := obj.(*SubscriptionSchema)
+	if !ok {
+		return +999, NewError(fmt.Sprint("compare(obj) needs a SubscriptionSchema instance", c2))
+	}
+	c.other = c2
+
+	val := misc.CompareStrings(c.get("compare_name"), c.other.get("compare_name"))
+	return val, nil
+}
+
+// param returns the value of the subscription parameter as WITH and SET expect it
+func (c *SubscriptionSchema) param(name string) string {
+	value := c.get(name)
+	switch {
+	case name == "slot_name" && value == "null":
+		return "NONE"
+	case name == "slot_name" || name == "synchronous_commit":
+		return quoteLiteral(value)
+	}
+	return value
+}
+
+// unrunnable returns a warning if SQL giving the subscription's connection string cannot be run as it is, because the
+// string could not be read or its password is masked, or nil if it can.
+func (c *SubscriptionSchema) unrunnable() Stringer {
+	name := quoteIdent(c.get("sub_name"))
+	switch conninfo := c.get("conninfo"); {
+	case conninfo == "null":
+		return NewWarning(fmt.Sprintf("-- WARNING: the connection string of subscription %s could not be read, which "+
+			"needs superuser, so it must be added to the SQL below, which must then be run by hand.", name))
+	case strings.Contains(conninfo, MaskedValue):
+		return NewWarning(fmt.Sprintf("-- WARNING: the password in the connection string of subscription %s is masked "+
+			"as %s, so it must be replaced in the SQL below, which must then be run by hand.", name, MaskedValue))
+	}
+	return nil
+}
+
+// lines returns stmts as Lines, or, if they cannot be run as they are, commented out after a warning, so that apply
+// does not run them
+func (c *SubscriptionSchema) lines(stmts ...string) []Stringer {
+	var strs []Stringer
+	warning := c.unrunnable()
+	if warning == nil {
+		for _, stmt := range stmts {
+			strs = append(strs, NewLine(stmt))
+		}
+		return strs
+	}
+	strs = append(strs, warning)
+	for _, stmt := range stmts {
+		strs = append(strs, NewWarning("-- "+stmt))
+	}
+	return strs
+}
+
+// Add returns SQL to create the subscription. It is created without connecting, so its replication slot must already
+// exist on the publisher, as when the subscription is being moved, and it is only enabled by ENABLE.
+func (c *SubscriptionSchema) Add() []Stringer {
+	name := quoteIdent(c.get("sub_name"))
+	params := []string{"connect = false"}
+	for _, param := range subscriptionParams {
+		params = append(params, param+" = "+c.param(param))
+	}
+	conninfo := c.get("conninfo")
+	if conninfo == "null" {
+		conninfo = ""
+	}
+	stmts := []string{fmt.Sprintf("CREATE SUBSCRIPTION %s CONNECTION %s PUBLICATION %s WITH (%s);", name,
+		quoteLiteral(conninfo), c.get("publications"), strings.Join(params, ", "))}
+	if c.get("enabled") == "YES" && c.get("slot_name") != "null" {
+		stmts = append(stmts, fmt.Sprintf("ALTER SUBSCRIPTION %s ENABLE;", name))
+	}
+	return c.lines(stmts...)
+}
+
+// Drop returns SQL to drop the subscription
+func (c SubscriptionSchema) Drop() []Stringer {
+	return []Stringer{NewLine(fmt.Sprintf("DROP SUBSCRIPTION %s;", quoteIdent(c.get("sub_name"))))}
+}
+
+// Change handles the case where the subscription names match, but the connection, publications or parameters do not
+func (c *SubscriptionSchema) Change() []Stringer {
+	var strs []Stringer
+	name := quoteIdent(c.get("sub_name"))
+	conninfo1, conninfo2 := c.get("conninfo"), c.other.get("conninfo")
+	if conninfo1 != conninfo2 && conninfo1 != "null" && conninfo2 != "null" {
+		strs = append(strs, c.lines(fmt.Sprintf("ALTER SUBSCRIPTION %s CONNECTION %s;", name, quoteLiteral(conninfo1)))...)
+	}
+	if c.get("publications") != c.other.get("publications") {
+		strs = append(strs, NewLine(fmt.Sprintf("ALTER SUBSCRIPTION %s SET PUBLICATION %s;", name, c.get("publications"))))
+	}
+	var params []string
+	for _, param := range subscriptionParams {
+		if c.get(param) != c.other.get(param) {
+			params = append(params, param+" = "+c.param(param))
+		}
+	}
+	if len(params) > 0 {
+		strs = append(strs, NewLine(fmt.Sprintf("ALTER SUBSCRIPTION %s SET (%s);", name, strings.Join(params, ", "))))
+	}
+	if c.get("enabled") != c.other.get("enabled") {
+		action := "DISABLE"
+		if c.get("enabled") == "YES" {
+			action = "ENABLE"
+		}
+		strs = append(strs, NewLine(fmt.Sprintf("ALTER SUBSCRIPTION %s %s;", name, action)))
+	}
+	return strs
+}
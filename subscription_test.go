@@ -0,0 +1,69 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func subscriptionRow(enabled, conninfo, publications, binary string) map[string]string {
+	return map[string]string{"compare_name": "sub", "sub_name": "sub", "enabled": enabled, "conninfo": conninfo,
+		"publications": publications, "slot_name": "sub", "binary": binary, "streaming": "off",
+		"synchronous_commit": "off"}
+}
+
+func TestSubscription(t *testing.T) {
+	c1, c2 := "host=h1 password=******** dbname=d", "host=h2 password=******** dbname=d"
+	masked := NewWarning("-- WARNING: the password in the connection string of subscription sub is masked as ********, " +
+		"so it must be replaced in the SQL below, which must then be run by hand.")
+	assert.Equal(t, []Stringer{
+		masked,
+		NewWarning("-- CREATE SUBSCRIPTION sub CONNECTION 'host=h1 password=******** dbname=d' PUBLICATION p1, p2 WITH " +
+			"(connect = false, slot_name = 'sub', binary = false, streaming = off, synchronous_commit = 'off');"),
+		NewWarning("-- ALTER SUBSCRIPTION sub ENABLE;"),
+	}, diffOutput(NewSubscriptionSchema(SubscriptionRows{subscriptionRow("YES", c1, "p1, p2", "false")}, "*"),
+		NewSubscriptionSchema(nil, "*")))
+	assert.Equal(t, []Stringer{
+		NewLine("CREATE SUBSCRIPTION sub CONNECTION 'host=h1 dbname=d' PUBLICATION p1 WITH (connect = false, " +
+			"slot_name = 'sub', binary = false, streaming = off, synchronous_commit = 'off');"),
+	}, diffOutput(NewSubscriptionSchema(SubscriptionRows{
+		subscriptionRow("NO", "host=h1 dbname=d", "p1", "false"),
+	}, "*"), NewSubscriptionSchema(nil, "*")))
+	assert.Equal(t, []Stringer{NewLine("DROP SUBSCRIPTION sub;")},
+		diffOutput(NewSubscriptionSchema(nil, "*"),
+			NewSubscriptionSchema(SubscriptionRows{subscriptionRow("YES", c1, "p1", "false")}, "*")))
+
+	assert.Empty(t, diffOutput(NewSubscriptionSchema(SubscriptionRows{subscriptionRow("YES", c1, "p1", "false")}, "*"),
+		NewSubscriptionSchema(SubscriptionRows{subscriptionRow("YES", c1, "p1", "false")}, "*")))
+	assert.Equal(t, []Stringer{
+		masked,
+		NewWarning("-- ALTER SUBSCRIPTION sub CONNECTION 'host=h1 password=******** dbname=d';"),
+		NewLine("ALTER SUBSCRIPTION sub SET PUBLICATION p1, p2;"),
+		NewLine("ALTER SUBSCRIPTION sub SET (binary = true);"),
+		NewLine("ALTER SUBSCRIPTION sub DISABLE;"),
+	}, diffOutput(NewSubscriptionSchema(SubscriptionRows{subscriptionRow("NO", c1, "p1, p2", "true")}, "*"),
+		NewSubscriptionSchema(SubscriptionRows{subscriptionRow("YES", c2, "p1", "false")}, "*")))
+
+	assert.Equal(t, []Stringer{NewLine("ALTER SUBSCRIPTION sub CONNECTION 'host=h1 dbname=d';")},
+		diffOutput(NewSubscriptionSchema(SubscriptionRows{
+			subscriptionRow("YES", "host=h1 dbname=d", "p1", "false"),
+		}, "*"), NewSubscriptionSchema(SubscriptionRows{
+			subscriptionRow("YES", "host=h2 dbname=d", "p1", "false"),
+		}, "*")))
+
+	// A connection string that could not be read is not compared, and one is needed to create the subscription.
+	unread := SubscriptionRows{subscriptionRow("YES", "null", "p1", "false")}
+	assert.Empty(t, diffOutput(NewSubscriptionSchema(unread, "*"),
+		NewSubscriptionSchema(SubscriptionRows{subscriptionRow("YES", c1, "p1", "false")}, "*")))
+	assert.Equal(t, []Stringer{
+		NewWarning("-- WARNING: the connection string of subscription sub could not be read, which needs superuser, " +
+			"so it must be added to the SQL below, which must then be run by hand."),
+		NewWarning("-- CREATE SUBSCRIPTION sub CONNECTION '' PUBLICATION p1 WITH (connect = false, slot_name = 'sub', " +
+			"binary = false, streaming = off, synchronous_commit = 'off');"),
+		NewWarning("-- ALTER SUBSCRIPTION sub ENABLE;"),
+	}, diffOutput(NewSubscriptionSchema(SubscriptionRows{subscriptionRow("YES", "null", "p1", "false")}, "*"),
+		NewSubscriptionSchema(nil, "*")))
+}
@@ -10,6 +10,7 @@ package pgdiff
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/joncrlsn/misc"
 )
@@ -66,6 +67,19 @@ func (c *TableSchema) NextRow() bool {
 	return !c.done
 }
 
+// Identity returns the qualified name of the current row's object
+func (c *TableSchema) Identity() string {
+	return c.get("table_schema") + "." + c.get("table_name")
+}
+
+// Row returns a copy of the current row
+func (c *TableSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
 // Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
 func (c *TableSchema) Compare(obj Schema) (int, *Error) {
 	c2, ok := obj.(*TableSchema)
@@ -79,13 +93,63 @@ func (c *TableSchema) Compare(obj Schema) (int, *Error) {
 	return val, nil
 }
 
-// Add returns SQL to add the table or view
+// optional returns the value from the current row for a key that rows read before the key was added do not have,
+// treating a missing value as null
+func (c *TableSchema) optional(key string) string {
+	v := c.get(key)
+	if v == "" {
+		return "null"
+	}
+	return v
+}
+
+// Add returns SQL to add the table or view. A partitioned table is created along with its partition key columns, the
+// rest are added as columns. A partition is created with the columns of its parent.
 func (c TableSchema) Add() []Stringer {
 	schema := c.other.dbSchema
 	if schema == "*" {
 		schema = c.get("table_schema")
 	}
-	return []Stringer{NewLine(fmt.Sprintf("CREATE %s %s.%s();", c.get("table_type"), schema, c.get("table_name")))}
+	def := fmt.Sprintf("CREATE %s %s.%s", c.get("table_type"), schema, c.get("table_name"))
+	switch {
+	case c.optional("parent_table") != "null":
+		def += fmt.Sprintf(" PARTITION OF %s %s", c.parent(), c.get("partition_bound"))
+	case c.optional("partition_key") != "null":
+		def += fmt.Sprintf(" (%s)", c.get("partition_columns"))
+	default:
+		def += "()"
+	}
+	if c.optional("inherits") != "null" {
+		def += fmt.Sprintf(" INHERITS (%s)", strings.Join(c.parents(schema), ", "))
+	}
+	if c.optional("partition_key") != "null" {
+		def += " PARTITION BY " + c.get("partition_key")
+	}
+	return []Stringer{NewLine(def + ";")}
+}
+
+// parents returns the qualified names of the tables the current row's table inherits from. A parent in the table's
+// own schema is put in the given schema.
+func (c *TableSchema) parents(schema string) []string {
+	if c.optional("inherits") == "null" {
+		return nil
+	}
+	names := strings.Split(c.get("inherits"), ", ")
+	for i, name := range names {
+		if !strings.Contains(name, ".") {
+			names[i] = schema + "." + name
+		}
+	}
+	return names
+}
+
+// parent returns the qualified name in db2 of the table the current row's table is a partition of
+func (c *TableSchema) parent() string {
+	schema := c.other.dbSchema
+	if schema == "*" {
+		schema = c.get("parent_schema")
+	}
+	return schema + "." + c.get("parent_table")
 }
 
 // Drop returns SQL to drop the table or view
@@ -93,8 +157,80 @@ func (c TableSchema) Drop() []Stringer {
 	return []Stringer{NewLine(fmt.Sprintf("DROP %s %s.%s;", c.get("table_type"), c.get("table_schema"), c.get("table_name")))}
 }
 
-// Change handles the case where the table and column match, but the details do not
+// Change handles the case where the table names match, but the partitioning or inheritance does not. A table is
+// detached from and attached to its parent to change its bounds or parent. How a table is partitioned cannot be
+// altered, so a warning is given instead.
 func (c TableSchema) Change() []Stringer {
-	// There's nothing we need to do here
-	return nil
+	var strs []Stringer
+	schema := c.other.dbSchema
+	if schema == "*" {
+		schema = c.get("table_schema")
+	}
+	table := schema + "." + c.get("table_name")
+	parents1 := c.parents(schema)
+	parents2 := c.other.parents(c.other.get("table_schema"))
+	for _, parent := range parents2 {
+		if !contains(parents1, parent) {
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER TABLE %s NO INHERIT %s;", table, parent)))
+		}
+	}
+	for _, parent := range parents1 {
+		if !contains(parents2, parent) {
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER TABLE %s INHERIT %s;", table, parent)))
+		}
+	}
+	if c.optional("partition_key") != c.other.optional("partition_key") {
+		strs = append(strs, NewWarning(fmt.Sprintf("-- WARNING: table %s is partitioned by %s in db1 but %s in db2. "+
+			"PostgreSQL cannot change how a table is partitioned, so it must be created again.", table,
+			c.optional("partition_key"), c.other.optional("partition_key"))))
+	}
+	if c.optional("parent_schema") == c.other.optional("parent_schema") &&
+		c.optional("parent_table") == c.other.optional("parent_table") &&
+		c.optional("partition_bound") == c.other.optional("partition_bound") {
+		return strs
+	}
+	if c.other.optional("parent_table") != "null" {
+		strs = append(strs, NewLine(fmt.Sprintf("ALTER TABLE %s.%s DETACH PARTITION %s;", c.other.get("parent_schema"),
+			c.other.get("parent_table"), table)))
+	}
+	if c.optional("parent_table") != "null" {
+		strs = append(strs, NewLine(fmt.Sprintf("ALTER TABLE %s ATTACH PARTITION %s %s;", c.parent(), table,
+			c.get("partition_bound"))))
+	}
+	return strs
+}
+
+// tableDependencies returns the dependencies of the tables of changes on the tables they inherit from or are partitions
+// of, read from the rows after the changes if after is set, or before them otherwise. A parent in the table's own schema
+// is named in the schema of the change's Identity, which differs from the row's when one schema is compared with
+// another.
+func tableDependencies(changes []*Change, after bool) []Dependency {
+	var deps []Dependency
+	for _, change := range changes {
+		row := change.Before
+		if after {
+			row = change.After
+		}
+		if row == nil {
+			continue
+		}
+		schema := strings.TrimSuffix(change.Identity, "."+row["table_name"])
+		var parents []string
+		if inherits := row["inherits"]; inherits != "" && inherits != "null" {
+			parents = strings.Split(inherits, ", ")
+		}
+		if parent := row["parent_table"]; parent != "" && parent != "null" {
+			parents = append(parents, row["parent_schema"]+"."+parent)
+		}
+		for _, parent := range parents {
+			if !strings.Contains(parent, ".") {
+				parent = schema + "." + parent
+			} else if strings.HasPrefix(parent, row["table_schema"]+".") {
+				parent = schema + strings.TrimPrefix(parent, row["table_schema"])
+			}
+			deps = append(deps, Dependency{Object: ObjectRef{TableSchemaType, change.Identity},
+				Referenced: ObjectRef{TableSchemaType, parent}})
+		}
+	}
+	return deps
 }
@@ -0,0 +1,111 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func relRow(name, key, parent, bound string) map[string]string {
+	row := map[string]string{"compare_name": "s." + name, "table_schema": "s", "table_name": name, "table_type": "TABLE",
+		"is_insertable_into": "YES", "partition_key": "null", "partition_columns": "null", "parent_schema": "null",
+		"parent_table": "null", "partition_bound": "null"}
+	if key != "null" {
+		row["partition_key"], row["partition_columns"] = key, "created timestamp with time zone NOT NULL"
+	}
+	if parent != "null" {
+		row["parent_schema"], row["parent_table"], row["partition_bound"] = "s", parent, bound
+	}
+	return row
+}
+
+func TestTable(t *testing.T) {
+	assert.Equal(t, []Stringer{
+		NewLine("CREATE TABLE s.events (created timestamp with time zone NOT NULL) PARTITION BY RANGE (created);"),
+		NewLine("CREATE TABLE s.events_old PARTITION OF s.events DEFAULT;"),
+		NewLine("CREATE TABLE s.plain();"),
+	}, diffOutput(NewTableSchema(TableRows{
+		relRow("events", "RANGE (created)", "null", ""),
+		relRow("events_old", "null", "events", "DEFAULT"),
+		relRow("plain", "null", "null", ""),
+	}, "*"), NewTableSchema(nil, "*")))
+
+	// Rows saved before partitions were known have none of their keys
+	old := map[string]string{"compare_name": "s.plain", "table_schema": "s", "table_name": "plain", "table_type": "TABLE"}
+	assert.Empty(t, diffOutput(NewTableSchema(TableRows{relRow("plain", "null", "null", "")}, "*"),
+		NewTableSchema(TableRows{old}, "*")))
+
+	bound := "FOR VALUES FROM ('2020-01-01') TO ('2021-01-01')"
+	assert.Equal(t, []Stringer{
+		NewLine("ALTER TABLE s.events DETACH PARTITION s.events_2020;"),
+		NewLine("ALTER TABLE s.events ATTACH PARTITION s.events_2020 " + bound + ";"),
+	}, diffOutput(NewTableSchema(TableRows{relRow("events_2020", "null", "events", bound)}, "*"),
+		NewTableSchema(TableRows{relRow("events_2020", "null", "events", "DEFAULT")}, "*")))
+	assert.Equal(t, []Stringer{NewLine("ALTER TABLE s.events DETACH PARTITION s.events_2020;")},
+		diffOutput(NewTableSchema(TableRows{relRow("events_2020", "null", "null", "")}, "*"),
+			NewTableSchema(TableRows{relRow("events_2020", "null", "events", bound)}, "*")))
+
+	assert.Equal(t, []Stringer{NewWarning("-- WARNING: table s.events is partitioned by LIST (created) in db1 but null in db2. " +
+		"PostgreSQL cannot change how a table is partitioned, so it must be created again.")},
+		diffOutput(NewTableSchema(TableRows{relRow("events", "LIST (created)", "null", "")}, "*"),
+			NewTableSchema(TableRows{relRow("events", "null", "null", "")}, "*")))
+
+	inherits := func(row map[string]string, parents string) map[string]string {
+		row["inherits"] = parents
+		return row
+	}
+	assert.Equal(t, []Stringer{NewLine("CREATE TABLE s.employee() INHERITS (s.person, other.audited);")},
+		diffOutput(NewTableSchema(TableRows{
+			inherits(relRow("employee", "null", "null", ""), "person, other.audited"),
+		}, "*"), NewTableSchema(nil, "*")))
+	assert.Equal(t, []Stringer{
+		NewLine("ALTER TABLE s.employee NO INHERIT other.audited;"),
+		NewLine("ALTER TABLE s.employee INHERIT s.person;"),
+	}, diffOutput(NewTableSchema(TableRows{inherits(relRow("employee", "null", "null", ""), "person")}, "*"),
+		NewTableSchema(TableRows{inherits(relRow("employee", "null", "null", ""), "other.audited")}, "*")))
+}
+
+func TestInheritedColumn(t *testing.T) {
+	column := func(inherited string) map[string]string {
+		return map[string]string{"compare_name": "s.employee.00001name", "table_schema": "s", "table_name": "employee",
+			"column_name": "name", "data_type": "text", "is_nullable": "YES", "column_default": "null",
+			"is_identity": "NO", "is_inherited": inherited}
+	}
+	assert.Empty(t, diffOutput(NewColumnSchema(ColumnRows{column("YES")}, "*"), NewColumnSchema(nil, "*")))
+	assert.Empty(t, diffOutput(NewColumnSchema(nil, "*"), NewColumnSchema(ColumnRows{column("YES")}, "*")))
+	assert.Equal(t, []Stringer{NewLine("ALTER TABLE s.employee ADD COLUMN name text;")},
+		diffOutput(NewColumnSchema(ColumnRows{column("NO")}, "*"), NewColumnSchema(nil, "*")))
+}
+
+type tableSource TableRows
+
+func (s tableSource) Rows(schemaType string) ([]map[string]string, error) {
+	return s, nil
+}
+
+func (s tableSource) Identify(num int) *Notice {
+	return NewNotice("-- test")
+}
+
+func TestTableParentsFirst(t *testing.T) {
+	child := relRow("a_employee", "null", "null", "")
+	child["inherits"] = "z_person"
+	comp := CompareByFactories(NewRowSchemaFactory(tableSource{
+		relRow("a_events_2020", "null", "z_events", "DEFAULT"),
+		child,
+		relRow("z_events", "RANGE (created)", "null", ""),
+		relRow("z_person", "null", "null", ""),
+	}, "*"), NewRowSchemaFactory(tableSource{
+		relRow("a_old", "RANGE (created)", "null", ""),
+		relRow("b_old_2020", "null", "a_old", "DEFAULT"),
+	}, "*"), TableSchemaType)
+	var idents []string
+	for _, change := range comp.Changes {
+		idents = append(idents, change.Identity)
+	}
+	assert.Equal(t, []string{"s.b_old_2020", "s.a_old", "s.z_events", "s.a_events_2020", "s.z_person", "s.a_employee"},
+		idents)
+}
@@ -10,9 +10,19 @@ DROP USER IF EXISTS u1;
 CREATE USER u1 PASSWORD 'asdf' INHERIT;
 GRANT u1 to pgdiff_parent;
 
-CREATE DATABASE db1 WITH OWNER = u1 TEMPLATE = template0;
-CREATE DATABASE db2 WITH OWNER = u1 TEMPLATE = template0;
+-- The templates hold the objects only a superuser can create, see run-first.sh.
+CREATE DATABASE db1 WITH OWNER = u1 TEMPLATE = pgdiff_template1;
+CREATE DATABASE db2 WITH OWNER = u1 TEMPLATE = pgdiff_template2;
 
 DROP USER IF EXISTS u2;
 CREATE USER u2 PASSWORD 'asdf' INHERIT;
 GRANT u2 TO u1;
+
+-- Subscriptions can only be created by members of pg_create_subscription, from PostgreSQL 16.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = 'pg_create_subscription') THEN
+        GRANT pg_create_subscription TO u1;
+    END IF;
+END
+$$;
@@ -0,0 +1,18 @@
+/*
+ * Copyright (c) 2022 Facefunk. All rights reserved.
+ * Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+ */
+
+-- A database cannot be dropped while it has subscriptions, so they are dropped first. They have no replication slot,
+-- so no connection to the publisher is needed.
+DO $$
+DECLARE
+    sub record;
+BEGIN
+    FOR sub IN SELECT s.subname FROM pg_catalog.pg_subscription s
+        WHERE s.subdbid = (SELECT oid FROM pg_catalog.pg_database WHERE datname = current_database())
+    LOOP
+        EXECUTE format('DROP SUBSCRIPTION %I', sub.subname);
+    END LOOP;
+END
+$$;
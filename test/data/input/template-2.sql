@@ -0,0 +1,31 @@
+/*
+ * Copyright (c) 2022 Facefunk. All rights reserved.
+ * Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+ */
+
+-- The template of db2, loaded by run-first.sh, holding the objects only a superuser can create.
+
+-- Shared with db1
+CREATE FUNCTION public.noop() RETURNS event_trigger LANGUAGE plpgsql AS $$
+BEGIN
+END
+$$;
+CREATE EXTENSION postgres_fdw;
+GRANT USAGE ON FOREIGN DATA WRAPPER postgres_fdw TO PUBLIC;
+
+-- FOREIGN_DATA_WRAPPER
+CREATE FOREIGN DATA WRAPPER checked;
+CREATE FOREIGN DATA WRAPPER dummy OPTIONS (debug 'false', level '1');
+CREATE FOREIGN DATA WRAPPER gone;  -- to be removed from this db
+
+-- OPERATOR_CLASS
+CREATE OPERATOR FAMILY public.gone_fam USING hash;  -- to be removed from this db
+CREATE OPERATOR FAMILY public.int_fam USING btree;
+CREATE OPERATOR CLASS public.int_ops FOR TYPE integer USING btree FAMILY public.int_fam AS
+    OPERATOR 1 <, OPERATOR 3 =, OPERATOR 5 >, FUNCTION 1 btint4cmp(integer, integer);
+
+-- EVENT_TRIGGER, last so they do not fire while the rest is created
+CREATE EVENT TRIGGER audit ON ddl_command_end WHEN TAG IN ('CREATE TABLE') EXECUTE FUNCTION public.noop();
+CREATE EVENT TRIGGER dropped ON ddl_command_start EXECUTE FUNCTION public.noop();  -- to be removed from this db
+CREATE EVENT TRIGGER logged ON ddl_command_end EXECUTE FUNCTION public.noop();
+ALTER EVENT TRIGGER logged DISABLE;
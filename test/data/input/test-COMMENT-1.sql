@@ -0,0 +1,23 @@
+/*
+ * Copyright (c) 2022 Facefunk. All rights reserved.
+ * Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+ */
+
+-- Schema s1
+CREATE SCHEMA s1;
+CREATE TABLE s1.t (id integer CONSTRAINT t_id_check CHECK (id > 0));
+CREATE VIEW s1.v AS SELECT 1 AS one;
+CREATE FUNCTION s1.f(integer) RETURNS integer LANGUAGE sql AS 'SELECT $1 + 1';
+COMMENT ON SCHEMA s1 IS 'first';
+COMMENT ON TABLE s1.t IS 'table t';
+COMMENT ON COLUMN s1.t.id IS 'id''s';
+COMMENT ON FUNCTION s1.f(integer) IS 'adds one';
+
+-- Schema s2
+CREATE SCHEMA s2;
+CREATE TABLE s2.t (id integer CONSTRAINT t_id_check CHECK (id > 0));
+CREATE VIEW s2.v AS SELECT 1 AS one;
+CREATE FUNCTION s2.f(integer) RETURNS integer LANGUAGE sql AS 'SELECT $1 + 1';
+COMMENT ON TABLE s2.t IS 'old';
+COMMENT ON CONSTRAINT t_id_check ON s2.t IS 'gone';
+COMMENT ON VIEW s2.v IS 'view';
@@ -0,0 +1,14 @@
+/*
+ * Copyright (c) 2022 Facefunk. All rights reserved.
+ * Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+ */
+
+-- Schema s1
+CREATE SCHEMA s1;
+CREATE DOMAIN s1.code AS text CONSTRAINT code_length CHECK (length(VALUE) = 3);
+CREATE DOMAIN s1.positive AS integer DEFAULT 1 NOT NULL CONSTRAINT positive_check CHECK (VALUE > 0);
+
+-- Schema s2
+CREATE SCHEMA s2;
+CREATE DOMAIN s2.positive AS integer CONSTRAINT positive_check CHECK (VALUE >= 0) CONSTRAINT small CHECK (VALUE < 100);
+CREATE DOMAIN s2.unused AS text;
@@ -0,0 +1,16 @@
+/*
+ * Copyright (c) 2022 Facefunk. All rights reserved.
+ * Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+ */
+
+-- Schema s1
+CREATE SCHEMA s1;
+CREATE TYPE s1.color AS ENUM ('red', 'green');
+CREATE TYPE s1.mood AS ENUM ('sad', 'ok', 'happy');
+CREATE TYPE s1.size AS ENUM ('small', 'medium', 'large');
+
+-- Schema s2
+CREATE SCHEMA s2;
+CREATE TYPE s2.mood AS ENUM ('sad', 'happy');
+CREATE TYPE s2.shape AS ENUM ('square');
+CREATE TYPE s2.size AS ENUM ('small', 'mid', 'large');
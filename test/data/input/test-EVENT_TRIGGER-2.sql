@@ -0,0 +1,7 @@
+/*
+ * Copyright (c) 2022 Facefunk. All rights reserved.
+ * Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+ */
+
+-- Event triggers can only be created by a superuser, so those of db1 and db2 are in
+-- template-1.sql and template-2.sql, which run-first.sh loads into the templates the databases are copied from.
@@ -0,0 +1,8 @@
+/*
+ * Copyright (c) 2022 Facefunk. All rights reserved.
+ * Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+ */
+
+CREATE SCHEMA s1;
+CREATE EXTENSION citext WITH SCHEMA s1 VERSION '1.5';
+CREATE EXTENSION hstore WITH SCHEMA public VERSION '1.4';  -- to be added to db2
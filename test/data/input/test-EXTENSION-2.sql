@@ -0,0 +1,8 @@
+/*
+ * Copyright (c) 2022 Facefunk. All rights reserved.
+ * Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+ */
+
+CREATE SCHEMA s2;
+CREATE EXTENSION citext WITH SCHEMA s2 VERSION '1.4';
+CREATE EXTENSION pgcrypto WITH SCHEMA public;  -- to be removed from this db
@@ -0,0 +1,24 @@
+/*
+ * Copyright (c) 2022 Facefunk. All rights reserved.
+ * Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+ */
+
+CREATE SERVER loopback FOREIGN DATA WRAPPER postgres_fdw;
+
+-- Schema s1
+CREATE SCHEMA s1;
+CREATE FOREIGN TABLE s1.copied (id integer) SERVER loopback;
+CREATE FOREIGN TABLE s1.remote (
+    id integer NOT NULL,
+    name text OPTIONS (column_name 'title'),
+    added date
+) SERVER loopback OPTIONS (schema_name 's1', table_name 'remote');
+
+-- Schema s2
+CREATE SCHEMA s2;
+CREATE FOREIGN TABLE s2.dropped (id integer) SERVER loopback;
+CREATE FOREIGN TABLE s2.remote (
+    id bigint,
+    name text,
+    gone text
+) SERVER loopback OPTIONS (table_name 'remote');
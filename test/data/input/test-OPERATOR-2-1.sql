@@ -0,0 +1,13 @@
+/*
+ * Copyright (c) 2022 Facefunk. All rights reserved.
+ * Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+ */
+
+CREATE FUNCTION public.int_differ(integer, integer) RETURNS boolean LANGUAGE sql IMMUTABLE AS 'SELECT $1 <> $2';
+CREATE FUNCTION public.int_same(integer, integer) RETURNS boolean LANGUAGE sql IMMUTABLE AS 'SELECT $1 = $2';
+CREATE FUNCTION public.negate_int(integer) RETURNS integer LANGUAGE sql IMMUTABLE AS 'SELECT -$1';
+CREATE OPERATOR public.=== (FUNCTION = int_same, LEFTARG = integer, RIGHTARG = integer, COMMUTATOR = ===,
+    NEGATOR = !==, RESTRICT = eqsel, JOIN = eqjoinsel);
+CREATE OPERATOR public.!== (FUNCTION = int_differ, LEFTARG = integer, RIGHTARG = integer, COMMUTATOR = !==,
+    NEGATOR = ===, RESTRICT = neqsel, JOIN = neqjoinsel);
+CREATE OPERATOR public.~~~ (FUNCTION = negate_int, RIGHTARG = integer);  -- to be added to db2
@@ -0,0 +1,21 @@
+/*
+ * Copyright (c) 2022 Facefunk. All rights reserved.
+ * Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+ */
+
+-- Schema s1
+CREATE SCHEMA s1;
+CREATE TABLE s1.t (id integer);
+CREATE TABLE s1.u (id integer);
+ALTER TABLE s1.t ENABLE ROW LEVEL SECURITY;
+CREATE POLICY ins ON s1.t FOR INSERT WITH CHECK (id > 0);
+CREATE POLICY own ON s1.t FOR SELECT TO u2 USING (id < 100);
+
+-- Schema s2
+CREATE SCHEMA s2;
+CREATE TABLE s2.t (id integer);
+CREATE TABLE s2.u (id integer);
+CREATE POLICY own ON s2.t FOR SELECT USING (true);
+ALTER TABLE s2.u ENABLE ROW LEVEL SECURITY;
+ALTER TABLE s2.u FORCE ROW LEVEL SECURITY;
+CREATE POLICY gone ON s2.u USING (true);
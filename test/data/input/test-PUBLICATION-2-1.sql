@@ -0,0 +1,9 @@
+/*
+ * Copyright (c) 2022 Facefunk. All rights reserved.
+ * Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+ */
+
+CREATE TABLE public.t1 (id integer PRIMARY KEY, v integer);
+CREATE TABLE public.t2 (id integer PRIMARY KEY);
+CREATE PUBLICATION added FOR TABLE public.t2;  -- to be added to db2
+CREATE PUBLICATION pub1 FOR TABLE public.t1 WHERE (id > 10), public.t2 WITH (publish = 'insert, update');
@@ -0,0 +1,16 @@
+/*
+ * Copyright (c) 2022 Facefunk. All rights reserved.
+ * Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+ */
+
+-- Schema s1
+CREATE SCHEMA s1;
+CREATE TABLE s1.t (id integer);
+CREATE RULE guard AS ON UPDATE TO s1.t WHERE new.id < 0 DO INSTEAD NOTHING;
+CREATE RULE keep AS ON DELETE TO s1.t DO INSTEAD NOTHING;
+
+-- Schema s2
+CREATE SCHEMA s2;
+CREATE TABLE s2.t (id integer);
+CREATE RULE gone AS ON INSERT TO s2.t DO INSTEAD NOTHING;
+CREATE RULE guard AS ON UPDATE TO s2.t WHERE new.id < 10 DO INSTEAD NOTHING;
@@ -0,0 +1,7 @@
+/*
+ * Copyright (c) 2022 Facefunk. All rights reserved.
+ * Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+ */
+
+CREATE SERVER added FOREIGN DATA WRAPPER postgres_fdw OPTIONS (port '5433');  -- to be added to db2
+CREATE SERVER loopback VERSION '2' FOREIGN DATA WRAPPER postgres_fdw OPTIONS (host 'localhost', dbname 'db2');
@@ -0,0 +1,7 @@
+/*
+ * Copyright (c) 2022 Facefunk. All rights reserved.
+ * Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+ */
+
+CREATE SERVER dropped FOREIGN DATA WRAPPER postgres_fdw;  -- to be removed from this db
+CREATE SERVER loopback FOREIGN DATA WRAPPER postgres_fdw OPTIONS (host 'remote', port '5432');
@@ -0,0 +1,11 @@
+/*
+ * Copyright (c) 2022 Facefunk. All rights reserved.
+ * Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+ */
+
+-- Subscriptions are created without connecting, so the publications need not exist. u1 cannot read their connection
+-- strings, so sub2 is only added in comments.
+CREATE SUBSCRIPTION sub1 CONNECTION 'host=localhost dbname=db2 user=u1 password=asdf' PUBLICATION pub1, pub2
+    WITH (connect = false, slot_name = NONE, synchronous_commit = 'local');
+CREATE SUBSCRIPTION sub2 CONNECTION 'host=localhost dbname=db2 user=u1 password=asdf' PUBLICATION pub1
+    WITH (connect = false, slot_name = NONE);
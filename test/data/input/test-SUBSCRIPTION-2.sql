@@ -0,0 +1,9 @@
+/*
+ * Copyright (c) 2022 Facefunk. All rights reserved.
+ * Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+ */
+
+CREATE SUBSCRIPTION sub1 CONNECTION 'host=localhost dbname=db1 user=u1 password=asdf' PUBLICATION pub1
+    WITH (connect = false, slot_name = NONE);
+CREATE SUBSCRIPTION sub3 CONNECTION 'host=localhost dbname=db1 user=u1 password=asdf' PUBLICATION pub1
+    WITH (connect = false, slot_name = NONE);  -- to be removed from this db
@@ -0,0 +1,14 @@
+/*
+ * Copyright (c) 2022 Facefunk. All rights reserved.
+ * Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+ */
+
+-- Schema s1
+CREATE SCHEMA s1;
+CREATE TYPE s1.pair AS (a integer, b character varying(10), d text);
+CREATE TYPE s1.point3 AS (x double precision, y double precision, z double precision);
+
+-- Schema s2
+CREATE SCHEMA s2;
+CREATE TYPE s2.pair AS (a bigint, c text, b character varying(10));
+CREATE TYPE s2.unused AS (x integer);
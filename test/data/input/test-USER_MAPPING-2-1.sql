@@ -0,0 +1,8 @@
+/*
+ * Copyright (c) 2022 Facefunk. All rights reserved.
+ * Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+ */
+
+CREATE SERVER loopback FOREIGN DATA WRAPPER postgres_fdw;
+CREATE USER MAPPING FOR PUBLIC SERVER loopback OPTIONS (user 'guest');  -- to be added to db2
+CREATE USER MAPPING FOR u1 SERVER loopback OPTIONS (user 'u1', password 'asdf');
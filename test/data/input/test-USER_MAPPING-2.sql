@@ -0,0 +1,8 @@
+/*
+ * Copyright (c) 2022 Facefunk. All rights reserved.
+ * Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+ */
+
+CREATE SERVER loopback FOREIGN DATA WRAPPER postgres_fdw;
+CREATE USER MAPPING FOR u1 SERVER loopback OPTIONS (user 'u2');
+CREATE USER MAPPING FOR u2 SERVER loopback;  -- to be removed from this db
@@ -0,0 +1,6 @@
+COMMENT ON COLUMN s2.t.id IS 'id''s';
+COMMENT ON CONSTRAINT t_id_check ON s2.t IS NULL;
+COMMENT ON FUNCTION s2.f(integer) IS 'adds one';
+COMMENT ON SCHEMA s2 IS 'first';
+COMMENT ON TABLE s2.t IS 'table t';
+COMMENT ON VIEW s2.v IS NULL;
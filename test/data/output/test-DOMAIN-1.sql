@@ -0,0 +1,7 @@
+CREATE DOMAIN s2.code AS text CONSTRAINT code_length CHECK ((length(VALUE) = 3));
+ALTER DOMAIN s2.positive SET DEFAULT 1;
+ALTER DOMAIN s2.positive SET NOT NULL;
+ALTER DOMAIN s2.positive DROP CONSTRAINT positive_check; -- CHECK ((VALUE >= 0))
+ALTER DOMAIN s2.positive DROP CONSTRAINT small; -- CHECK ((VALUE < 100))
+ALTER DOMAIN s2.positive ADD CONSTRAINT positive_check CHECK ((VALUE > 0));
+DROP DOMAIN s2.unused;
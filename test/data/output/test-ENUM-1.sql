@@ -0,0 +1,4 @@
+CREATE TYPE s2.color AS ENUM ('red', 'green');
+ALTER TYPE s2.mood ADD VALUE 'ok' AFTER 'sad';
+DROP TYPE s2.shape;
+ALTER TYPE s2.size RENAME VALUE 'mid' TO 'medium';
@@ -0,0 +1,5 @@
+CREATE EVENT TRIGGER added ON sql_drop EXECUTE FUNCTION noop();
+DROP EVENT TRIGGER audit;
+CREATE EVENT TRIGGER audit ON ddl_command_end WHEN TAG IN ('ALTER TABLE', 'CREATE TABLE') EXECUTE FUNCTION noop();
+DROP EVENT TRIGGER dropped;
+ALTER EVENT TRIGGER logged ENABLE;
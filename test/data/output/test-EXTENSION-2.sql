@@ -0,0 +1,4 @@
+ALTER EXTENSION citext SET SCHEMA s1;
+ALTER EXTENSION citext UPDATE TO '1.5';
+CREATE EXTENSION hstore WITH SCHEMA public VERSION '1.4';
+DROP EXTENSION pgcrypto;
@@ -0,0 +1,4 @@
+CREATE FOREIGN DATA WRAPPER added;
+ALTER FOREIGN DATA WRAPPER checked VALIDATOR postgresql_fdw_validator;
+ALTER FOREIGN DATA WRAPPER dummy OPTIONS (DROP level, SET debug 'true', ADD mode 'fast');
+DROP FOREIGN DATA WRAPPER gone;
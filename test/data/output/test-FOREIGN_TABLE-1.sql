@@ -0,0 +1,8 @@
+CREATE FOREIGN TABLE s2.copied (id integer) SERVER loopback;
+DROP FOREIGN TABLE s2.dropped;
+ALTER FOREIGN TABLE s2.remote OPTIONS (ADD schema_name 's1');
+ALTER FOREIGN TABLE s2.remote ALTER COLUMN id TYPE integer;
+ALTER FOREIGN TABLE s2.remote ALTER COLUMN id SET NOT NULL;
+ALTER FOREIGN TABLE s2.remote ALTER COLUMN name OPTIONS (ADD column_name 'title');
+ALTER FOREIGN TABLE s2.remote ADD COLUMN added date;
+ALTER FOREIGN TABLE s2.remote DROP COLUMN gone;
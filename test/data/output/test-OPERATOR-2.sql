@@ -0,0 +1,3 @@
+DROP OPERATOR public.<#> (integer, integer);
+ALTER OPERATOR public.=== (integer, integer) SET (RESTRICT = eqsel, JOIN = eqjoinsel);
+CREATE OPERATOR public.~~~ (FUNCTION = negate_int, RIGHTARG = integer);
@@ -0,0 +1,4 @@
+DROP OPERATOR FAMILY public.gone_fam USING hash;
+ALTER OPERATOR FAMILY public.int_fam USING btree ADD OPERATOR 1 <(integer,bigint);
+DROP OPERATOR CLASS public.int_ops USING btree;
+CREATE OPERATOR CLASS public.int_ops FOR TYPE integer USING btree FAMILY public.int_fam AS OPERATOR 1 <(integer,integer), OPERATOR 2 <=(integer,integer), OPERATOR 3 =(integer,integer), OPERATOR 4 >=(integer,integer), OPERATOR 5 >(integer,integer), FUNCTION 1 (integer, integer) btint4cmp(integer,integer);
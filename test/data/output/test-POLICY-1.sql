@@ -0,0 +1,6 @@
+ALTER TABLE s2.t ENABLE ROW LEVEL SECURITY;
+CREATE POLICY ins ON s2.t AS PERMISSIVE FOR INSERT TO PUBLIC WITH CHECK ((id > 0));
+ALTER POLICY own ON s2.t TO u2 USING ((id < 100));
+ALTER TABLE s2.u NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE s2.u DISABLE ROW LEVEL SECURITY;
+DROP POLICY gone ON s2.u;
@@ -0,0 +1,6 @@
+CREATE PUBLICATION added FOR TABLE public.t2 WITH (publish = 'insert, update, delete, truncate', publish_via_partition_root = false);
+DROP PUBLICATION gone;
+ALTER PUBLICATION pub1 SET (publish = 'insert, update', publish_via_partition_root = false);
+ALTER PUBLICATION pub1 DROP TABLE public.t1;
+ALTER PUBLICATION pub1 ADD TABLE public.t1 WHERE (id > 10);
+ALTER PUBLICATION pub1 ADD TABLE public.t2;
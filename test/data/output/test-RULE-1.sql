@@ -0,0 +1,6 @@
+DROP RULE gone ON s2.t;
+CREATE OR REPLACE RULE guard AS
+    ON UPDATE TO s2.t
+   WHERE (new.id < 0) DO INSTEAD NOTHING;
+CREATE OR REPLACE RULE keep AS
+    ON DELETE TO s2.t DO INSTEAD NOTHING;
@@ -0,0 +1,3 @@
+CREATE SERVER added FOREIGN DATA WRAPPER postgres_fdw OPTIONS (port '5433');
+DROP SERVER dropped;
+ALTER SERVER loopback VERSION '2' OPTIONS (DROP port, SET host 'localhost', ADD dbname 'db2');
@@ -0,0 +1,3 @@
+ALTER SUBSCRIPTION sub1 SET PUBLICATION pub1, pub2;
+ALTER SUBSCRIPTION sub1 SET (synchronous_commit = 'local');
+DROP SUBSCRIPTION sub3;
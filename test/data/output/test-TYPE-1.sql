@@ -0,0 +1,5 @@
+ALTER TYPE s2.pair DROP ATTRIBUTE c;
+ALTER TYPE s2.pair ALTER ATTRIBUTE a TYPE integer;
+ALTER TYPE s2.pair ADD ATTRIBUTE d text;
+CREATE TYPE s2.point3 AS (x double precision, y double precision, z double precision);
+DROP TYPE s2.unused;
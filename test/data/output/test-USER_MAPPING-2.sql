@@ -0,0 +1,3 @@
+CREATE USER MAPPING FOR PUBLIC SERVER loopback OPTIONS (user 'guest');
+ALTER USER MAPPING FOR u1 SERVER loopback OPTIONS (SET user 'u1', ADD password '********');
+DROP USER MAPPING FOR u2 SERVER loopback;
@@ -58,7 +58,7 @@ func TestDB(t *testing.T) {
 			}
 
 			// Generate output.
-			strs := pgdiff.CompareByFactories(facs[0], facs[1], s.op)
+			strs := pgdiff.CompareByFactories(facs[0], facs[1], s.op).Stringers()
 
 			// Close factories every time to avoid collisions with input.
 			for _, fac := range facs {
@@ -87,6 +87,17 @@ func TestDB(t *testing.T) {
 			// Compare
 			assert.Equal(t, string(out), bStr, te.name)
 		}
+
+		// Tear down what would stop start-fresh.sql dropping the databases.
+		for _, dbName := range []string{"db1", "db2"} {
+			info := newDbInfo()
+			info.DbName = dbName
+			info.DbUser = "u1"
+			err = singleBatchFromFile(info, inputData+"/tear-down.sql")
+			if err != nil {
+				t.Fatalf("error with suite: %s: %s", s.name, err)
+			}
+		}
 	}
 }
 
@@ -123,7 +134,10 @@ func runBatchFromFile(db *sql.DB, fname string) error {
 		return err
 	}
 	//fmt.Println(string(query))
-	qs := splitSQL(string(query))
+	qs, err := pgdiff.SplitSQL(string(query))
+	if err != nil {
+		return err
+	}
 	for _, q := range qs {
 		_, err = db.Exec(q)
 		if err != nil {
@@ -133,78 +147,20 @@ func runBatchFromFile(db *sql.DB, fname string) error {
 	return nil
 }
 
-const (
-	defaultState = iota
-	quoteState
-	dollarState
-	commentState
-	blockState
-)
-
-// splitSQL splits SQL batches up into separate queries because Postgres clients must send queries to the server one at
-// a time. psql does this splitting but Go client libs don't.
-// TODO: Possibly improve this simplistic interpretation.
-func splitSQL(query string) []string {
-	var out []string
-	var state uint8
-	var li int
-	var prev rune
-	for i, c := range query {
-		switch state {
-		case defaultState:
-			switch c {
-			case ';':
-				out = append(out, query[li:i+1])
-				li = i + 1
-			case '\'':
-				state = quoteState
-			case '$':
-				if prev == '$' {
-					state = dollarState
-				}
-			case '-':
-				if prev == '-' {
-					state = commentState
-				}
-			case '*':
-				if prev == '/' {
-					state = blockState
-				}
-			}
-		case quoteState:
-			if c == '\'' {
-				state = defaultState
-			}
-		case dollarState:
-			if c == '$' && prev == '$' {
-				state = defaultState
-			}
-		case commentState:
-			if c == '\n' {
-				state = defaultState
-			}
-		case blockState:
-			if c == '/' && prev == '*' {
-				state = defaultState
-			}
-		}
-		prev = c
-	}
-	return out
-}
-
 func TestSplitSQL(t *testing.T) {
 	query, err := os.ReadFile(inputData + "/test-FUNCTION-1.sql")
 	if err != nil {
 		t.Fatal(err)
 	}
-	strs := splitSQL(string(query))
+	strs, err := pgdiff.SplitSQL(string(query))
+	assert.NoError(t, err)
 	assert.Equal(t, 6, len(strs))
 
 	query, err = os.ReadFile(inputData + "/test-COLUMN-3-1.sql")
 	if err != nil {
 		t.Fatal(err)
 	}
-	strs = splitSQL(string(query))
+	strs, err = pgdiff.SplitSQL(string(query))
+	assert.NoError(t, err)
 	assert.Equal(t, 4, len(strs))
 }
@@ -8,7 +8,17 @@
 # In the interest of security, we don't actually create a fully-fledged superuser. Instead we create a role with all the
 # necessary permissions to act as a superuser for the purposes of testing pgdiff against live databases but without risk
 # to any third-party data hosted on the same server.
+#
+# Foreign data wrappers, event triggers and operator classes can only be created by a superuser, so they are created
+# here in the templates db1 and db2 are copied from, along with postgres_fdw for the servers and foreign tables the
+# tests create. The tests of subscriptions need PostgreSQL 16, which lets members of pg_create_subscription create them.
 
+dir=$(dirname "$0")
 sql="CREATE ROLE pgdiff_parent PASSWORD 'asdf' NOSUPERUSER CREATEDB CREATEROLE INHERIT LOGIN;
-CREATE DATABASE pgdiff_parent OWNER = pgdiff_parent TEMPLATE = template0;"
+CREATE DATABASE pgdiff_parent OWNER = pgdiff_parent TEMPLATE = template0;
+CREATE DATABASE pgdiff_template1 IS_TEMPLATE = true TEMPLATE = template0;
+CREATE DATABASE pgdiff_template2 IS_TEMPLATE = true TEMPLATE = template0;
+GRANT pg_create_subscription TO pgdiff_parent WITH ADMIN OPTION;"
 sudo -u postgres psql <<< "$sql"
+sudo -u postgres psql -d pgdiff_template1 < "$dir/data/input/template-1.sql"
+sudo -u postgres psql -d pgdiff_template2 < "$dir/data/input/template-2.sql"
@@ -14,5 +14,5 @@ func TestParseTests(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	assert.Equal(t, 14, len(suites))
+	assert.Equal(t, 30, len(suites))
 }
@@ -67,6 +67,19 @@ func (c *TriggerSchema) NextRow() bool {
 	return !c.done
 }
 
+// Identity returns the qualified name of the current row's object
+func (c *TriggerSchema) Identity() string {
+	return c.get("schema_name") + "." + c.get("table_name") + "." + c.get("trigger_name")
+}
+
+// Row returns a copy of the current row
+func (c *TriggerSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
 // Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
 func (c *TriggerSchema) Compare(obj Schema) (int, *Error) {
 	c2, ok := obj.(*TriggerSchema)
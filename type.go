@@ -0,0 +1,186 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/joncrlsn/misc"
+)
+
+// typeAttribute is a single attribute of a composite type, as held in the JSON array of a TYPE row.
+type typeAttribute struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// ==================================
+// TypeRows definition
+// ==================================
+
+// TypeRows is a sortable string map
+type TypeRows []map[string]string
+
+func (slice TypeRows) Len() int {
+	return len(slice)
+}
+
+func (slice TypeRows) Less(i, j int) bool {
+	return slice[i]["compare_name"] < slice[j]["compare_name"]
+}
+
+func (slice TypeRows) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+// ==================================
+// TypeSchema definition
+// (implements Schema -- defined in pgdiff.go)
+// ==================================
+
+// TypeSchema holds a slice of rows from one of the databases as well as
+// a reference to the current row of data we're viewing.
+type TypeSchema struct {
+	rows     TypeRows
+	rowNum   int
+	done     bool
+	dbSchema string
+	other    *TypeSchema
+}
+
+func NewTypeSchema(rows TypeRows, dbSchema string) *TypeSchema {
+	return &TypeSchema{rows: rows, rowNum: -1, dbSchema: dbSchema}
+}
+
+// get returns the value from the current row for the given key
+func (c *TypeSchema) get(key string) string {
+	if c.rowNum >= len(c.rows) {
+		return ""
+	}
+	return c.rows[c.rowNum][key]
+}
+
+// attributes returns the current row's attributes in order. They are held as a JSON array.
+func (c *TypeSchema) attributes() ([]typeAttribute, error) {
+	var attrs []typeAttribute
+	err := json.Unmarshal([]byte(c.get("attributes")), &attrs)
+	if err != nil {
+		return nil, fmt.Errorf("reading attributes of type %s: %s", c.Identity(), err)
+	}
+	return attrs, nil
+}
+
+// NextRow increments the rowNum and tells you whether or not there are more
+func (c *TypeSchema) NextRow() bool {
+	if c.rowNum >= len(c.rows)-1 {
+		c.done = true
+	}
+	c.rowNum = c.rowNum + 1
+	return !c.done
+}
+
+// Identity returns the qualified name of the current row's object
+func (c *TypeSchema) Identity() string {
+	return c.get("schema_name") + "." + c.get("type_name")
+}
+
+// Row returns a copy of the current row
+func (c *TypeSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
+// Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
+func (c *TypeSchema) Compare(obj Schema) (int, *Error) {
+	c2, ok := obj.(*TypeSchema)
+	if !ok {
+		return +999, NewError(fmt.Sprint("compare(obj) needs a TypeSchema instance", c2))
+	}
+	c.other = c2
+
+	val := misc.CompareStrings(c.get("compare_name"), c.other.get("compare_name"))
+	return val, nil
+}
+
+// typeName returns the qualified name of the type in db2
+func (c *TypeSchema) typeName() string {
+	schema := c.other.dbSchema
+	if schema == "*" {
+		schema = c.get("schema_name")
+	}
+	return schema + "." + c.get("type_name")
+}
+
+// Add returns SQL to create the composite type
+func (c *TypeSchema) Add() []Stringer {
+	attrs, err := c.attributes()
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	defs := make([]string, len(attrs))
+	for i, attr := range attrs {
+		defs[i] = attr.Name + " " + attr.Type
+	}
+	return []Stringer{NewLine(fmt.Sprintf("CREATE TYPE %s AS (%s);", c.typeName(), strings.Join(defs, ", ")))}
+}
+
+// Drop returns SQL to drop the composite type
+func (c TypeSchema) Drop() []Stringer {
+	return []Stringer{NewLine(fmt.Sprintf("DROP TYPE %s.%s;", c.get("schema_name"), c.get("type_name")))}
+}
+
+// Change handles the case where the type names match, but the attributes do not. Attributes are matched by name, so a
+// renamed attribute is dropped and added, and a warning is given if the attributes db2 keeps are in a different order.
+func (c *TypeSchema) Change() []Stringer {
+	if c.get("attributes") == c.other.get("attributes") {
+		return nil
+	}
+	attrs1, err := c.attributes()
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	attrs2, err := c.other.attributes()
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	typeName := c.typeName()
+	types1 := map[string]string{}
+	for _, attr := range attrs1 {
+		types1[attr.Name] = attr.Type
+	}
+	types2 := map[string]string{}
+	for _, attr := range attrs2 {
+		types2[attr.Name] = attr.Type
+	}
+
+	var strs []Stringer
+	var kept1, kept2 []string
+	for _, attr := range attrs2 {
+		if _, ok := types1[attr.Name]; !ok {
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER TYPE %s DROP ATTRIBUTE %s;", typeName, attr.Name)))
+			continue
+		}
+		kept2 = append(kept2, attr.Name)
+	}
+	for _, attr := range attrs1 {
+		type2, ok := types2[attr.Name]
+		switch {
+		case !ok:
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER TYPE %s ADD ATTRIBUTE %s %s;", typeName, attr.Name, attr.Type)))
+			continue
+		case type2 != attr.Type:
+			strs = append(strs, NewLine(fmt.Sprintf("ALTER TYPE %s ALTER ATTRIBUTE %s TYPE %s;", typeName, attr.Name, attr.Type)))
+		}
+		kept1 = append(kept1, attr.Name)
+	}
+	if strings.Join(kept1, ",") != strings.Join(kept2, ",") {
+		strs = append(strs, NewWarning(fmt.Sprintf("-- WARNING: type %s orders its attributes differently in db1 but "+
+			"PostgreSQL cannot reorder attributes.", typeName)))
+	}
+	return strs
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func typeRow(attributes string) map[string]string {
+	return map[string]string{"compare_name": "s.pair", "schema_name": "s", "type_name": "pair", "attributes": attributes}
+}
+
+func TestType(t *testing.T) {
+	assert.Equal(t, []Stringer{NewLine("CREATE TYPE s.pair AS (a integer, b character varying(10));")},
+		diffOutput(NewTypeSchema(TypeRows{
+			typeRow(`[{"name":"a","type":"integer"},{"name":"b","type":"character varying(10)"}]`),
+		}, "*"), NewTypeSchema(nil, "*")))
+	assert.Equal(t, []Stringer{NewLine("DROP TYPE s.pair;")}, diffOutput(NewTypeSchema(nil, "*"),
+		NewTypeSchema(TypeRows{typeRow(`[]`)}, "*")))
+	assert.Empty(t, diffOutput(NewTypeSchema(TypeRows{typeRow(`[{"name":"a","type":"integer"}]`)}, "*"),
+		NewTypeSchema(TypeRows{typeRow(`[{"name" : "a", "type" : "integer"}]`)}, "*")))
+
+	assert.Equal(t, []Stringer{
+		NewLine("ALTER TYPE s.pair DROP ATTRIBUTE c;"),
+		NewLine("ALTER TYPE s.pair ALTER ATTRIBUTE a TYPE bigint;"),
+		NewLine("ALTER TYPE s.pair ADD ATTRIBUTE d text;"),
+	}, diffOutput(NewTypeSchema(TypeRows{
+		typeRow(`[{"name":"a","type":"bigint"},{"name":"b","type":"text"},{"name":"d","type":"text"}]`),
+	}, "*"), NewTypeSchema(TypeRows{
+		typeRow(`[{"name":"a","type":"integer"},{"name":"c","type":"text"},{"name":"b","type":"text"}]`),
+	}, "*")))
+
+	assert.Equal(t, []Stringer{
+		NewWarning("-- WARNING: type s.pair orders its attributes differently in db1 but PostgreSQL cannot reorder attributes."),
+	}, diffOutput(NewTypeSchema(TypeRows{typeRow(`[{"name":"b","type":"text"},{"name":"a","type":"text"}]`)}, "*"),
+		NewTypeSchema(TypeRows{typeRow(`[{"name":"a","type":"text"},{"name":"b","type":"text"}]`)}, "*")))
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/joncrlsn/misc"
+)
+
+// ==================================
+// UserMappingRows definition
+// ==================================
+
+// UserMappingRows is a sortable string map
+type UserMappingRows []map[string]string
+
+func (slice UserMappingRows) Len() int {
+	return len(slice)
+}
+
+func (slice UserMappingRows) Less(i, j int) bool {
+	return slice[i]["compare_name"] < slice[j]["compare_name"]
+}
+
+func (slice UserMappingRows) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+// ==================================
+// UserMappingSchema definition
+// (implements Schema -- defined in pgdiff.go)
+// ==================================
+
+// UserMappingSchema holds a slice of rows from one of the databases as well as
+// a reference to the current row of data we're viewing.
+//
+// The values of secret options, such as passwords, are MaskedValue in the rows, see MaskOptions, so they are never
+// compared and the SQL sets them to MaskedValue, which must be replaced by hand.
+type UserMappingSchema struct {
+	rows     UserMappingRows
+	rowNum   int
+	done     bool
+	dbSchema string
+	other    *UserMappingSchema
+}
+
+func NewUserMappingSchema(rows UserMappingRows, dbSchema string) *UserMappingSchema {
+	return &UserMappingSchema{rows: rows, rowNum: -1, dbSchema: dbSchema}
+}
+
+// get returns the value from the current row for the given key
+func (c *UserMappingSchema) get(key string) string {
+	if c.rowNum >= len(c.rows) {
+		return ""
+	}
+	return c.rows[c.rowNum][key]
+}
+
+// NextRow increments the rowNum and tells you whether or not there are more
+func (c *UserMappingSchema) NextRow() bool {
+	if c.rowNum >= len(c.rows)-1 {
+		c.done = true
+	}
+	c.rowNum = c.rowNum + 1
+	return !c.done
+}
+
+// Identity returns the server and user of the current row's user mapping
+func (c *UserMappingSchema) Identity() string {
+	return c.get("server_name") + "." + c.get("user_name")
+}
+
+// Row returns a copy of the current row
+func (c *UserMappingSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
+// Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
+func (c *UserMappingSchema) Compare(obj Schema) (int, *Error) {
+	c2, ok := obj.(*UserMappingSchema)
+	if !ok {
+		return +999, NewError(fmt.Sprint("compare(obj) needs a UserMappingSchema instance", c2))
+	}
+	c.other = c2
+
+	val := misc.CompareStrings(c.get("compare_name"), c.other.get("compare_name"))
+	return val, nil
+}
+
+// mapping returns the user mapping's name as SQL expects it
+func (c *UserMappingSchema) mapping() string {
+	user := c.get("user_name")
+	if user != "PUBLIC" {
+		user = quoteIdent(user)
+	}
+	return fmt.Sprintf("USER MAPPING FOR %s SERVER %s", user, quoteIdent(c.get("server_name")))
+}
+
+// maskedWarning returns a warning that the secret option values of the user mapping have been masked
+func (c *UserMappingSchema) maskedWarning() Stringer {
+	return NewWarning(fmt.Sprintf("-- WARNING: the secret option values of %s are masked as %s and must be replaced.",
+		c.mapping(), quoteLiteral(MaskedValue)))
+}
+
+// Add returns SQL to create the user mapping
+func (c *UserMappingSchema) Add() []Stringer {
+	opts, err := parseOptions(c.get("options"))
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	var strs []Stringer
+	if opts.masked() {
+		strs = append(strs, c.maskedWarning())
+	}
+	return append(strs, NewLine(fmt.Sprintf("CREATE %s%s;", c.mapping(), opts.create())))
+}
+
+// Drop returns SQL to drop the user mapping
+func (c UserMappingSchema) Drop() []Stringer {
+	return []Stringer{NewLine(fmt.Sprintf("DROP %s;", c.mapping()))}
+}
+
+// Change handles the case where the server and user match, but the options do not
+func (c *UserMappingSchema) Change() []Stringer {
+	opts1, err := parseOptions(c.get("options"))
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	opts2, err := parseOptions(c.other.get("options"))
+	if err != nil {
+		return []Stringer{NewError(err.Error())}
+	}
+	alter := opts1.alter(opts2)
+	if alter == "" {
+		return nil
+	}
+	var strs []Stringer
+	if strings.Contains(alter, quoteLiteral(MaskedValue)) {
+		strs = append(strs, c.maskedWarning())
+	}
+	return append(strs, NewLine(fmt.Sprintf("ALTER %s%s;", c.mapping(), alter)))
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+package pgdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func userMappingRow(user, options string) map[string]string {
+	return map[string]string{"compare_name": "loopback." + user, "server_name": "loopback", "user_name": user,
+		"options": options}
+}
+
+func TestUserMapping(t *testing.T) {
+	masked := NewWarning("-- WARNING: the secret option values of USER MAPPING FOR u1 SERVER loopback are masked as '********' and must be replaced.")
+	assert.Equal(t, []Stringer{
+		masked,
+		NewLine("CREATE USER MAPPING FOR u1 SERVER loopback OPTIONS (password '********', user 'bob');"),
+		NewLine("CREATE USER MAPPING FOR PUBLIC SERVER loopback;"),
+	}, diffOutput(NewUserMappingSchema(UserMappingRows{userMappingRow("u1", `["password=********","user=bob"]`),
+		userMappingRow("PUBLIC", "[]")}, "*"), NewUserMappingSchema(nil, "*")))
+	assert.Equal(t, []Stringer{NewLine(`DROP USER MAPPING FOR "User" SERVER loopback;`)},
+		diffOutput(NewUserMappingSchema(nil, "*"),
+			NewUserMappingSchema(UserMappingRows{userMappingRow("User", "[]")}, "*")))
+
+	same := UserMappingRows{userMappingRow("u1", `["password=********"]`)}
+	assert.Empty(t, diffOutput(NewUserMappingSchema(same, "*"), NewUserMappingSchema(same, "*")))
+	assert.Equal(t, []Stringer{NewLine("ALTER USER MAPPING FOR u1 SERVER loopback OPTIONS (SET user 'bob');")},
+		diffOutput(NewUserMappingSchema(UserMappingRows{userMappingRow("u1", `["password=********","user=bob"]`)}, "*"),
+			NewUserMappingSchema(UserMappingRows{
+				userMappingRow("u1", `["password=********","user=alice"]`),
+			}, "*")))
+	assert.Equal(t, []Stringer{
+		masked,
+		NewLine("ALTER USER MAPPING FOR u1 SERVER loopback OPTIONS (ADD password '********');"),
+	}, diffOutput(NewUserMappingSchema(UserMappingRows{userMappingRow("u1", `["password=********"]`)}, "*"),
+		NewUserMappingSchema(UserMappingRows{userMappingRow("u1", `[]`)}, "*")))
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+//go:build go1.16
+// +build go1.16
+
+// Package verify lets an application check at startup that its database matches the schema it was built with.
+//
+//	//go:embed schema
+//	var schemaFS embed.FS
+//
+//	result, err := verify.Check(schemaFS, conn, verify.Options{Schema: "public", Policy: verify.Strict})
+package verify
+
+import (
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/facefunk/pgdiff"
+	"github.com/facefunk/pgdiff/db"
+	"github.com/facefunk/pgdiff/dump"
+	"github.com/joncrlsn/pgutil"
+)
+
+const defaultSchema = "public"
+
+const (
+	// Warn returns a Result describing any differences without treating them as an error.
+	Warn Policy = iota
+	// Strict returns a *MismatchError along with the Result if the database differs from the desired schema, other than
+	// by changes to the definitions of objects of renderedSchemaTypes.
+	Strict
+)
+
+// renderedSchemaTypes are the schema types whose definitions are compared as PostgreSQL renders them, eg. with
+// pg_get_viewdef and pg_get_functiondef. The schema files are only parsed, not run, so hand-written definitions
+// rarely match the rendered ones exactly. Changes to objects of these types are reported in the Result, but are not an
+// error under the Strict Policy. Objects of these types that are missing or extra still are.
+var renderedSchemaTypes = map[string]bool{
+	pgdiff.IndexSchemaType:           true,
+	pgdiff.ViewSchemaType:            true,
+	pgdiff.MatViewSchemaType:         true,
+	pgdiff.CheckConstraintSchemaType: true,
+	pgdiff.FunctionSchemaType:        true,
+	pgdiff.TriggerSchemaType:         true,
+	pgdiff.RuleSchemaType:            true,
+	pgdiff.PolicySchemaType:          true,
+}
+
+type (
+	// Policy decides whether differences between the database and the desired schema are an error.
+	Policy int
+
+	// Options configure Check.
+	Options struct {
+		// Schema is the schema to compare, or * for all non-system schemas. Defaults to public.
+		Schema string
+		// SchemaTypes are the schema types to compare. Defaults to pgdiff.AllSchemaTypes.
+		SchemaTypes []string
+		Policy      Policy
+		// CheckNotValid adds CHECK constraints NOT VALID in the Statements, then validates them.
+		CheckNotValid bool
+	}
+
+	// Result is the outcome of comparing a database with its desired schema, one TypeResult per schema type compared.
+	Result struct {
+		Types []TypeResult
+	}
+
+	// TypeResult is the outcome of comparing a single schema type.
+	TypeResult struct {
+		SchemaType string
+		// Changes are the objects that differ from the desired schema.
+		Changes []*pgdiff.Change
+		// Statements is the SQL that would make the database match the desired schema.
+		Statements []string
+		Notices    []string
+		Errors     []string
+	}
+
+	// MismatchError is returned under the Strict Policy when the database does not match the desired schema.
+	MismatchError struct {
+		Result *Result
+	}
+)
+
+// Check compares the database conn with the desired schema defined by the .sql files in fsys, which are read as a
+// schema dump would be, see dump.ReadFS. The database is not modified. conn is not closed. An error reading either
+// schema is returned without a Result.
+func Check(fsys fs.FS, conn *sql.DB, opts Options) (*Result, error) {
+	if opts.Schema == "" {
+		opts.Schema = defaultSchema
+	}
+	if opts.SchemaTypes == nil {
+		opts.SchemaTypes = pgdiff.AllSchemaTypes
+	}
+	source, err := dump.ReadFS(fsys, opts.Schema)
+	if err != nil {
+		return nil, err
+	}
+	desired := pgdiff.NewRowSchemaFactory(source, opts.Schema)
+	desired.SetCheckNotValid(opts.CheckNotValid)
+	actual := db.NewSchemaFactory(conn, &pgutil.DbInfo{DbSchema: opts.Schema})
+	return check(desired, actual, opts)
+}
+
+// check compares the desired and actual schemas once Check has read them.
+func check(desired, actual pgdiff.SchemaFactory, opts Options) (*Result, error) {
+	result := &Result{}
+	for _, schemaType := range opts.SchemaTypes {
+		comp := pgdiff.CompareByFactories(desired, actual, schemaType)
+		// Errors outside any Change come from reading the schemas.
+		for _, s := range comp.Output {
+			if e, ok := s.(*pgdiff.Error); ok {
+				return nil, pgdiff.NewError(fmt.Sprintf("reading %s: %s", schemaType, e))
+			}
+		}
+		result.Types = append(result.Types, newTypeResult(comp))
+	}
+	if opts.Policy == Strict && !result.matches(false) {
+		return result, &MismatchError{result}
+	}
+	return result, nil
+}
+
+func newTypeResult(comp *pgdiff.Comparison) TypeResult {
+	r := TypeResult{SchemaType: comp.SchemaType, Changes: comp.Changes}
+	for _, s := range comp.Stringers() {
+		switch s.(type) {
+		case *pgdiff.Line:
+			r.Statements = append(r.Statements, s.String())
+		case *pgdiff.Notice, *pgdiff.Warning:
+			r.Notices = append(r.Notices, s.String())
+		case *pgdiff.Error:
+			r.Errors = append(r.Errors, s.String())
+		}
+	}
+	return r
+}
+
+// Matches reports whether the database matches the desired schema, ie. there are no statements to run and no errors.
+func (r *Result) Matches() bool {
+	return r.matches(true)
+}
+
+// matches reports whether the database matches the desired schema, leaving out changes to objects of
+// renderedSchemaTypes unless rendered is true. Errors, adds and drops always count.
+func (r *Result) matches(rendered bool) bool {
+	for _, t := range r.Types {
+		if len(t.Errors) > 0 {
+			return false
+		}
+		if rendered || !renderedSchemaTypes[t.SchemaType] {
+			if len(t.Statements) > 0 {
+				return false
+			}
+			continue
+		}
+		for _, change := range t.Changes {
+			if change.Action != pgdiff.ActionAlter && len(change.Statements()) > 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Statements returns the SQL that would make the database match the desired schema, in schema type order.
+func (r *Result) Statements() []string {
+	var stmts []string
+	for _, t := range r.Types {
+		stmts = append(stmts, t.Statements...)
+	}
+	return stmts
+}
+
+func (e *MismatchError) Error() string {
+	var counts []string
+	for _, t := range e.Result.Types {
+		if len(t.Statements) > 0 || len(t.Errors) > 0 {
+			counts = append(counts, fmt.Sprintf("%s (%d statements, %d errors)", t.SchemaType, len(t.Statements),
+				len(t.Errors)))
+		}
+	}
+	return "database does not match desired schema: " + strings.Join(counts, ", ")
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2022 Facefunk. All rights reserved.
+// Use of this source code is governed by the MIT license that can be found in the LICENSE file.
+
+//go:build go1.16
+// +build go1.16
+
+package verify
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/facefunk/pgdiff"
+	"github.com/facefunk/pgdiff/dump"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResult(t *testing.T) {
+	r := &Result{Types: []TypeResult{
+		newTypeResult(&pgdiff.Comparison{
+			SchemaType: pgdiff.RoleSchemaType,
+			Output:     []pgdiff.Stringer{pgdiff.NewNotice("-- Skipping ROLE")},
+		}),
+		newTypeResult(&pgdiff.Comparison{
+			SchemaType: pgdiff.TableSchemaType,
+			Changes: []*pgdiff.Change{{
+				SchemaType: pgdiff.TableSchemaType,
+				Identity:   "public.t",
+				Action:     pgdiff.ActionAdd,
+				Output:     []pgdiff.Stringer{pgdiff.NewLine("CREATE TABLE t();")},
+			}},
+		}),
+	}}
+	assert.False(t, r.Matches())
+	assert.Equal(t, []string{"CREATE TABLE t();"}, r.Statements())
+	assert.Equal(t, []string{"-- Skipping ROLE"}, r.Types[0].Notices)
+	assert.Equal(t, "public.t", r.Types[1].Changes[0].Identity)
+	assert.EqualError(t, &MismatchError{r}, "database does not match desired schema: TABLE (1 statements, 0 errors)")
+
+	r.Types = r.Types[:1]
+	assert.True(t, r.Matches())
+}
+
+// errFS is an fs.FS that cannot be read.
+type errFS struct{}
+
+func (errFS) Open(name string) (fs.File, error) {
+	return nil, fs.ErrPermission
+}
+
+// errSource is a pgdiff.RowSource whose queries fail.
+type errSource struct{}
+
+func (errSource) Rows(schemaType string) ([]map[string]string, error) {
+	return nil, pgdiff.NewError("connection refused")
+}
+
+func (errSource) Identify(num int) *pgdiff.Notice {
+	return pgdiff.NewNotice("-- errSource")
+}
+
+func TestCheck(t *testing.T) {
+	_, err := Check(errFS{}, nil, Options{})
+	assert.EqualError(t, err, "reading schema files: permission denied")
+
+	desired, err := dump.ReadFS(fstest.MapFS{
+		"tables.sql": {Data: []byte("CREATE TABLE public.t (id integer);\n")},
+		"functions.sql": {Data: []byte("CREATE FUNCTION public.f() RETURNS integer LANGUAGE sql AS $$ SELECT 1 $$;\n" +
+			"CREATE FUNCTION public.g() RETURNS integer LANGUAGE sql AS $$ SELECT 1 $$;\n")},
+	}, "public")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := dump.NewSource("actual.sql", []byte(`
+CREATE TABLE public.t (id integer);
+CREATE FUNCTION public.f() RETURNS integer LANGUAGE sql AS $$ SELECT 2 $$;
+`), "public")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{SchemaTypes: []string{pgdiff.TableSchemaType, pgdiff.FunctionSchemaType}, Policy: Strict}
+	r, err := check(pgdiff.NewRowSchemaFactory(desired, "public"), pgdiff.NewRowSchemaFactory(actual, "public"), opts)
+	// g is missing, which fails even though FUNCTION definitions are rendered.
+	assert.IsType(t, &MismatchError{}, err)
+	assert.Empty(t, r.Types[0].Statements)
+	assert.Len(t, r.Types[1].Changes, 2)
+	assert.False(t, r.Matches())
+
+	// f only differs in its definition, which does not.
+	actual, err = dump.NewSource("actual.sql", []byte(`
+CREATE TABLE public.t (id integer);
+CREATE FUNCTION public.f() RETURNS integer LANGUAGE sql AS $$ SELECT 2 $$;
+CREATE FUNCTION public.g() RETURNS integer LANGUAGE sql AS $$ SELECT 1 $$;
+`), "public")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err = check(pgdiff.NewRowSchemaFactory(desired, "public"), pgdiff.NewRowSchemaFactory(actual, "public"), opts)
+	assert.NoError(t, err)
+	assert.Equal(t, pgdiff.ActionAlter, r.Types[1].Changes[0].Action)
+	assert.NotEmpty(t, r.Types[1].Statements)
+	assert.False(t, r.Matches())
+
+	opts.SchemaTypes = []string{pgdiff.TableSchemaType}
+	empty, err := dump.NewSource("empty.sql", nil, "public")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err = check(pgdiff.NewRowSchemaFactory(desired, "public"), pgdiff.NewRowSchemaFactory(empty, "public"), opts)
+	assert.IsType(t, &MismatchError{}, err)
+	assert.Equal(t, []string{"CREATE TABLE public.t();"}, r.Statements())
+
+	r, err = check(pgdiff.NewRowSchemaFactory(desired, "public"), pgdiff.NewRowSchemaFactory(errSource{}, "public"), opts)
+	assert.EqualError(t, err, "reading TABLE: connection refused")
+	assert.Nil(t, r)
+}
@@ -65,6 +65,19 @@ func (c *ViewSchema) NextRow() bool {
 	return !c.done
 }
 
+// Identity returns the qualified name of the current row's object
+func (c *ViewSchema) Identity() string {
+	return c.get("viewname")
+}
+
+// Row returns a copy of the current row
+func (c *ViewSchema) Row() map[string]string {
+	if c.rowNum >= len(c.rows) {
+		return nil
+	}
+	return copyRow(c.rows[c.rowNum])
+}
+
 // Compare tells you, in one pass, whether or not the first row matches, is less than, or greater than the second row
 func (c *ViewSchema) Compare(obj Schema) (int, *Error) {
 	c2, ok := obj.(*ViewSchema)